@@ -19,6 +19,7 @@ package algo
 
 import (
 	"container/heap"
+	"math/rand"
 	"sort"
 
 	"github.com/dgraph-io/dgraph/bp128"
@@ -400,6 +401,30 @@ func IndexOf(u *protos.List, uid uint64) int {
 	return -1
 }
 
+// Sample returns a uniformly random subset of n uids of u, keeping them sorted.
+// All of u is returned if it has n or fewer uids.
+func Sample(u *protos.List, n int, r *rand.Rand) *protos.List {
+	if n >= len(u.Uids) {
+		return u
+	}
+	if n <= 0 {
+		return &protos.List{}
+	}
+	out := make([]uint64, 0, n)
+	for i, uid := range u.Uids {
+		// Pick each uid with the probability of it being among the ones still
+		// needed out of the remaining ones.
+		needed := n - len(out)
+		if r.Intn(len(u.Uids)-i) < needed {
+			out = append(out, uid)
+			if len(out) == n {
+				break
+			}
+		}
+	}
+	return &protos.List{Uids: out}
+}
+
 // ToUintsListForTest converts to list of uints for testing purpose only.
 func ToUintsListForTest(ul []*protos.List) [][]uint64 {
 	out := make([][]uint64, 0, len(ul))
@@ -415,3 +415,39 @@ func skipDuplicate(in []uint64, idx int) int {
 	}
 	return i
 }
+
+func TestSample(t *testing.T) {
+	u := newList([]uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		res := Sample(u, 4, r)
+		require.Equal(t, 4, len(res.Uids))
+		require.True(t, sort.SliceIsSorted(res.Uids, func(i, j int) bool {
+			return res.Uids[i] < res.Uids[j]
+		}))
+		require.Equal(t, res.Uids, IntersectSorted([]*protos.List{u, res}).Uids)
+	}
+
+	require.Equal(t, u.Uids, Sample(u, 20, r).Uids)
+	require.Empty(t, Sample(u, 0, r).Uids)
+
+	// The same seed gives the same sample.
+	a := Sample(u, 3, rand.New(rand.NewSource(42)))
+	b := Sample(u, 3, rand.New(rand.NewSource(42)))
+	require.Equal(t, a.Uids, b.Uids)
+}
+
+func TestSampleUniform(t *testing.T) {
+	u := newList([]uint64{1, 2, 3, 4, 5})
+	r := rand.New(rand.NewSource(7))
+	counts := make(map[uint64]int)
+	for i := 0; i < 10000; i++ {
+		for _, uid := range Sample(u, 2, r).Uids {
+			counts[uid]++
+		}
+	}
+	// Each uid is expected to be picked 4000 times.
+	for _, uid := range u.Uids {
+		require.InDelta(t, 4000, counts[uid], 300)
+	}
+}
@@ -411,7 +411,8 @@ func (e *Edge) SetValueDatetime(dateTime time.Time) error {
 // the type of the edge to types.GeoID.  If the edge had previous been assigned another value (even
 // of another type), the value and type are overwritten.  If the edge has previously been connected
 // to a node, the edge and type are left unchanged and ErrConnected is returned. If the string
-// fails to parse with geojson.Unmarshal() the edge is left unchanged and an error returned.
+// fails to parse with geojson.Unmarshal() or isn't a Point, LineString, MultiLineString, Polygon
+// or MultiPolygon which can be indexed, the edge is left unchanged and an error returned.
 func (e *Edge) SetValueGeoJson(json string) error {
 	if len(e.nq.ObjectId) > 0 {
 		return ErrConnected
@@ -422,6 +423,9 @@ func (e *Edge) SetValueGeoJson(json string) error {
 	if err != nil {
 		return err
 	}
+	if err = types.ValidateGeo(g); err != nil {
+		return err
+	}
 
 	geo, err := types.ObjectValue(types.GeoID, g)
 	if err != nil {
@@ -438,11 +442,15 @@ func (e *Edge) SetValueGeoJson(json string) error {
 // the type of the edge to types.GeoID.  If the edge had previous been assigned another value (even
 // of another type), the value and type are overwritten.  If the edge has previously been connected
 // to a node, the edge and type are left unchanged and ErrConnected is returned. If the geometry
-// fails to be marshalled with wkb.Marshal() the edge is left unchanged and an error returned.
+// isn't a Point, LineString, MultiLineString, Polygon or MultiPolygon which can be indexed, or
+// fails to be marshalled with wkb.Marshal(), the edge is left unchanged and an error returned.
 func (e *Edge) SetValueGeoGeometry(g geom.T) error {
 	if len(e.nq.ObjectId) > 0 {
 		return ErrConnected
 	}
+	if err := types.ValidateGeo(g); err != nil {
+		return err
+	}
 
 	b, err := wkb.Marshal(g, binary.LittleEndian)
 	if err != nil {
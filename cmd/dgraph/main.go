@@ -122,6 +122,10 @@ func setupConfigOpts() {
 	flag.Float64Var(&config.CommitFraction, "gentlecommit", defaults.CommitFraction,
 		"Fraction of dirty posting lists to commit every few seconds.")
 
+	flag.StringVar(&config.AnalyzerDir, "analyzer_dir", defaults.AnalyzerDir,
+		"Directory of the stop word and synonym files of fulltext indexes. Every server"+
+			" serving the predicates needs the same files.")
+
 	flag.StringVar(&config.ConfigFile, "config", defaults.ConfigFile,
 		"YAML configuration file containing dgraph settings.")
 	flag.BoolVar(&config.DebugMode, "debugmode", defaults.DebugMode,
@@ -264,6 +268,13 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 		x.SetStatus(w, x.ErrorInvalidRequest, msg)
 	}
 
+	// Geometries are output as GeoJSON objects by default.
+	geoFormat := r.URL.Query().Get("geo")
+	if !query.ValidGeoFormat(geoFormat) {
+		x.SetStatus(w, x.ErrorInvalidRequest, fmt.Sprintf("Invalid geo output format %s", geoFormat))
+		return
+	}
+
 	var l query.Latency
 	l.Start = time.Now()
 	defer r.Body.Close()
@@ -377,7 +388,7 @@ func queryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = query.ToJson(&l, res.Subgraphs, w,
-		query.ConvertUidsToHex(res.Allocations), addLatency)
+		query.ConvertUidsToHex(res.Allocations), addLatency, geoFormat)
 	if err != nil {
 		// since we performed w.Write in ToJson above,
 		// calling WriteHeader with 500 code will be ignored.
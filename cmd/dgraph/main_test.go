@@ -193,7 +193,7 @@ func processToFastJSON(q string) string {
 	}
 
 	var buf bytes.Buffer
-	err = query.ToJson(&l, qr.Subgraphs, &buf, nil, false)
+	err = query.ToJson(&l, qr.Subgraphs, &buf, nil, false, "")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -71,14 +71,15 @@ func processGeoFile(ctx context.Context, file string, dgraphClient *client.Dgrap
 	}
 
 	// Read the features one at a time.
-	for dec.More() {
+	for i := 0; dec.More(); i++ {
 		var f geojson.Feature
 		err := dec.Decode(&f)
 		if err != nil {
 			return err
 		}
 		if err = toMutations(&f, dgraphClient); err != nil {
-			return err
+			return fmt.Errorf("While processing feature %d with id %q of %s: %v", i, f.ID, file,
+				err)
 		}
 	}
 	return nil
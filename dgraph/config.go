@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/tok"
 	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -46,6 +47,8 @@ type Options struct {
 	ExpandEdge          bool
 	InMemoryComm        bool
 
+	AnalyzerDir string
+
 	ConfigFile string
 	DebugMode  bool
 }
@@ -75,6 +78,8 @@ var DefaultConfig = Options{
 	ExpandEdge:          true,
 	InMemoryComm:        false,
 
+	AnalyzerDir: "",
+
 	ConfigFile: "",
 	DebugMode:  false,
 }
@@ -145,6 +150,8 @@ func SetConfiguration(newConfig Options) {
 	worker.Config.ExpandEdge = Config.ExpandEdge
 	worker.Config.InMemoryComm = Config.InMemoryComm
 
+	tok.Config.AnalyzerDir = Config.AnalyzerDir
+
 	x.Config.ConfigFile = Config.ConfigFile
 	x.Config.DebugMode = Config.DebugMode
 }
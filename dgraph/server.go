@@ -28,6 +28,7 @@ import (
 	"github.com/dgraph-io/badger"
 	"github.com/dgraph-io/badger/options"
 	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/posting"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/query"
 	"github.com/dgraph-io/dgraph/worker"
@@ -93,6 +94,7 @@ func (s *ServerState) initStorage() {
 }
 
 func (s *ServerState) Dispose() error {
+	posting.Cleanup()
 	if err := s.Pstore.Close(); err != nil {
 		return errors.Wrapf(err, "While closing postings store")
 	}
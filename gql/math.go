@@ -57,9 +57,11 @@ func (s *mathTreeStack) peek() *MathTree {
 type MathTree struct {
 	Fn    string
 	Var   string
-	Const types.Val // This will always be parsed as a float value
+	Const types.Val // This is parsed as a float value, or a string if quoted.
 	Val   map[uint64]types.Val
 	Child []*MathTree
+
+	nargs int // Number of arguments given to a value function.
 }
 
 func isUnary(f string) bool {
@@ -75,6 +77,21 @@ func isTernary(f string) bool {
 	return f == "cond"
 }
 
+// ValueFuncs are the math functions on dates, strings and types, which take a
+// variable number of arguments of any type.
+var ValueFuncs = []string{"dateadd", "truncate", "extract", "concat", "lower", "upper",
+	"length", "substring", "tostring", "toint", "tofloat", "tobool", "todatetime"}
+
+// IsValueFunc returns true if f is one of the ValueFuncs.
+func IsValueFunc(f string) bool {
+	for _, vf := range ValueFuncs {
+		if f == vf {
+			return true
+		}
+	}
+	return false
+}
+
 func evalMathStack(opStack, valueStack *mathTreeStack) error {
 	topOp, err := opStack.pop()
 	if err != nil {
@@ -88,6 +105,16 @@ func evalMathStack(opStack, valueStack *mathTreeStack) error {
 		}
 		topOp.Child = []*MathTree{topVal}
 
+	} else if IsValueFunc(topOp.Fn) {
+		if valueStack.size() < topOp.nargs {
+			return x.Errorf("Invalid math expression. Expected %d operands for %s",
+				topOp.nargs, topOp.Fn)
+		}
+		topOp.Child = make([]*MathTree, topOp.nargs)
+		for i := topOp.nargs - 1; i >= 0; i-- {
+			topOp.Child[i] = valueStack.popAssert()
+		}
+
 	} else if isTernary(topOp.Fn) {
 		if valueStack.size() < 3 {
 			return x.Errorf("Invalid Math expression. Expected 3 operands")
@@ -119,7 +146,7 @@ func isMathFunc(f string) bool {
 		f == "==" || f == "!=" ||
 		f == "min" || f == "max" || f == "sqrt" ||
 		f == "pow" || f == "logbase" || f == "floor" || f == "ceil" ||
-		f == "since"
+		f == "since" || IsValueFunc(f)
 }
 
 func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
@@ -140,7 +167,17 @@ func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
 	for it.Next() {
 		item := it.Item()
 		lval := strings.ToLower(item.Val)
-		if isMathFunc(lval) {
+		isFunc := isMathFunc(lval)
+		if isFunc && item.Typ == itemName {
+			// A named function is always called, otherwise this is a variable,
+			// e.g. length in math(length * 2).
+			peekIt, err := it.Peek(1)
+			if err != nil {
+				return nil, false, err
+			}
+			isFunc = peekIt[0].Typ == itemLeftRound
+		}
+		if isFunc {
 			op := lval
 			it.Prev()
 			lastItem := it.Item()
@@ -162,7 +199,8 @@ func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
 					return nil, false, err
 				}
 			}
-			opStack.push(&MathTree{Fn: op}) // Push current operator.
+			opNode := &MathTree{Fn: op}
+			opStack.push(opNode) // Push current operator.
 			peekIt, err := it.Peek(1)
 			if err != nil {
 				return nil, false, err
@@ -176,6 +214,7 @@ func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
 						return nil, false, err
 					}
 					valueStack.push(child)
+					opNode.nargs++
 					if !again {
 						break
 					}
@@ -207,7 +246,17 @@ func parseMathFunc(it *lex.ItemIterator, again bool) (*MathTree, bool, error) {
 			// Try to parse it as a constant.
 			child := &MathTree{}
 			v, err := strconv.ParseFloat(item.Val, 64)
-			if err != nil {
+			if strings.HasPrefix(item.Val, `"`) {
+				str, err := strconv.Unquote(item.Val)
+				if err != nil {
+					return nil, false, x.Wrapf(err, "Invalid string in math expression: %s",
+						item.Val)
+				}
+				child.Const = types.Val{
+					Tid:   types.StringID,
+					Value: str,
+				}
+			} else if err != nil {
 				child.Var = item.Val
 			} else {
 				child.Const = types.Val{
@@ -308,6 +357,10 @@ func (t *MathTree) stringHelper(buf *bytes.Buffer) {
 	}
 	if t.Const.Value != nil {
 		// Leaf node.
+		if str, ok := t.Const.Value.(string); ok {
+			buf.WriteString(strconv.Quote(str))
+			return
+		}
 		buf.WriteString(strconv.FormatFloat(t.Const.Value.(float64), 'E', -1, 64))
 		return
 	}
@@ -319,7 +372,10 @@ func (t *MathTree) stringHelper(buf *bytes.Buffer) {
 		"logbase", "pow":
 		buf.WriteString(t.Fn)
 	default:
-		x.Fatalf("Unknown operator: %q", t.Fn)
+		if !IsValueFunc(t.Fn) {
+			x.Fatalf("Unknown operator: %q", t.Fn)
+		}
+		buf.WriteString(t.Fn)
 	}
 
 	for _, c := range t.Child {
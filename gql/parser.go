@@ -49,7 +49,8 @@ type GraphQuery struct {
 	Var        string
 	NeedsVar   []VarContext
 	Func       *Function
-	Expand     string // Which variable to expand with.
+	Expand     string    // Which variable to expand with.
+	Path       *PathTree // Regular path expression, if Attr is a path.
 
 	Args map[string]string
 	// Query can have multiple sort parameters.
@@ -67,6 +68,16 @@ type GraphQuery struct {
 	FacetOrder   string
 	FacetDesc    bool
 
+	// If non-empty, only these children are required by @cascade. The others
+	// are optional and the cascade is not propagated to them.
+	CascadeFields []string
+
+	// Groups are filtered by GroupbyHaving, sorted by GroupbyOrder and then
+	// paginated using first and offset from GroupbyArgs.
+	GroupbyOrder  []*protos.Order
+	GroupbyArgs   map[string]string
+	GroupbyHaving *FilterTree
+
 	// Internal fields below.
 	// If gq.fragment is nonempty, then it is a fragment reference / spread.
 	fragment string
@@ -84,6 +95,8 @@ type GraphQuery struct {
 type AttrLang struct {
 	Attr  string
 	Langs []string
+	Facet string    // Facet of Attr to use, instead of its value.
+	Path  *PathTree // Set if Attr is a path like friend/school.
 }
 
 // pair denotes the key value pair that is part of the GraphQL query root in parenthesis.
@@ -128,9 +141,10 @@ type varMap map[string]varInfo
 // Either you can have `Op and Children` on non-leaf nodes
 // Or Func at leaf nodes.
 type FilterTree struct {
-	Op    string
-	Child []*FilterTree
-	Func  *Function
+	Op     string
+	Child  []*FilterTree
+	Func   *Function
+	Exists *GraphQuery // Query block for exists { ... }, Func.Name is exists.
 }
 
 type Arg struct {
@@ -192,7 +206,11 @@ func init() {
 		"==": 6,
 		"!=": 5,
 	}
-
+	// The value functions come after the other functions, and before the
+	// operators.
+	for i, f := range ValueFuncs {
+		mathOpPrecedence[f] = 80 - i
+	}
 }
 
 func (f *Function) IsAggregator() bool {
@@ -203,6 +221,14 @@ func (f *Function) IsPasswordVerifier() bool {
 	return f.Name == "checkpwd"
 }
 
+func (f *Function) IsTextScore() bool {
+	return f.Name == "bm25"
+}
+
+func (f *Function) IsDistance() bool {
+	return f.Name == "distance"
+}
+
 // DebugPrint is useful for debugging.
 func (gq *GraphQuery) DebugPrint(prefix string) {
 	x.Printf("%s[%x %q %q]\n", prefix, gq.UID, gq.Attr, gq.Alias)
@@ -451,6 +477,11 @@ func substituteVariables(gq *GraphQuery, vmap varMap) error {
 }
 
 func substituteVariablesFilter(f *FilterTree, vmap varMap) error {
+	if f.Exists != nil {
+		if err := substituteVariables(f.Exists, vmap); err != nil {
+			return err
+		}
+	}
 	if f.Func != nil {
 		if err := substituteVar(f.Func.Attr, &f.Func.Attr, vmap); err != nil {
 			return err
@@ -687,6 +718,9 @@ func (f *FilterTree) collectVars(v *Vars) {
 			v.Needs = append(v.Needs, va.Name)
 		}
 	}
+	if f.Exists != nil {
+		f.Exists.collectVars(v)
+	}
 	for _, fch := range f.Child {
 		fch.collectVars(v)
 	}
@@ -784,12 +818,41 @@ L:
 			case "normalize":
 				gq.Normalize = true
 			case "cascade":
-				gq.Cascade = true
+				if err := parseCascade(it, gq); err != nil {
+					return nil, err
+				}
 			case "groupby":
 				gq.IsGroupby = true
-				parseGroupby(it, gq)
+				if err := parseGroupby(it, gq); err != nil {
+					return nil, err
+				}
+			case "having":
+				having, err := parseFilter(it)
+				if err != nil {
+					return nil, err
+				}
+				gq.GroupbyHaving = having
 			case "ignorereflex":
 				gq.IgnoreReflex = true
+			case "facets":
+				// Keeps the nodes having an edge whose facets match.
+				if gq.Func == nil || gq.Func.Name != "has" {
+					return nil, x.Errorf("Facets filter at root can only be used with has function.")
+				}
+				if gq.FacetsFilter != nil {
+					return nil, x.Errorf("Only one facets filter allowed")
+				}
+				res, err := parseFacets(it)
+				if err != nil {
+					return nil, err
+				}
+				if res.ft == nil {
+					return nil, x.Errorf("Only a facets filter is allowed at root")
+				}
+				if res.ft.hasVars() {
+					return nil, x.Errorf("variables are not allowed in facets filter.")
+				}
+				gq.FacetsFilter = res.ft
 			case "upsert":
 				if gq.Func == nil || gq.Func.Name != "eq" {
 					return nil, x.Errorf("Upsert query can only be done with eq function.")
@@ -1402,12 +1465,73 @@ func validFuncName(name string) bool {
 	}
 
 	switch name {
-	case "regexp", "anyofterms", "allofterms", "alloftext", "anyoftext", "has", "uid", "uid_in":
+	case "regexp", "anyofterms", "allofterms", "alloftext", "anyoftext", "has", "uid", "uid_in",
+		"join", "distinct", "phrase", "near_words", "prefix",
+		"match", "icontains", "sounds_like", "similar_to":
 		return true
 	}
 	return false
 }
 
+// validateDistinct checks that distinct has at most a prefix as argument, like
+// distinct(category) or distinct(category, "elec").
+func validateDistinct(f *Function) error {
+	if len(f.Args) > 1 || (len(f.Args) == 1 && f.Args[0].IsValueVar) {
+		return x.Errorf("distinct expects a predicate and an optional prefix. Got: %v", f.Args)
+	}
+	return nil
+}
+
+// validateTextScore checks that bm25 has the text to score the values of the
+// predicate against, like bm25(description, "red shoes").
+func validateTextScore(f *Function) error {
+	if len(f.Args) != 1 || f.Args[0].IsValueVar {
+		return x.Errorf("bm25 expects a predicate and the text to score against. Got: %v",
+			f.Args)
+	}
+	return nil
+}
+
+// validateDistance checks that distance only has the predicate the block was
+// found with, like distance(embedding).
+func validateDistance(f *Function) error {
+	if len(f.Args) != 0 {
+		return x.Errorf("distance expects only a predicate. Got: %v", f.Args)
+	}
+	return nil
+}
+
+// validateJoin checks that a join has exactly one value variable as argument,
+// like join(customer.email, val(e)).
+func validateJoin(f *Function) error {
+	if len(f.Args) != 1 || !f.Args[0].IsValueVar {
+		return x.Errorf("join expects a predicate and a value variable. Got: %v", f.Args)
+	}
+	return nil
+}
+
+// validateAggregatorArgs checks the arguments passed to an aggregator after
+// the variable or predicate. Only percentile takes one, which must be a number
+// between 0 and 100.
+func validateAggregatorArgs(f *Function) error {
+	if f.Name != "percentile" {
+		if len(f.Args) > 0 {
+			return x.Errorf("Aggregator %s doesn't take any arguments. Got: %v", f.Name, f.Args)
+		}
+		return nil
+	}
+	if len(f.Args) != 1 {
+		return x.Errorf("percentile expects a percentage as its second argument")
+	}
+	p, err := strconv.ParseFloat(f.Args[0].Value, 64)
+	// NaN and the infinities aren't between 0 and 100 either.
+	if err != nil || !(p >= 0 && p <= 100) {
+		return x.Errorf("Invalid percentile %q. Expected a number between 0 and 100",
+			f.Args[0].Value)
+	}
+	return nil
+}
+
 func parseFunction(it *lex.ItemIterator, gq *GraphQuery) (*Function, error) {
 	var g *Function
 	var expectArg, seenFuncArg, expectLang, isDollar bool
@@ -1799,7 +1923,9 @@ func tryParseFacetList(it *lex.ItemIterator) (res facetRes, parseOk bool, err er
 	}
 }
 
-// parseGroupby parses the groupby directive.
+// parseGroupby parses the groupby directive. The attributes to group by can be
+// paths like friend/school or facets like school @facets(since), and the
+// options orderasc, orderdesc, first and offset apply to the groups.
 func parseGroupby(it *lex.ItemIterator, gq *GraphQuery) error {
 	count := 0
 	expectArg := true
@@ -1822,24 +1948,20 @@ func parseGroupby(it *lex.ItemIterator, gq *GraphQuery) error {
 			if !expectArg {
 				return x.Errorf("Expected a comma or right round but got: %v", item.Val)
 			}
-			attr := collectName(it, item.Val)
-			var langs []string
-			items, err := it.Peek(1)
-			if err == nil && items[0].Typ == itemAt {
-				it.Next() // consume '@'
-				it.Next() // move forward
-				langs, err = parseLanguageList(it)
-				if err != nil {
+			expectArg = false
+			if items, err := it.Peek(1); err == nil && items[0].Typ == itemColon {
+				it.Next() // consume ':'
+				if err := parseGroupbyArg(it, gq, item.Val); err != nil {
 					return err
 				}
+				continue
 			}
-			attrLang := AttrLang{
-				Attr:  attr,
-				Langs: langs,
+			attrLang, err := parseGroupbyAttr(it, item.Val)
+			if err != nil {
+				return err
 			}
 			gq.GroupbyAttrs = append(gq.GroupbyAttrs, attrLang)
 			count++
-			expectArg = false
 		}
 	}
 	if expectArg {
@@ -1851,6 +1973,122 @@ func parseGroupby(it *lex.ItemIterator, gq *GraphQuery) error {
 	return nil
 }
 
+// collectGroupbyName collects a predicate name, or a path made of predicate
+// names separated by slashes.
+func collectGroupbyName(it *lex.ItemIterator, val string) string {
+	val = collectName(it, val)
+	for {
+		items, err := it.Peek(2)
+		if err != nil || items[0].Val != "/" || items[1].Typ != itemName {
+			return val
+		}
+		it.Next()
+		it.Next()
+		val += "/" + collectName(it, it.Item().Val)
+	}
+}
+
+func parseGroupbyAttr(it *lex.ItemIterator, val string) (AttrLang, error) {
+	attrLang := AttrLang{Attr: collectGroupbyName(it, val)}
+	if strings.Contains(attrLang.Attr, "/") {
+		path, err := parsePath(attrLang.Attr)
+		if err != nil {
+			return attrLang, err
+		}
+		attrLang.Path = path
+	}
+	items, err := it.Peek(2)
+	if err != nil || items[0].Typ != itemAt {
+		return attrLang, nil
+	}
+	it.Next() // consume '@'
+	it.Next() // move forward
+	if items[1].Val != "facets" {
+		attrLang.Langs, err = parseLanguageList(it)
+		return attrLang, err
+	}
+	if attrLang.Path != nil {
+		return attrLang, x.Errorf("Cannot group by facets of path %s", attrLang.Attr)
+	}
+	if it.Next(); it.Item().Typ != itemLeftRound {
+		return attrLang, x.Errorf("Expected ( after facets in groupby")
+	}
+	if it.Next(); it.Item().Typ != itemName {
+		return attrLang, x.Errorf("Expected a facet key in groupby. Got: %v", it.Item().Val)
+	}
+	attrLang.Facet = collectName(it, it.Item().Val)
+	if it.Next(); it.Item().Typ != itemRightRound {
+		return attrLang, x.Errorf("Expected only one facet key in groupby")
+	}
+	return attrLang, nil
+}
+
+func parseGroupbyArg(it *lex.ItemIterator, gq *GraphQuery, key string) error {
+	var val string
+	if it.Next() && it.Item().Val == "-" {
+		// A negative number.
+		val = "-"
+		it.Next()
+	}
+	if it.Item().Typ != itemName {
+		return x.Errorf("Expected a value for %s in groupby", key)
+	}
+	val += it.Item().Val
+	switch key {
+	case "orderasc", "orderdesc":
+		gq.GroupbyOrder = append(gq.GroupbyOrder, &protos.Order{
+			Attr: collectGroupbyName(it, val),
+			Desc: key == "orderdesc",
+		})
+	case "first", "offset":
+		if _, ok := gq.GroupbyArgs[key]; ok {
+			return x.Errorf("Got repeated key %q in groupby", key)
+		}
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return x.Errorf("Expected a non-negative number for %s in groupby. Got: %v",
+				key, val)
+		}
+		if key == "first" && n == 0 {
+			return x.Errorf("Expected a positive number for first in groupby. Got: %v", val)
+		}
+		if gq.GroupbyArgs == nil {
+			gq.GroupbyArgs = make(map[string]string)
+		}
+		gq.GroupbyArgs[key] = val
+	default:
+		return x.Errorf("Invalid argument %q in groupby", key)
+	}
+	return nil
+}
+
+// parseExists parses exists { ... } inside a filter. The block is a regular
+// query block which is evaluated starting from the nodes being filtered.
+func parseExists(it *lex.ItemIterator) (*FilterTree, error) {
+	it.Next()
+	if it.Item().Typ != itemLeftCurl {
+		return nil, x.Errorf("Expected { after exists")
+	}
+	gq := &GraphQuery{
+		Args: make(map[string]string),
+	}
+	if err := godeep(it, gq); err != nil {
+		return nil, err
+	}
+	if len(gq.Children) == 0 {
+		return nil, x.Errorf("Empty block inside exists")
+	}
+	var v Vars
+	gq.collectVars(&v)
+	if len(v.Defines) > 0 {
+		return nil, x.Errorf("Variables cannot be defined inside exists. Got: %v", v.Defines)
+	}
+	return &FilterTree{
+		Func:   &Function{Name: "exists"},
+		Exists: gq,
+	}, nil
+}
+
 // parseFilter parses the filter directive to produce a QueryFilter / parse tree.
 func parseFilter(it *lex.ItemIterator) (*FilterTree, error) {
 	it.Next()
@@ -1884,6 +2122,12 @@ func parseFilter(it *lex.ItemIterator) (*FilterTree, error) {
 				}
 			}
 			opStack.push(&FilterTree{Op: op}) // Push current operator.
+		} else if item.Typ == itemName && lval == "exists" {
+			leaf, err := parseExists(it)
+			if err != nil {
+				return nil, err
+			}
+			valueStack.push(leaf)
 		} else if item.Typ == itemName { // Value.
 			it.Prev()
 			f, err := parseFunction(it, nil)
@@ -2060,10 +2304,24 @@ func parseDirective(it *lex.ItemIterator, curp *GraphQuery) error {
 			curp.Filter = filter
 		case "groupby":
 			curp.IsGroupby = true
-			parseGroupby(it, curp)
+			if err := parseGroupby(it, curp); err != nil {
+				return err
+			}
+		case "having":
+			having, err := parseFilter(it)
+			if err != nil {
+				return err
+			}
+			curp.GroupbyHaving = having
+		case "cascade":
+			if err := parseCascade(it, curp); err != nil {
+				return err
+			}
 		default:
 			return x.Errorf("Unknown directive [%s]", item.Val)
 		}
+	} else if item.Val == "cascade" {
+		curp.Cascade = true
 	} else if len(curp.Attr) > 0 && len(curp.Langs) == 0 {
 		// this is language list
 		if curp.Langs, err = parseLanguageList(it); err != nil {
@@ -2106,7 +2364,7 @@ func parseLanguageList(it *lex.ItemIterator) ([]string, error) {
 
 func validKeyAtRoot(k string) bool {
 	switch k {
-	case "func", "orderasc", "orderdesc", "first", "offset", "after":
+	case "func", "orderasc", "orderdesc", "nulls", "first", "offset", "after", "random", "seed":
 		return true
 	case "from", "to", "numpaths":
 		// Specific to shortest path
@@ -2120,7 +2378,7 @@ func validKeyAtRoot(k string) bool {
 // Check for validity of key at non-root nodes.
 func validKey(k string) bool {
 	switch k {
-	case "orderasc", "orderdesc", "first", "offset", "after":
+	case "orderasc", "orderdesc", "nulls", "first", "offset", "after", "random", "seed":
 		return true
 	}
 	return false
@@ -2212,6 +2470,16 @@ func getRoot(it *lex.ItemIterator) (gq *GraphQuery, rerr error) {
 			if !validFuncName(gen.Name) {
 				return nil, x.Errorf("Function name: %s is not valid.", gen.Name)
 			}
+			if gen.Name == "join" {
+				if err := validateJoin(gen); err != nil {
+					return nil, err
+				}
+			}
+			if gen.Name == "distinct" {
+				if err := validateDistinct(gen); err != nil {
+					return nil, err
+				}
+			}
 			gq.Func = gen
 			gq.NeedsVar = append(gq.NeedsVar, gen.NeedsVar...)
 		} else {
@@ -2367,7 +2635,8 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 
 			val := collectName(it, item.Val)
 			valLower := strings.ToLower(val)
-			if gq.IsGroupby && (!isAggregator(val) && val != "count" && count != seen) {
+			if gq.IsGroupby && (!isAggregator(val) && val != "count" && count != seen) &&
+				peekIt[0].Typ != itemColon {
 				// Only aggregator or count allowed inside the groupby block.
 				return x.Errorf("Only aggregator/count functions allowed inside @groupby. Got: %v", val)
 			}
@@ -2394,6 +2663,63 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 				gq.Children = append(gq.Children, child)
 				curp = nil
 				continue
+			} else if valLower == "bm25" || valLower == "distance" {
+				peekIt, err = it.Peek(1)
+				if err != nil {
+					return err
+				}
+				if peekIt[0].Typ != itemLeftRound {
+					goto Fall
+				}
+				child := &GraphQuery{
+					Args:  make(map[string]string),
+					Var:   varName,
+					Alias: alias,
+				}
+				varName, alias = "", ""
+				it.Prev()
+				if child.Func, err = parseFunction(it, gq); err != nil {
+					return err
+				}
+				if valLower == "bm25" {
+					err = validateTextScore(child.Func)
+				} else {
+					err = validateDistance(child.Func)
+				}
+				if err != nil {
+					return err
+				}
+				child.Attr = child.Func.Attr
+				gq.Children = append(gq.Children, child)
+				curp = nil
+				continue
+			} else if valLower == "join" {
+				peekIt, err = it.Peek(1)
+				if err != nil {
+					return err
+				}
+				if peekIt[0].Typ != itemLeftRound {
+					goto Fall
+				}
+				child := &GraphQuery{
+					Args:  make(map[string]string),
+					Var:   varName,
+					Alias: alias,
+				}
+				varName, alias = "", ""
+				it.Prev()
+				if child.Func, err = parseFunction(it, gq); err != nil {
+					return err
+				}
+				if err = validateJoin(child.Func); err != nil {
+					return err
+				}
+				child.Attr = child.Func.Attr
+				child.NeedsVar = append(child.NeedsVar, child.Func.NeedsVar...)
+				gq.Children = append(gq.Children, child)
+				// Note: curp is not set to nil. So it can have children, filters, etc.
+				curp = child
+				continue
 			} else if isAggregator(valLower) {
 				child := &GraphQuery{
 					Attr:       value,
@@ -2441,7 +2767,19 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 					Name:     valLower,
 					NeedsVar: child.NeedsVar,
 				}
-				it.Next() // Skip the closing ')'
+				it.Next()
+				if it.Item().Typ == itemComma {
+					it.Next()
+					child.Func.Args = append(child.Func.Args, Arg{Value: it.Item().Val})
+					it.Next()
+				}
+				if it.Item().Typ != itemRightRound {
+					return x.Errorf("Expected ) at the end of %s. Got: %v", valLower,
+						it.Item().Val)
+				}
+				if err := validateAggregatorArgs(child.Func); err != nil {
+					return err
+				}
 				gq.Children = append(gq.Children, child)
 				curp = nil
 				continue
@@ -2617,6 +2955,31 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 			if count == seen {
 				count = seenWithPred
 			}
+		case itemPath:
+			if gq.IsGroupby {
+				return x.Errorf("Only aggregator/count functions allowed inside @groupby. Got: %v",
+					item.Val)
+			}
+			if count != notSeen {
+				return x.Errorf("count of a path expression is not allowed")
+			}
+			path, err := parsePath(item.Val)
+			if err != nil {
+				return err
+			}
+			child := &GraphQuery{
+				Args:  make(map[string]string),
+				Attr:  item.Val,
+				Path:  path,
+				Var:   varName,
+				Alias: alias,
+			}
+			if gq.IsCount {
+				return x.Errorf("Cannot have children attributes when asking for count.")
+			}
+			gq.Children = append(gq.Children, child)
+			varName, alias = "", ""
+			curp = child
 		case itemLeftCurl:
 			if len(curp.Langs) > 0 {
 				return x.Errorf("Cannot have children for attr: %s with lang tags: %v", curp.Attr,
@@ -2673,7 +3036,12 @@ func godeep(it *lex.ItemIterator, gq *GraphQuery) error {
 }
 
 func isAggregator(fname string) bool {
-	return fname == "min" || fname == "max" || fname == "sum" || fname == "avg"
+	switch fname {
+	case "min", "max", "sum", "avg", "median", "percentile", "stddev", "variance",
+		"count_distinct", "approx_count_distinct":
+		return true
+	}
+	return false
 }
 
 func isExpandFunc(name string) bool {
@@ -2685,7 +3053,8 @@ func isMathBlock(name string) bool {
 }
 
 func isGeoFunc(name string) bool {
-	return name == "near" || name == "contains" || name == "within" || name == "intersects"
+	return name == "near" || name == "contains" || name == "within" || name == "intersects" ||
+		name == "knn"
 }
 
 func isInequalityFn(name string) bool {
@@ -2696,6 +3065,42 @@ func isInequalityFn(name string) bool {
 	return false
 }
 
+// parseCascade parses @cascade with an optional list of required predicates,
+// like @cascade(name, email).
+func parseCascade(it *lex.ItemIterator, gq *GraphQuery) error {
+	gq.Cascade = true
+	items, err := it.Peek(1)
+	if err != nil || items[0].Typ != itemLeftRound {
+		return nil
+	}
+	it.Next() // Consume the '('.
+	expectArg := true
+	for it.Next() {
+		item := it.Item()
+		if item.Typ == itemRightRound {
+			break
+		}
+		if item.Typ == itemComma {
+			if expectArg {
+				return x.Errorf("Expected a predicate but got comma")
+			}
+			expectArg = true
+		} else if item.Typ == itemName {
+			if !expectArg {
+				return x.Errorf("Expected a comma or right round but got: %v", item.Val)
+			}
+			gq.CascadeFields = append(gq.CascadeFields, collectName(it, item.Val))
+			expectArg = false
+		} else {
+			return x.Errorf("Unexpected item in cascade: %v", item.Val)
+		}
+	}
+	if expectArg {
+		return x.Errorf("Expected atleast one predicate in cascade")
+	}
+	return nil
+}
+
 // Name can have dashes or alphanumeric characters. Lexer lexes them as separate items.
 // We put it back together here.
 func collectName(it *lex.ItemIterator, val string) string {
@@ -2381,6 +2381,23 @@ func TestParseFilter_Geo1(t *testing.T) {
 	require.Equal(t, false, resp.Query[0].Children[0].Filter.Func.Args[1].IsValueVar)
 }
 
+func TestParseKnn(t *testing.T) {
+	query := `
+	{
+		me(func: knn(loc, [-122.41 , 37.77 ], 5)) {
+			name
+			distance(loc)
+		}
+	}
+`
+	resp, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.Equal(t, "knn", resp.Query[0].Func.Name)
+	require.Equal(t, "loc", resp.Query[0].Func.Attr)
+	require.Equal(t, "[-122.41,37.77]", resp.Query[0].Func.Args[0].Value)
+	require.Equal(t, "5", resp.Query[0].Func.Args[1].Value)
+}
+
 func TestParseFilter_Geo2(t *testing.T) {
 	query := `
 	query {
@@ -3184,6 +3201,117 @@ func TestParseGroupbyError(t *testing.T) {
 	require.Contains(t, err.Error(), "Only aggregator/count functions allowed inside @groupby")
 }
 
+func TestParsePercentile(t *testing.T) {
+	query := `
+	query {
+		me(func: uid(0x1)) {
+			friends @groupby(age) {
+				percentile(score, 95)
+				median(score)
+			}
+		}
+		var(func: uid(0x1)) {
+			a as age
+		}
+		agg() {
+			percentile(val(a), 99.9)
+		}
+	}
+`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	perc := res.Query[0].Children[0].Children[0]
+	require.Equal(t, "score", perc.Attr)
+	require.Equal(t, "percentile", perc.Func.Name)
+	require.Equal(t, []Arg{{Value: "95"}}, perc.Func.Args)
+	require.Empty(t, res.Query[0].Children[0].Children[1].Func.Args)
+
+	perc = res.Query[2].Children[0]
+	require.Equal(t, "percentile", perc.Func.Name)
+	require.Equal(t, "a", perc.NeedsVar[0].Name)
+	require.Equal(t, []Arg{{Value: "99.9"}}, perc.Func.Args)
+}
+
+func TestParsePercentileError(t *testing.T) {
+	tests := []struct {
+		agg string
+		err string
+	}{
+		{"percentile(val(a))", "percentile expects a percentage"},
+		{"percentile(val(a), 101)", "Invalid percentile"},
+		{"percentile(val(a), x)", "Invalid percentile"},
+		{"percentile(val(a), NaN)", "Invalid percentile"},
+		{"percentile(val(a), Inf)", "Invalid percentile"},
+		{"median(val(a), 50)", "Aggregator median doesn't take any arguments"},
+	}
+	for _, test := range tests {
+		query := `
+		{
+			var(func: uid(0x1)) {
+				a as age
+			}
+			agg() {
+				` + test.agg + `
+			}
+		}`
+		_, err := Parse(Request{Str: query})
+		require.Error(t, err, test.agg)
+		require.Contains(t, err.Error(), test.err, test.agg)
+	}
+}
+
+func TestParseGroupbyParams(t *testing.T) {
+	query := `
+	query {
+		me(func: uid(0x1)) {
+			friends @groupby(school @facets(since), friend/age, orderdesc: c, orderasc: age,
+				first: 10, offset: 5) @having(gt(c, 2)) {
+				c: count(_uid_)
+			}
+		}
+	}
+`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	gq := res.Query[0].Children[0]
+	require.Equal(t, 2, len(gq.GroupbyAttrs))
+	require.Equal(t, "school", gq.GroupbyAttrs[0].Attr)
+	require.Equal(t, "since", gq.GroupbyAttrs[0].Facet)
+	require.Equal(t, "friend/age", gq.GroupbyAttrs[1].Attr)
+	require.Equal(t, "friend/age", gq.GroupbyAttrs[1].Path.String())
+	require.Equal(t, []*protos.Order{{Attr: "c", Desc: true}, {Attr: "age"}}, gq.GroupbyOrder)
+	require.Equal(t, map[string]string{"first": "10", "offset": "5"}, gq.GroupbyArgs)
+	require.Equal(t, "(gt c \"2\")", gq.GroupbyHaving.debugString())
+	require.Equal(t, "c", gq.Children[0].Alias)
+}
+
+func TestParseGroupbyParamsError(t *testing.T) {
+	tests := []struct {
+		groupby string
+		err     string
+	}{
+		{"@groupby(age, first: -1)", "Expected a non-negative number for first"},
+		{"@groupby(age, first: 0)", "Expected a positive number for first"},
+		{"@groupby(age, first: 1, first: 2)", "Got repeated key \"first\""},
+		{"@groupby(age, after: 0x1)", "Invalid argument \"after\" in groupby"},
+		{"@groupby(orderasc: age)", "Expected atleast one attribute in groupby"},
+		{"@groupby(friend/age @facets(since))", "Cannot group by facets of path"},
+	}
+	for _, test := range tests {
+		query := `
+		{
+			me(func: uid(0x1)) {
+				friends ` + test.groupby + ` {
+					count(_uid_)
+				}
+			}
+		}`
+		_, err := Parse(Request{Str: query})
+		require.Error(t, err, test.groupby)
+		require.Contains(t, err.Error(), test.err, test.groupby)
+	}
+}
+
 func TestParseFacetsError1(t *testing.T) {
 	query := `
 	query {
@@ -3614,6 +3742,20 @@ func TestFacetsFilterFail2(t *testing.T) {
 	require.Contains(t, err.Error(), "Only one facets filter allowed")
 }
 
+func TestFacetsFilterAtRoot(t *testing.T) {
+	query := `
+	{
+		me(func: has(rated)) @facets(gt(score, 4)) {
+			_uid_
+		}
+	}
+`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.NotNil(t, res.Query[0].FacetsFilter)
+	require.Equal(t, `(gt score "4")`, res.Query[0].FacetsFilter.debugString())
+}
+
 func TestFacetsFilterFail3(t *testing.T) {
 	// vars are not allowed in facets filtering.
 	query := `
@@ -3647,7 +3789,7 @@ func TestFacetsFilterFailRoot(t *testing.T) {
 
 	_, err := Parse(Request{Str: query, Http: true})
 	require.Error(t, err)
-	require.Contains(t, err.Error(), "Unknown directive [facets]")
+	require.Contains(t, err.Error(), "Facets filter at root can only be used with has function")
 }
 
 func TestFacetsFilterAtValue(t *testing.T) {
@@ -4227,6 +4369,23 @@ func TestOrder2(t *testing.T) {
 	require.Equal(t, true, curp.Order[1].Desc)
 }
 
+func TestOrderNulls(t *testing.T) {
+	query := `
+		{
+			me(func: uid(1), orderasc: name@de, nulls: last) {
+				friend(orderdesc: age, nulls: first) {
+					name
+				}
+			}
+		}
+	`
+	gq, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.Equal(t, "name@de", gq.Query[0].Order[0].Attr)
+	require.Equal(t, "last", gq.Query[0].Args["nulls"])
+	require.Equal(t, "first", gq.Query[0].Children[0].Args["nulls"])
+}
+
 func TestMultipleOrderError(t *testing.T) {
 	query := `
 		{
@@ -4291,3 +4450,309 @@ func TestUpsertQueryError2(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "Upsert query can only have one argument.")
 }
+
+func TestParsePath(t *testing.T) {
+	query := `
+	{
+		me(func: uid(1)) {
+			fof as friend/(follows|~follows)*{1,3}/name
+			friend/follows+ (first: 10) @filter(has(name)) {
+				name
+			}
+			p: knows/knows?
+			follows*{2,}
+		}
+		fof(func: uid(fof)) {
+			name
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	children := res.Query[0].Children
+	require.Equal(t, 4, len(children))
+	require.Equal(t, "friend/(follows|~follows)*{1,3}/name", children[0].Attr)
+	require.Equal(t, "fof", children[0].Var)
+	require.Equal(t, "friend/(follows|~follows){1,3}/name", children[0].Path.String())
+	require.Equal(t, []string{"friend", "follows", "follows", "name"}, children[0].Path.Attrs())
+
+	require.Equal(t, "friend/follows{1,}", children[1].Path.String())
+	require.Equal(t, "10", children[1].Args["first"])
+	require.NotNil(t, children[1].Filter)
+	require.Equal(t, 1, len(children[1].Children))
+
+	require.Equal(t, "p", children[2].Alias)
+	require.Equal(t, "knows/knows{0,1}", children[2].Path.String())
+
+	require.Equal(t, "follows{2,}", children[3].Path.String())
+}
+
+func TestParsePathError(t *testing.T) {
+	query := `
+	{
+		me(func: uid(1)) {
+			friend/(follows|~follows
+		}
+	}
+	`
+	_, err := Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unclosed group in path expression")
+
+	query = `
+	{
+		me(func: uid(1)) {
+			friend/follows{3,1}
+		}
+	}
+	`
+	_, err = Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "less than min")
+
+	query = `
+	{
+		me(func: uid(1)) {
+			friend/a~b
+		}
+	}
+	`
+	_, err = Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `expected predicate name. Got: "a~b"`)
+}
+
+func TestParseExistsFilter(t *testing.T) {
+	query := `
+	{
+		me(func: uid(1)) @filter(exists { friend @filter(eq(name, "a")) { name } } or not exists { ~follows }) {
+			name
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.NotNil(t, res.Query[0].Filter)
+	require.Equal(t, `(OR (exists) (NOT (exists)))`, res.Query[0].Filter.debugString())
+
+	exists := res.Query[0].Filter.Child[0].Exists
+	require.Equal(t, 1, len(exists.Children))
+	require.Equal(t, "friend", exists.Children[0].Attr)
+	require.Equal(t, `(eq name "a")`, exists.Children[0].Filter.debugString())
+	require.Equal(t, []string{"name"}, childAttrs(exists.Children[0]))
+	require.Equal(t, "~follows", res.Query[0].Filter.Child[1].Child[0].Exists.Children[0].Attr)
+	require.Equal(t, []string{"name"}, childAttrs(res.Query[0]))
+}
+
+func TestParseExistsFilterWithVar(t *testing.T) {
+	query := `
+	{
+		var(func: uid(1)) {
+			f as friend
+		}
+		me(func: uid(1)) @filter(exists { friend @filter(uid(f)) }) {
+			name
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"f"}, res.QueryVars[1].Needs)
+
+	query = `
+	{
+		me(func: uid(1)) @filter(exists { f as friend }) {
+			name
+		}
+	}
+	`
+	_, err = Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Variables cannot be defined inside exists")
+}
+
+func TestParseCascadeFields(t *testing.T) {
+	query := `
+	{
+		me(func: uid(1)) @cascade(name, email) {
+			name
+			email
+			phone
+			friend @cascade(~follows) {
+				name
+				~follows
+			}
+			school @cascade {
+				name
+			}
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	require.True(t, res.Query[0].Cascade)
+	require.Equal(t, []string{"name", "email"}, res.Query[0].CascadeFields)
+	require.True(t, res.Query[0].Children[3].Cascade)
+	require.Equal(t, []string{"~follows"}, res.Query[0].Children[3].CascadeFields)
+	require.True(t, res.Query[0].Children[4].Cascade)
+	require.Nil(t, res.Query[0].Children[4].CascadeFields)
+	require.False(t, res.Query[0].Children[0].Cascade)
+}
+
+func TestParseCascadeFieldsError(t *testing.T) {
+	query := `
+	{
+		me(func: uid(1)) @cascade(name,) {
+			name
+		}
+	}
+	`
+	_, err := Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+}
+
+func TestParseJoin(t *testing.T) {
+	query := `
+	{
+		var(func: has(order.customer_email)) {
+			e as order.customer_email
+		}
+		orders(func: uid(e)) {
+			customer: join(customer.email, val(e)) {
+				name
+			}
+		}
+		customers(func: join(customer.email, val(e))) {
+			name
+		}
+	}
+	`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	child := res.Query[1].Children[0]
+	require.Equal(t, "customer", child.Alias)
+	require.Equal(t, "customer.email", child.Attr)
+	require.Equal(t, "join", child.Func.Name)
+	require.Equal(t, []Arg{{Value: "e", IsValueVar: true}}, child.Func.Args)
+	require.Equal(t, []string{"name"}, childAttrs(child))
+	require.Equal(t, "join", res.Query[2].Func.Name)
+	require.Equal(t, []string{"e"}, res.QueryVars[2].Needs)
+}
+
+func TestParseJoinError(t *testing.T) {
+	query := `
+	{
+		me(func: join(customer.email, "a@b.com")) {
+			name
+		}
+	}
+	`
+	_, err := Parse(Request{Str: query, Http: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "join expects a predicate and a value variable")
+}
+
+func TestParseDistinct(t *testing.T) {
+	query := `
+		{
+			me(func: distinct(category, "elec"), first: 10) {
+				category
+			}
+		}
+	`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	require.Equal(t, "distinct", res.Query[0].Func.Name)
+	require.Equal(t, "category", res.Query[0].Func.Attr)
+	require.Equal(t, "elec", res.Query[0].Func.Args[0].Value)
+}
+
+func TestParseDistinctError(t *testing.T) {
+	query := `
+		{
+			me(func: distinct(category, "a", "b")) {
+				category
+			}
+		}
+	`
+	_, err := Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "distinct expects a predicate and an optional prefix")
+}
+
+func TestParseMathValueFuncs(t *testing.T) {
+	query := `
+	{
+		var(func: uid(0x0a)) {
+			friends {
+				d as dob
+				n as name
+				length as count(friends)
+				a as math(extract(truncate(dateadd(d, "-1mo2d"), "month"), "year"))
+				b as math(concat(upper(n), " has ", tostring(length * 2), " friends"))
+				c as math(substring(lower(n), 0, length(n) - 1))
+			}
+		}
+
+		me(func: uid(0x0a)) {
+			val(a)
+			val(b)
+			val(c)
+		}
+	}
+`
+	res, err := Parse(Request{Str: query, Http: true})
+	require.NoError(t, err)
+	children := res.Query[0].Children[0].Children
+	require.EqualValues(t, `(extract (truncate (dateadd d "-1mo2d") "month") "year")`,
+		children[3].MathExp.debugString())
+	require.EqualValues(t, `(concat (upper n) " has " (tostring (* length 2E+00)) " friends")`,
+		children[4].MathExp.debugString())
+	require.EqualValues(t, `(substring (lower n) 0E+00 (- (length n) 1E+00))`,
+		children[5].MathExp.debugString())
+}
+
+func TestValueFuncsPrecedence(t *testing.T) {
+	for _, f := range ValueFuncs {
+		// Between the other functions and the operators.
+		require.True(t, mathOpPrecedence[f] > mathOpPrecedence["/"], f)
+		require.True(t, mathOpPrecedence[f] < mathOpPrecedence["min"], f)
+	}
+}
+
+func TestParseTextScore(t *testing.T) {
+	query := `
+		{
+			me(func: anyoftext(description, "red shoes")) {
+				s as bm25(description, "red shoes")
+				description
+			}
+
+			top(func: uid(s), orderdesc: val(s), first: 10) {
+				description
+			}
+		}
+	`
+	res, err := Parse(Request{Str: query})
+	require.NoError(t, err)
+	child := res.Query[0].Children[0]
+	require.Equal(t, "s", child.Var)
+	require.Equal(t, "description", child.Attr)
+	require.NotNil(t, child.Func)
+	require.Equal(t, "bm25", child.Func.Name)
+	require.Equal(t, []Arg{{Value: "red shoes"}}, child.Func.Args)
+	require.Equal(t, "description", res.Query[0].Children[1].Attr)
+}
+
+func TestParseTextScoreError(t *testing.T) {
+	query := `
+		{
+			me(func: uid(1)) {
+				bm25(description)
+			}
+		}
+	`
+	_, err := Parse(Request{Str: query})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "bm25 expects a predicate and the text to score against")
+}
@@ -0,0 +1,280 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gql
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Operators of a PathTree.
+const (
+	PathSeq    = "/"
+	PathAlt    = "|"
+	PathRepeat = "*"
+)
+
+// PathTree is the parsed form of a regular path expression like
+// friend/(follows|~follows)*{1,3}/name. Leaves have an Attr and no Op.
+// A repeat node has exactly one child, and Max is -1 when unbounded.
+type PathTree struct {
+	Op    string
+	Attr  string
+	Min   int
+	Max   int
+	Child []*PathTree
+}
+
+// Attrs returns all the predicates referred to by the path.
+func (t *PathTree) Attrs() []string {
+	if t.Op == "" {
+		return []string{strings.TrimPrefix(t.Attr, "~")}
+	}
+	var out []string
+	for _, c := range t.Child {
+		out = append(out, c.Attrs()...)
+	}
+	return out
+}
+
+func (t *PathTree) stringHelper(buf *bytes.Buffer) {
+	switch t.Op {
+	case "":
+		buf.WriteString(t.Attr)
+	case PathRepeat:
+		c := t.Child[0]
+		if c.Op == PathSeq || c.Op == PathAlt {
+			buf.WriteRune('(')
+			c.stringHelper(buf)
+			buf.WriteRune(')')
+		} else {
+			c.stringHelper(buf)
+		}
+		if t.Max < 0 {
+			fmt.Fprintf(buf, "{%d,}", t.Min)
+		} else {
+			fmt.Fprintf(buf, "{%d,%d}", t.Min, t.Max)
+		}
+	default:
+		for i, c := range t.Child {
+			if i > 0 {
+				buf.WriteString(t.Op)
+			}
+			if t.Op == PathSeq && c.Op == PathAlt {
+				buf.WriteRune('(')
+				c.stringHelper(buf)
+				buf.WriteRune(')')
+				continue
+			}
+			c.stringHelper(buf)
+		}
+	}
+}
+
+func (t *PathTree) String() string {
+	if t == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	t.stringHelper(&buf)
+	return buf.String()
+}
+
+type pathParser struct {
+	input string
+	pos   int
+}
+
+func (p *pathParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *pathParser) errorf(format string, args ...interface{}) error {
+	return x.Errorf("Invalid path expression %q at position %d: %s",
+		p.input, p.pos, fmt.Sprintf(format, args...))
+}
+
+// parsePath parses a regular path expression as emitted by the lexer.
+func parsePath(input string) (*PathTree, error) {
+	p := &pathParser{input: input}
+	t, err := p.parseAlt()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.input) {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return t, nil
+}
+
+func (p *pathParser) parseAlt() (*PathTree, error) {
+	t, err := p.parseSeq()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '|' {
+		return t, nil
+	}
+	alt := &PathTree{Op: PathAlt, Child: []*PathTree{t}}
+	for p.peek() == '|' {
+		p.pos++
+		c, err := p.parseSeq()
+		if err != nil {
+			return nil, err
+		}
+		alt.Child = append(alt.Child, c)
+	}
+	return alt, nil
+}
+
+func (p *pathParser) parseSeq() (*PathTree, error) {
+	t, err := p.parseRepeat()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != '/' {
+		return t, nil
+	}
+	seq := &PathTree{Op: PathSeq, Child: []*PathTree{t}}
+	for p.peek() == '/' {
+		p.pos++
+		c, err := p.parseRepeat()
+		if err != nil {
+			return nil, err
+		}
+		seq.Child = append(seq.Child, c)
+	}
+	return seq, nil
+}
+
+func (p *pathParser) parseRepeat() (*PathTree, error) {
+	t, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		min, max := 0, 0
+		switch p.peek() {
+		case '*':
+			p.pos++
+			min, max = 0, -1
+			if p.peek() == '{' {
+				// Bounds given after a star override it, as in follows*{1,3}.
+				if min, max, err = p.parseBounds(); err != nil {
+					return nil, err
+				}
+			}
+		case '+':
+			p.pos++
+			min, max = 1, -1
+		case '?':
+			p.pos++
+			min, max = 0, 1
+		case '{':
+			if min, max, err = p.parseBounds(); err != nil {
+				return nil, err
+			}
+		default:
+			return t, nil
+		}
+		t = &PathTree{Op: PathRepeat, Min: min, Max: max, Child: []*PathTree{t}}
+	}
+}
+
+// parseBounds parses {n}, {n,} and {n,m}.
+func (p *pathParser) parseBounds() (int, int, error) {
+	end := strings.IndexByte(p.input[p.pos:], '}')
+	if end < 0 {
+		return 0, 0, p.errorf("unclosed repetition bounds")
+	}
+	bounds := p.input[p.pos+1 : p.pos+end]
+	p.pos += end + 1
+
+	parts := strings.Split(bounds, ",")
+	if len(parts) > 2 {
+		return 0, 0, p.errorf("too many repetition bounds: %q", bounds)
+	}
+	min, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, p.errorf("invalid repetition bound: %q", parts[0])
+	}
+	max := min
+	if len(parts) == 2 {
+		if parts[1] == "" {
+			max = -1
+		} else if max, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, p.errorf("invalid repetition bound: %q", parts[1])
+		}
+	}
+	if max >= 0 && max < min {
+		return 0, 0, p.errorf("max repetitions %d less than min %d", max, min)
+	}
+	return min, max, nil
+}
+
+func (p *pathParser) parseAtom() (*PathTree, error) {
+	if p.peek() == '(' {
+		p.pos++
+		t, err := p.parseAlt()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("expected )")
+		}
+		p.pos++
+		return t, nil
+	}
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '/' || c == '|' || c == '(' || c == ')' || c == '*' || c == '+' ||
+			c == '?' || c == '{' {
+			break
+		}
+		p.pos++
+	}
+	attr := p.input[start:p.pos]
+	if !isPathAttr(attr) {
+		p.pos = start
+		return nil, p.errorf("expected predicate name. Got: %q", attr)
+	}
+	return &PathTree{Attr: attr}, nil
+}
+
+// isPathAttr returns true if attr is a predicate name as lexed by lexName,
+// with a ~ only allowed in front of it to follow the reverse edges.
+func isPathAttr(attr string) bool {
+	attr = strings.TrimPrefix(attr, "~")
+	for i, r := range attr {
+		if r == '~' {
+			return false
+		}
+		if (i == 0 && !isNameBegin(r)) || (i > 0 && !isNameSuffix(r)) {
+			return false
+		}
+	}
+	return attr != ""
+}
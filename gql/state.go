@@ -18,7 +18,11 @@
 // Package gql is responsible for lexing and parsing a GraphQL query/mutation.
 package gql
 
-import "github.com/dgraph-io/dgraph/lex"
+import (
+	"strings"
+
+	"github.com/dgraph-io/dgraph/lex"
+)
 
 const (
 	leftCurl    = '{'
@@ -64,6 +68,7 @@ const (
 	itemRightSquare
 	itemComma
 	itemMathOp
+	itemPath // regular path expression over predicates
 )
 
 func lexInsideMutation(l *lex.Lexer) lex.StateFn {
@@ -222,6 +227,11 @@ func lexFuncOrArg(l *lex.Lexer) lex.StateFn {
 			l.Emit(itemLeftSquare)
 		case r == rightSquare:
 			l.Emit(itemRightSquare)
+		case r == leftCurl && startsBlock(l):
+			// A query block inside a function, e.g. exists { friend }.
+			l.Emit(itemLeftCurl)
+			l.PushBlock()
+			return lexQuery
 		case r == '#':
 			return lexComment
 		case r == '.':
@@ -232,6 +242,13 @@ func lexFuncOrArg(l *lex.Lexer) lex.StateFn {
 	}
 }
 
+// startsBlock returns true if a { seen inside a function starts a query
+// block, which is only allowed right after exists.
+func startsBlock(l *lex.Lexer) bool {
+	item, ok := l.LastItem()
+	return ok && item.Typ == itemName && strings.ToLower(item.Val) == "exists"
+}
+
 func lexTopLevel(l *lex.Lexer) lex.StateFn {
 	l.Mode = lexTopLevel
 Loop:
@@ -278,6 +295,10 @@ func lexQuery(l *lex.Lexer) lex.StateFn {
 		case r == rightCurl:
 			l.Depth--
 			l.Emit(itemRightCurl)
+			if l.PopBlock() {
+				// Done with a block inside a function, e.g. exists { ... }.
+				return lexFuncOrArg
+			}
 			if l.Depth == 0 {
 				return lexTopLevel
 			}
@@ -291,7 +312,7 @@ func lexQuery(l *lex.Lexer) lex.StateFn {
 		case r == comma:
 			l.Emit(itemComma)
 		case isNameBegin(r):
-			return lexName
+			return lexNameOrPath
 		case r == '#':
 			return lexComment
 		case r == '-':
@@ -382,6 +403,57 @@ func lexComment(l *lex.Lexer) lex.StateFn {
 	return nil // Stop the run loop.
 }
 
+// lexNameOrPath lexes a predicate name. If the name is followed by a slash or
+// a repetition, the whole regular path expression (e.g.
+// friend/(follows|~follows)*{1,3}/name) is emitted as a single itemPath.
+func lexNameOrPath(l *lex.Lexer) lex.StateFn {
+	// The caller already checked isNameBegin, and absorbed one rune.
+	l.AcceptRun(isNameSuffix)
+	pos := l.Pos
+	r := l.Next()
+	isPath := r == slash || r == '*' || r == '+' || r == '?' ||
+		(r == leftCurl && isNumber(l.Next()))
+	l.Pos = pos
+	if isPath {
+		return lexPath
+	}
+	l.Emit(itemName)
+	return l.Mode
+}
+
+// lexPath is called when we are inside a path expression. Round brackets
+// only start a group after a slash, a pipe or another round bracket,
+// otherwise they start the arguments of the path.
+func lexPath(l *lex.Lexer) lex.StateFn {
+	depth := 0
+	prev := slash
+	for {
+		r := l.Next()
+		switch {
+		case isNameSuffix(r) || r == slash || r == '|' || r == '*' || r == '+' || r == '?':
+		case r == leftRound && (prev == slash || prev == '|' || prev == leftRound):
+			depth++
+		case r == rightRound && depth > 0:
+			depth--
+		case r == leftCurl && isNumber(l.Peek()):
+			// Repetition bounds like {2} or {1,3}.
+			l.AcceptRun(func(r rune) bool { return isNumber(r) || r == comma })
+			if l.Next() != rightCurl {
+				return l.Errorf("Expected } after repetition bounds in path")
+			}
+			r = rightCurl
+		default:
+			l.Backup()
+			if depth != 0 {
+				return l.Errorf("Unclosed group in path expression")
+			}
+			l.Emit(itemPath)
+			return l.Mode
+		}
+		prev = r
+	}
+}
+
 // lexNameMutation lexes the itemMutationOp, which could be set or delete.
 func lexNameMutation(l *lex.Lexer) lex.StateFn {
 	for {
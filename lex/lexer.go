@@ -130,6 +130,42 @@ type Lexer struct {
 	Depth    int     // nesting of {}
 	ArgDepth int     // nesting of ()
 	Mode     StateFn // Default state to go back to after reading a token.
+
+	// blocks holds the {} and () nesting at which blocks nested inside
+	// arguments were opened, e.g. @filter(exists { friend }).
+	blocks []nesting
+}
+
+type nesting struct {
+	depth    int
+	argDepth int
+}
+
+// LastItem returns the last item emitted, if any.
+func (l *Lexer) LastItem() (Item, bool) {
+	if len(l.items) == 0 {
+		return Item{}, false
+	}
+	return l.items[len(l.items)-1], true
+}
+
+// PushBlock is called when a {} block starts inside arguments. The argument
+// nesting is reset and restored by PopBlock once the block is closed.
+func (l *Lexer) PushBlock() {
+	l.blocks = append(l.blocks, nesting{depth: l.Depth, argDepth: l.ArgDepth})
+	l.Depth++
+	l.ArgDepth = 0
+}
+
+// PopBlock returns true if the current {} nesting closes the innermost block
+// opened by PushBlock, in which case the argument nesting is restored.
+func (l *Lexer) PopBlock() bool {
+	if len(l.blocks) == 0 || l.blocks[len(l.blocks)-1].depth != l.Depth {
+		return false
+	}
+	l.ArgDepth = l.blocks[len(l.blocks)-1].argDepth
+	l.blocks = l.blocks[:len(l.blocks)-1]
+	return true
 }
 
 func (l *Lexer) Run(f StateFn) *Lexer {
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// compositeLocks serialize the mutations of the predicates of composite
+// indexes for the same uid, as the tokens are computed from the values of all
+// of them. The uids share a fixed number of locks.
+var compositeLocks [256]sync.Mutex
+
+func compositeLock(uid uint64) *sync.Mutex {
+	return &compositeLocks[uid%uint64(len(compositeLocks))]
+}
+
+// compositeIndexes returns the composite indexes to update when the values of
+// attr change.
+func compositeIndexes(attr string) []tok.CompositeIndex {
+	if pstore == nil {
+		return nil
+	}
+	return schema.State().CompositeIndexes(attr)
+}
+
+// compositeTokens returns the tokens of the composite index for the values of
+// uid. The values with a language aren't indexed, and neither are the uids when
+// a predicate has become a list, as a token is kept for a single value of each.
+func compositeTokens(ci tok.CompositeIndex, uid uint64) ([]string, error) {
+	vals := make([][]types.Val, len(ci.Preds))
+	for i, pred := range ci.Preds {
+		typ, err := schema.State().TypeOf(pred)
+		if err != nil {
+			// There can't be values without a schema.
+			return nil, nil
+		}
+		if schema.State().IsList(pred) {
+			return nil, nil
+		}
+		Get(x.DataKey(pred, uid)).Iterate(0, func(p *protos.Posting) bool {
+			if postingType(p) != x.ValuePlain {
+				return true
+			}
+			src := types.Val{Tid: types.TypeID(p.ValType), Value: p.Value}
+			if v, err := types.Convert(src, typ); err == nil {
+				vals[i] = append(vals[i], v)
+			}
+			return true
+		})
+		if len(vals[i]) == 0 {
+			return nil, nil
+		}
+	}
+	return ci.Tokens(vals)
+}
+
+// compositeIndexTokens returns the tokens of every composite index for the
+// values of uid.
+func compositeIndexTokens(cis []tok.CompositeIndex, uid uint64) ([][]string, error) {
+	tokens := make([][]string, len(cis))
+	for i, ci := range cis {
+		var err error
+		if tokens[i], err = compositeTokens(ci, uid); err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+// updateCompositeIndexes updates the composite indexes, which had the tokens
+// before for uid, to the current values of uid. The tokens are kept in the
+// index of the first predicate of every composite index.
+func updateCompositeIndexes(ctx context.Context, cis []tok.CompositeIndex, uid uint64,
+	before [][]string) error {
+	after, err := compositeIndexTokens(cis, uid)
+	if err != nil {
+		return err
+	}
+	for i, ci := range cis {
+		var prev []string
+		if before != nil {
+			prev = before[i]
+		}
+		if err := updateIndexTokens(ctx, ci.Preds[0], uid, prev, after[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateIndexTokens updates the index of attr for uid, which had the tokens
+// before and has the tokens after.
+func updateIndexTokens(ctx context.Context, attr string, uid uint64,
+	before, after []string) error {
+	had := make(map[string]bool, len(before))
+	for _, token := range before {
+		had[token] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, token := range after {
+		has[token] = true
+	}
+	for _, token := range before {
+		if has[token] {
+			continue
+		}
+		edge := &protos.DirectedEdge{ValueId: uid, Attr: attr, Op: protos.DirectedEdge_DEL}
+		if err := addIndexMutation(ctx, edge, token); err != nil {
+			return err
+		}
+	}
+	for _, token := range after {
+		if had[token] {
+			continue
+		}
+		edge := &protos.DirectedEdge{ValueId: uid, Attr: attr, Op: protos.DirectedEdge_SET}
+		if err := addIndexMutation(ctx, edge, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declaredCompositeIndexes returns the composite indexes declared by attr, which
+// are kept in its index.
+func declaredCompositeIndexes(attr string) []tok.CompositeIndex {
+	var out []tok.CompositeIndex
+	for _, ci := range schema.State().CompositeIndexes(attr) {
+		if ci.Preds[0] == attr {
+			out = append(out, ci)
+		}
+	}
+	return out
+}
+
+// rebuildCompositeIndexes rebuilds the indexes of the predicates having
+// composite indexes with attr, after its values have been deleted.
+func rebuildCompositeIndexes(ctx context.Context, attr string) error {
+	for _, ci := range schema.State().CompositeIndexes(attr) {
+		if ci.Preds[0] == attr {
+			continue
+		}
+		if err := DeleteIndex(ctx, ci.Preds[0]); err != nil {
+			return err
+		}
+		if err := RebuildIndex(ctx, ci.Preds[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"context"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// facetIndexes returns the facet indexes to update when the edges of attr
+// change.
+func facetIndexes(attr string) []tok.FacetIndex {
+	if pstore == nil {
+		return nil
+	}
+	return schema.State().FacetIndexes(attr)
+}
+
+// addFacetTokens adds the tokens of the facet indexes for the facets of an
+// edge to tokens.
+func addFacetTokens(fis []tok.FacetIndex, p *protos.Posting, tokens map[string]bool) error {
+	for _, fi := range fis {
+		for _, f := range p.Facets {
+			if f.Key != fi.Key {
+				continue
+			}
+			token, err := fi.Token(facets.ValFor(f))
+			if err != nil {
+				return err
+			}
+			tokens[token] = true
+		}
+	}
+	return nil
+}
+
+// facetIndexTokens returns the tokens of the facet indexes for the facets of
+// all the edges in the list. A node has a token as long as one of its edges
+// has the facet value.
+func (l *List) facetIndexTokens(fis []tok.FacetIndex) ([]string, error) {
+	if len(fis) == 0 {
+		return nil, nil
+	}
+	tokens := make(map[string]bool)
+	var err error
+	l.Iterate(0, func(p *protos.Posting) bool {
+		err = addFacetTokens(fis, p, tokens)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokenList(tokens), nil
+}
+
+// edgeFacetTokens returns the tokens of the facet indexes for the facets of the
+// edge to uid in the list, if there is one.
+func (l *List) edgeFacetTokens(fis []tok.FacetIndex, uid uint64) ([]string, error) {
+	if len(fis) == 0 || uid == 0 {
+		return nil, nil
+	}
+	l.RLock()
+	found, p := l.findPosting(uid)
+	l.RUnlock()
+	if !found {
+		return nil, nil
+	}
+	tokens := make(map[string]bool)
+	if err := addFacetTokens(fis, p, tokens); err != nil {
+		return nil, err
+	}
+	return tokenList(tokens), nil
+}
+
+// heldFacetTokens returns the tokens among the given ones which the facets of
+// the edges in the list still have.
+func (l *List) heldFacetTokens(fis []tok.FacetIndex, tokens map[string]bool) ([]string, error) {
+	held := make(map[string]bool)
+	var err error
+	l.Iterate(0, func(p *protos.Posting) bool {
+		ptokens := make(map[string]bool)
+		if err = addFacetTokens(fis, p, ptokens); err != nil {
+			return false
+		}
+		for token := range ptokens {
+			if tokens[token] {
+				held[token] = true
+			}
+		}
+		return len(held) < len(tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokenList(held), nil
+}
+
+func tokenList(tokens map[string]bool) []string {
+	out := make([]string, 0, len(tokens))
+	for token := range tokens {
+		out = append(out, token)
+	}
+	return out
+}
+
+// updateFacetIndexes updates the facet indexes of the list for the mutation of
+// the edge t, which had the tokens before. Only the tokens of the edge change,
+// except that a token it no longer has is kept if another edge still has it.
+// When all the edges are deleted, before has the tokens of all of them.
+func (l *List) updateFacetIndexes(ctx context.Context, fis []tok.FacetIndex,
+	t *protos.DirectedEdge, before []string) error {
+	if len(fis) == 0 {
+		return nil
+	}
+	var after []string
+	if t.Op != protos.DirectedEdge_DEL || string(t.Value) != x.Star {
+		var err error
+		if after, err = l.edgeFacetTokens(fis, t.ValueId); err != nil {
+			return err
+		}
+	}
+	has := make(map[string]bool, len(after))
+	for _, token := range after {
+		has[token] = true
+	}
+	lost := make(map[string]bool)
+	for _, token := range before {
+		if !has[token] {
+			lost[token] = true
+		}
+	}
+	if len(lost) > 0 {
+		held, err := l.heldFacetTokens(fis, lost)
+		if err != nil {
+			return err
+		}
+		after = append(after, held...)
+	}
+	return updateIndexTokens(ctx, t.Attr, t.Entity, before, after)
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
 	"golang.org/x/net/trace"
@@ -34,6 +35,7 @@ import (
 	"github.com/dgraph-io/dgraph/schema"
 	"github.com/dgraph-io/dgraph/tok"
 	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -58,12 +60,9 @@ func indexTokens(attr, lang string, src types.Val) ([]string, error) {
 	var tokens []string
 	tokenizers := schema.State().Tokenizer(attr)
 	for _, it := range tokenizers {
-		if tok.FtsTokenizerName("") == it.Name() && len(lang) > 0 {
-			newTokenizer, ok := tok.GetTokenizer(tok.FtsTokenizerName(lang))
-			if ok {
-				it = newTokenizer
-			} else {
-				return nil, x.Errorf("Tokenizer not available for language: %s", lang)
+		if ft, ok := it.(tok.FullTextTokenizer); ok && len(lang) > 0 {
+			if it, err = ft.ForLang(lang); err != nil {
+				return nil, err
 			}
 		}
 		toks, err := it.Tokens(sv)
@@ -91,6 +90,11 @@ func addIndexMutations(ctx context.Context, t *protos.DirectedEdge, p types.Val,
 		return err
 	}
 
+	positions, length, err := fullTextStats(attr, t.GetLang(), p)
+	if err != nil {
+		return err
+	}
+
 	// Create a value token -> uid edge.
 	edge := &protos.DirectedEdge{
 		ValueId: uid,
@@ -99,13 +103,91 @@ func addIndexMutations(ctx context.Context, t *protos.DirectedEdge, p types.Val,
 	}
 
 	for _, token := range tokens {
-		if err := addIndexMutation(ctx, edge, token); err != nil {
+		e := edge
+		if pos, ok := positions[token]; ok && op == protos.DirectedEdge_SET {
+			// Keep the statistics needed for relevance scoring and the positions
+			// needed for phrase search on the postings of the full text index.
+			if e, err = statsEdge(edge, length, pos); err != nil {
+				return err
+			}
+		}
+		if err := addIndexMutation(ctx, e, token); err != nil {
 			return err
 		}
 	}
+	if positions == nil {
+		return nil
+	}
+	// Update the statistics of the whole index once the value is indexed.
+	if op == protos.DirectedEdge_DEL {
+		updateTextStats(attr, -1, -int64(length))
+	} else {
+		updateTextStats(attr, 1, int64(length))
+	}
 	return nil
 }
 
+// postingEdge returns the edge of entity whose value is kept in the posting, with
+// the language of the value, so that the value is indexed as when it was set.
+func postingEdge(attr string, entity uint64, p *protos.Posting) *protos.DirectedEdge {
+	edge := &protos.DirectedEdge{Attr: attr, Entity: entity}
+	if p.PostingType == protos.Posting_VALUE_LANG {
+		edge.Lang = string(p.Metadata)
+	}
+	return edge
+}
+
+// fullTextStats returns the term positions and the number of terms of the
+// value, if attr has a full text index. Otherwise the positions are nil.
+func fullTextStats(attr, lang string, src types.Val) (map[string][]int, int, error) {
+	for _, it := range schema.State().Tokenizer(attr) {
+		ft, ok := it.(tok.FullTextTokenizer)
+		if !ok {
+			continue
+		}
+		if len(lang) > 0 {
+			var err error
+			if ft, err = ft.ForLang(lang); err != nil {
+				return nil, 0, err
+			}
+		}
+		sv, err := types.Convert(src, types.StringID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return tok.TermPositions(ft, sv)
+	}
+	return nil, 0, nil
+}
+
+// statsEdge returns a copy of the index edge with the length of the value as
+// the dl facet. The positions and frequency of the term in the value are kept
+// as the pos and tf facets.
+func statsEdge(edge *protos.DirectedEdge, length int,
+	positions []int) (*protos.DirectedEdge, error) {
+	e := *edge
+	dl, err := facets.FacetFor(tok.DocLengthFacet, strconv.Itoa(length))
+	if err != nil {
+		return nil, err
+	}
+	e.Facets = []*protos.Facet{dl}
+	if len(positions) > 0 {
+		tf, err := facets.FacetFor(tok.TermFreqFacet, strconv.Itoa(len(positions)))
+		if err != nil {
+			return nil, err
+		}
+		// The positions are set directly, as string facets built by FacetFor
+		// are tokenized for filtering.
+		pos := &protos.Facet{
+			Key:     tok.PositionsFacet,
+			Value:   tok.EncodePositions(positions),
+			ValType: protos.Facet_STRING,
+		}
+		e.Facets = append(e.Facets, pos, tf)
+	}
+	return &e, nil
+}
+
 func addIndexMutation(ctx context.Context, edge *protos.DirectedEdge,
 	token string) error {
 	key := x.IndexKey(edge.Attr, token)
@@ -210,13 +292,16 @@ func (l *List) handleDeleteAll(ctx context.Context, t *protos.DirectedEdge) erro
 				return false
 			}
 			return true
-		} else if isIndexed {
-			// Delete index edge of each posting.
+		} else if isIndexed && postingType(p) != x.ValueUid {
+			// Delete index edge of each posting. The facet indexes of the edges
+			// are updated along with the mutation.
+			edge := postingEdge(t.Attr, t.Entity, p)
+			edge.Op = t.Op
 			p := types.Val{
 				Tid:   types.TypeID(p.ValType),
 				Value: p.Value,
 			}
-			if err := addIndexMutations(ctx, t, p, protos.DirectedEdge_DEL); err != nil {
+			if err := addIndexMutations(ctx, edge, p, protos.DirectedEdge_DEL); err != nil {
 				iterErr = err
 				return false
 			}
@@ -303,8 +388,41 @@ func (l *List) AddMutationWithIndex(ctx context.Context, t *protos.DirectedEdge)
 	}
 	defer l.index.Unlock()
 
-	if t.Op == protos.DirectedEdge_DEL && string(t.Value) == x.Star {
-		return l.handleDeleteAll(ctx, t)
+	// Check the composite indexes BEFORE any mutation actually happens.
+	composites := compositeIndexes(t.Attr)
+	if len(composites) > 0 {
+		// The values of the other predicates of the composite indexes can't
+		// change until their tokens are updated.
+		mu := compositeLock(t.Entity)
+		mu.Lock()
+		defer mu.Unlock()
+	}
+	before, err := compositeIndexTokens(composites, t.Entity)
+	if err != nil {
+		return err
+	}
+	// The facet indexes have the facet values of all the edges of the node, of
+	// which only those of the edge change, unless all the edges are deleted.
+	fis := facetIndexes(t.Attr)
+	deleteAll := t.Op == protos.DirectedEdge_DEL && string(t.Value) == x.Star
+	var facetsBefore []string
+	if deleteAll {
+		facetsBefore, err = l.facetIndexTokens(fis)
+	} else {
+		facetsBefore, err = l.edgeFacetTokens(fis, t.ValueId)
+	}
+	if err != nil {
+		return err
+	}
+
+	if deleteAll {
+		if err := l.handleDeleteAll(ctx, t); err != nil {
+			return err
+		}
+		if err := l.updateFacetIndexes(ctx, fis, t, facetsBefore); err != nil {
+			return err
+		}
+		return updateCompositeIndexes(ctx, composites, t.Entity, before)
 	}
 
 	doUpdateIndex := pstore != nil && (t.Value != nil) && schema.State().IsIndexed(t.Attr)
@@ -321,6 +439,10 @@ func (l *List) AddMutationWithIndex(ctx context.Context, t *protos.DirectedEdge)
 			// Check original value BEFORE any mutation actually happens.
 			if len(t.Lang) > 0 {
 				val, found = l.findValue(farm.Fingerprint64([]byte(t.Lang)))
+			} else if schema.State().IsList(t.Attr) {
+				// A value of a list is only replaced by itself, and is removed
+				// from the index when it is deleted.
+				val, found = l.findValue(farm.Fingerprint64(t.Value))
 			} else {
 				val, found = l.findValue(math.MaxUint64)
 			}
@@ -377,7 +499,10 @@ func (l *List) AddMutationWithIndex(ctx context.Context, t *protos.DirectedEdge)
 			return err
 		}
 	}
-	return nil
+	if err := l.updateFacetIndexes(ctx, fis, t, facetsBefore); err != nil {
+		return err
+	}
+	return updateCompositeIndexes(ctx, composites, t.Entity, before)
 }
 
 func deleteEntries(prefix []byte) error {
@@ -648,7 +773,7 @@ func DeleteIndex(ctx context.Context, attr string) error {
 	if err := deleteEntries(prefix); err != nil {
 		return err
 	}
-	return nil
+	return resetTextStats(attr)
 }
 
 // RebuildIndex rebuilds index for a given attribute.
@@ -660,22 +785,37 @@ func RebuildIndex(ctx context.Context, attr string) error {
 	it := pstore.NewIterator(badger.DefaultIteratorOptions)
 	defer it.Close()
 
+	composites := declaredCompositeIndexes(attr)
+	fis := schema.State().FacetIndexes(attr)
+
 	// Helper function - Add index entries for values in posting list
 	addPostingsToIndex := func(uid uint64, pl *protos.PostingList) error {
+		if err := updateCompositeIndexes(ctx, composites, uid, nil); err != nil {
+			return err
+		}
+		if len(fis) > 0 {
+			tokens := make(map[string]bool)
+			for _, p := range pl.Postings {
+				if err := addFacetTokens(fis, p, tokens); err != nil {
+					return err
+				}
+			}
+			return updateIndexTokens(ctx, attr, uid, nil, tokenList(tokens))
+		}
 		postingsLen := len(pl.Postings)
-		edge := protos.DirectedEdge{Attr: attr, Entity: uid}
 		for idx := 0; idx < postingsLen; idx++ {
 			p := pl.Postings[idx]
+			edge := postingEdge(attr, uid, p)
 			// Add index entries based on p.
 			val := types.Val{
 				Value: p.Value,
 				Tid:   types.TypeID(p.ValType),
 			}
-			err := addIndexMutations(ctx, &edge, val, protos.DirectedEdge_SET)
+			err := addIndexMutations(ctx, edge, val, protos.DirectedEdge_SET)
 			// We retry once in case we do GetLru and stop the world happens
 			// before we do addmutation
 			if err == ErrRetry {
-				err = addIndexMutations(ctx, &edge, val, protos.DirectedEdge_SET)
+				err = addIndexMutations(ctx, edge, val, protos.DirectedEdge_SET)
 			}
 			if err != nil {
 				return err
@@ -719,8 +859,10 @@ func RebuildIndex(ctx context.Context, attr string) error {
 			return err
 		}
 
-		// Posting list contains only values or only UIDs.
-		if len(pl.Postings) != 0 && postingType(pl.Postings[0]) != x.ValueUid {
+		// Posting list contains only values or only UIDs, which are only indexed
+		// by the facet indexes.
+		if len(pl.Postings) != 0 &&
+			(postingType(pl.Postings[0]) != x.ValueUid || len(fis) > 0) {
 			ch <- item{
 				uid:  pki.Uid,
 				list: &pl,
@@ -770,5 +912,5 @@ func DeletePredicate(ctx context.Context, attr string) error {
 			return err
 		}
 	}
-	return nil
+	return rebuildCompositeIndexes(ctx, attr)
 }
@@ -20,6 +20,7 @@ package posting
 import (
 	"bytes"
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
 	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -110,6 +112,150 @@ func TestIndexingInvalidLang(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestTextStats(t *testing.T) {
+	schema.ParseBytes([]byte("bio:string @index(fulltext) ."), 1)
+	defer deletePl(t)
+
+	l1 := Get(x.DataKey("bio", 1))
+	addMutationWithIndex(t, l1, &protos.DirectedEdge{
+		Value: []byte("quick brown fox"), Attr: "bio", Entity: 1}, Set)
+	l2 := Get(x.DataKey("bio", 2))
+	addMutationWithIndex(t, l2, &protos.DirectedEdge{
+		Value: []byte("lazy dog"), Attr: "bio", Entity: 2}, Set)
+	count, length := TextStats("bio")
+	require.EqualValues(t, 2, count)
+	require.EqualValues(t, 5, length)
+
+	// Replacing a value removes the old one from the statistics.
+	addMutationWithIndex(t, l1, &protos.DirectedEdge{
+		Value: []byte("fox"), Attr: "bio", Entity: 1}, Set)
+	count, length = TextStats("bio")
+	require.EqualValues(t, 2, count)
+	require.EqualValues(t, 3, length)
+
+	addMutationWithIndex(t, l2, &protos.DirectedEdge{
+		Value: []byte("lazy dog"), Attr: "bio", Entity: 2}, Del)
+	count, length = TextStats("bio")
+	require.EqualValues(t, 1, count)
+	require.EqualValues(t, 1, length)
+
+	// Deleting all the values measures them with the analyzers of their languages.
+	l3 := Get(x.DataKey("bio", 3))
+	addMutationWithIndex(t, l3, &protos.DirectedEdge{
+		Value: []byte("the foxes are running"), Attr: "bio", Entity: 3, Lang: "en"}, Set)
+	count, _ = TextStats("bio")
+	require.EqualValues(t, 2, count)
+	addMutationWithIndex(t, l3, &protos.DirectedEdge{
+		Value: []byte(x.Star), Attr: "bio", Entity: 3}, Del)
+	count, length = TextStats("bio")
+	require.EqualValues(t, 1, count)
+	require.EqualValues(t, 1, length)
+
+	// The statistics are written under their own key and removed with the index.
+	CommitLists(10, 1)
+	require.Equal(t, [2]int64{1, 1}, func() [2]int64 {
+		c, l := readTextStats(ps, "bio")
+		return [2]int64{c, l}
+	}())
+	require.NoError(t, DeleteIndex(context.Background(), "bio"))
+	count, length = TextStats("bio")
+	require.Zero(t, count)
+	require.Zero(t, length)
+}
+
+func TestFacetIndexSharedToken(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte("rated: uid @index(facet(score)) ."), 1))
+	defer deletePl(t)
+
+	fis := schema.State().FacetIndexes("rated")
+	require.Len(t, fis, 1)
+	token, err := fis[0].Token(types.Val{Tid: types.IntID, Value: int64(5)})
+	require.NoError(t, err)
+	indexed := func() []uint64 {
+		return Get(x.IndexKey("rated", token)).Uids(ListOptions{}).Uids
+	}
+	score, err := facets.FacetFor("score", "5")
+	require.NoError(t, err)
+
+	l := Get(x.DataKey("rated", 1))
+	for _, dst := range []uint64{10, 11} {
+		addMutationWithIndex(t, l, &protos.DirectedEdge{
+			ValueId: dst, Attr: "rated", Entity: 1, Facets: []*protos.Facet{score}}, Set)
+	}
+	require.Equal(t, []uint64{1}, indexed())
+
+	// The node keeps the token as long as one of its edges has the value.
+	addMutationWithIndex(t, l, &protos.DirectedEdge{ValueId: 10, Attr: "rated", Entity: 1}, Del)
+	require.Equal(t, []uint64{1}, indexed())
+	addMutationWithIndex(t, l, &protos.DirectedEdge{ValueId: 11, Attr: "rated", Entity: 1}, Set)
+	require.Empty(t, indexed())
+}
+
+func TestTextStatsList(t *testing.T) {
+	schema.ParseBytes([]byte("tags:[string] @index(fulltext) ."), 1)
+	defer deletePl(t)
+
+	l := Get(x.DataKey("tags", 1))
+	for _, v := range []string{"quick brown fox", "lazy dog", "lazy dog"} {
+		addMutationWithIndex(t, l, &protos.DirectedEdge{
+			Value: []byte(v), Attr: "tags", Entity: 1}, Set)
+	}
+	count, length := TextStats("tags")
+	require.EqualValues(t, 2, count)
+	require.EqualValues(t, 5, length)
+
+	addMutationWithIndex(t, l, &protos.DirectedEdge{
+		Value: []byte("lazy dog"), Attr: "tags", Entity: 1}, Del)
+	count, length = TextStats("tags")
+	require.EqualValues(t, 1, count)
+	require.EqualValues(t, 3, length)
+}
+
+func TestCompositeIndexConcurrent(t *testing.T) {
+	require.NoError(t, schema.ParseBytes([]byte(`
+		country: string @index(exact, composite(status)) .
+		status: string @index(exact) .
+	`), 1))
+	defer deletePl(t)
+	ci := schema.State().CompositeIndexes("country")[0]
+
+	// The predicates of the composite index are set together for every uid.
+	var wg sync.WaitGroup
+	for uid := uint64(1); uid <= 100; uid++ {
+		for attr, vals := range map[string][]string{
+			"country": {"US", "IN"},
+			"status":  {"active", "inactive"},
+		} {
+			wg.Add(1)
+			go func(attr string, uid uint64, vals []string) {
+				defer wg.Done()
+				l := Get(x.DataKey(attr, uid))
+				for _, val := range vals {
+					addMutationWithIndex(t, l, &protos.DirectedEdge{
+						Value: []byte(val), Attr: attr, Entity: uid}, Set)
+				}
+			}(attr, uid, vals)
+		}
+	}
+	wg.Wait()
+
+	for _, country := range []string{"US", "IN"} {
+		for _, status := range []string{"active", "inactive"} {
+			tokens, err := ci.Tokens([][]types.Val{
+				{{Tid: types.StringID, Value: country}},
+				{{Tid: types.StringID, Value: status}},
+			})
+			require.NoError(t, err)
+			uids := Get(x.IndexKey("country", tokens[0])).Uids(ListOptions{}).Uids
+			if country == "IN" && status == "inactive" {
+				require.Len(t, uids, 100)
+			} else {
+				require.Empty(t, uids, "%s %s", country, status)
+			}
+		}
+	}
+}
+
 func addMutationWithIndex(t *testing.T, l *List, edge *protos.DirectedEdge, op uint32) {
 	if op == Del {
 		edge.Op = protos.DirectedEdge_DEL
@@ -790,6 +790,10 @@ func (l *List) Postings(opt ListOptions, postFn func(*protos.Posting) bool) {
 	l.RLock()
 	defer l.RUnlock()
 
+	if opt.Intersect != nil {
+		l.intersectPostings(opt, postFn)
+		return
+	}
 	l.iterate(opt.AfterUID, func(p *protos.Posting) bool {
 		if postingType(p) != x.ValueUid {
 			return true
@@ -798,6 +802,47 @@ func (l *List) Postings(opt ListOptions, postFn func(*protos.Posting) bool) {
 	})
 }
 
+// intersectPostings calls postFn with the postings of the uids in
+// opt.Intersect. When there are fewer of them than postings, each is sought
+// so that the other postings aren't visited.
+func (l *List) intersectPostings(opt ListOptions, postFn func(*protos.Posting) bool) {
+	l.AssertRLock()
+	uids := opt.Intersect.Uids
+	uids = uids[sort.Search(len(uids), func(i int) bool { return uids[i] > opt.AfterUID }):]
+	if len(uids) == 0 {
+		return
+	}
+
+	if len(uids) >= l.length(opt.AfterUID) {
+		l.iterate(opt.AfterUID, func(p *protos.Posting) bool {
+			for len(uids) > 0 && uids[0] < p.Uid {
+				uids = uids[1:]
+			}
+			if len(uids) == 0 {
+				return false
+			}
+			if uids[0] != p.Uid || postingType(p) != x.ValueUid {
+				return true
+			}
+			return postFn(p)
+		})
+		return
+	}
+
+	for _, uid := range uids {
+		cont := true
+		l.iterate(uid-1, func(p *protos.Posting) bool {
+			if p.Uid == uid && postingType(p) == x.ValueUid {
+				cont = postFn(p)
+			}
+			return false
+		})
+		if !cont {
+			return
+		}
+	}
+}
+
 func (l *List) AllValues() (vals []types.Val, rerr error) {
 	l.RLock()
 	defer l.RUnlock()
@@ -581,6 +581,47 @@ func TestDelete(t *testing.T) {
 	require.EqualValues(t, 0, ol.Length(0))
 }
 
+func TestPostingsIntersect(t *testing.T) {
+	key := x.DataKey("value", 10)
+	ol := getNew(key, ps)
+
+	edge := &protos.DirectedEdge{
+		Label: "jchiu",
+	}
+	for i := 1; i <= 30; i++ {
+		edge.ValueId = uint64(i)
+		addMutation(t, ol, edge, Set)
+	}
+	commited, err := ol.SyncIfDirty(false)
+	require.NoError(t, err)
+	require.True(t, commited)
+	edge.ValueId = 40
+	addMutation(t, ol, edge, Set)
+	edge.ValueId = 5
+	addMutation(t, ol, edge, Del)
+
+	postings := func(opt ListOptions) []uint64 {
+		var out []uint64
+		ol.Postings(opt, func(p *protos.Posting) bool {
+			out = append(out, p.Uid)
+			return true
+		})
+		return out
+	}
+	// Few uids are sought, many are merged with the postings.
+	few := &protos.List{Uids: []uint64{3, 5, 35, 40}}
+	require.Equal(t, []uint64{3, 40}, postings(ListOptions{Intersect: few}))
+	require.Equal(t, []uint64{40}, postings(ListOptions{AfterUID: 3, Intersect: few}))
+	many := &protos.List{}
+	for i := 2; i <= 50; i += 2 {
+		many.Uids = append(many.Uids, uint64(i))
+	}
+	require.Equal(t, []uint64{2, 4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30, 40},
+		postings(ListOptions{Intersect: many}))
+	deletePl(t)
+	ps.Delete(ol.key)
+}
+
 func TestAfterUIDCountWithCommit(t *testing.T) {
 	key := x.DataKey("value", 10)
 	ol := getNew(key, ps)
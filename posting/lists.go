@@ -200,6 +200,7 @@ func periodicCommit() {
 
 			fraction := math.Min(1.0, Config.CommitFraction*math.Exp(float64(dsize)/1000000.0))
 			gentleCommit(dirtyMap, pending, fraction)
+			commitTextStats()
 
 			stats := lcache.Stats()
 			x.EvictedPls.Set(int64(stats.NumEvicts))
@@ -257,6 +258,7 @@ func Init(ps *badger.KV) {
 	marks.Init()
 
 	pstore = ps
+	initTextStats(ps)
 	lcache = newListCache(math.MaxUint64)
 	x.LcacheCapacity.Set(math.MaxInt64)
 	dirtyChan = make(chan []byte, 10000)
@@ -374,6 +376,7 @@ func CommitLists(numRoutines int, gid uint32) {
 	})
 	close(workChan)
 	wg.Wait()
+	commitTextStats()
 }
 
 // This doesn't sync, so call this only when you don't care about dirty posting lists in
@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package posting
+
+import (
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// textStats are the statistics of the full text index of a predicate, which
+// BM25 uses as the statistics of the corpus: the number of values and the sum
+// of their lengths.
+type textStats struct {
+	count  int64
+	length int64
+	dirty  int32
+}
+
+// textStatsMap keeps the statistics of the full text indexes in memory. The
+// mutations only add to them there, so that indexing doesn't contend on a
+// single key, and commitTextStats writes them under the stats keys of the
+// predicates periodically. Mutations applied again after a crash may be
+// counted twice, which only makes the scores slightly off.
+var textStatsMap = struct {
+	sync.RWMutex
+	m map[string]*textStats
+}{m: make(map[string]*textStats)}
+
+// textStatsCommit serializes writing the statistics and resetting them.
+var textStatsCommit sync.Mutex
+
+// textStatsStore is the store the statistics are read from and written to.
+// It is nil once the store is about to be closed, so that the periodic commit
+// doesn't write to it anymore.
+var textStatsStore *badger.KV
+
+// initTextStats drops the statistics of the previous store and keeps those of
+// ps from now on.
+func initTextStats(ps *badger.KV) {
+	textStatsCommit.Lock()
+	defer textStatsCommit.Unlock()
+
+	textStatsMap.Lock()
+	textStatsMap.m = make(map[string]*textStats)
+	textStatsMap.Unlock()
+	textStatsStore = ps
+}
+
+// Cleanup writes the statistics kept in memory and stops writing them. It must
+// be called before closing the store given to Init.
+func Cleanup() {
+	commitTextStats()
+	textStatsCommit.Lock()
+	defer textStatsCommit.Unlock()
+	textStatsStore = nil
+}
+
+// getTextStats returns the statistics of attr, which are read from the store
+// the first time.
+func getTextStats(attr string) *textStats {
+	textStatsMap.RLock()
+	s, ok := textStatsMap.m[attr]
+	textStatsMap.RUnlock()
+	if ok {
+		return s
+	}
+
+	textStatsCommit.Lock()
+	ps := textStatsStore
+	textStatsCommit.Unlock()
+	var count, length int64
+	if ps != nil {
+		count, length = readTextStats(ps, attr)
+	}
+
+	textStatsMap.Lock()
+	defer textStatsMap.Unlock()
+	if s, ok := textStatsMap.m[attr]; ok {
+		return s
+	}
+	s = &textStats{count: count, length: length}
+	textStatsMap.m[attr] = s
+	return s
+}
+
+func readTextStats(ps *badger.KV, attr string) (count, length int64) {
+	var item badger.KVItem
+	if err := ps.Get(x.StatsKey(attr), &item); err != nil {
+		return 0, 0
+	}
+	var pl protos.PostingList
+	err := item.Value(func(val []byte) error {
+		return pl.Unmarshal(val)
+	})
+	if err != nil || len(pl.Postings) == 0 {
+		return 0, 0
+	}
+	return decodeTextStats(pl.Postings[0].Value)
+}
+
+func decodeTextStats(b []byte) (count, length int64) {
+	if len(b) != 16 {
+		return 0, 0
+	}
+	return int64(binary.BigEndian.Uint64(b)), int64(binary.BigEndian.Uint64(b[8:]))
+}
+
+// TextStats returns the number of values in the full text index of attr and
+// the sum of their lengths, which BM25 uses as the statistics of the corpus.
+func TextStats(attr string) (count, length int64) {
+	s := getTextStats(attr)
+	return atomic.LoadInt64(&s.count), atomic.LoadInt64(&s.length)
+}
+
+// updateTextStats adds count values whose lengths sum to length to the
+// statistics of the full text index of attr. Both are negative when values
+// are removed.
+func updateTextStats(attr string, count, length int64) {
+	s := getTextStats(attr)
+	atomic.AddInt64(&s.count, count)
+	atomic.AddInt64(&s.length, length)
+	atomic.StoreInt32(&s.dirty, 1)
+}
+
+// commitTextStats writes the statistics which changed since they were last
+// written. The stats key holds a posting list, like the other keys of the
+// predicate, with the statistics as the value of its single posting.
+func commitTextStats() {
+	textStatsCommit.Lock()
+	defer textStatsCommit.Unlock()
+	if textStatsStore == nil {
+		return
+	}
+
+	dirty := make(map[string]*textStats)
+	textStatsMap.RLock()
+	for attr, s := range textStatsMap.m {
+		if atomic.CompareAndSwapInt32(&s.dirty, 1, 0) {
+			dirty[attr] = s
+		}
+	}
+	textStatsMap.RUnlock()
+
+	for attr, s := range dirty {
+		b := make([]byte, 16)
+		binary.BigEndian.PutUint64(b, uint64(atomic.LoadInt64(&s.count)))
+		binary.BigEndian.PutUint64(b[8:], uint64(atomic.LoadInt64(&s.length)))
+		pl := protos.PostingList{Postings: []*protos.Posting{{
+			Value:   b,
+			ValType: protos.Posting_ValType(types.BinaryID),
+		}}}
+		data, err := pl.Marshal()
+		x.Check(err)
+		if err := textStatsStore.Set(x.StatsKey(attr), data, 0x00); err != nil {
+			x.Printf("Error while writing statistics of %s: %v\n", attr, err)
+			atomic.StoreInt32(&s.dirty, 1)
+		}
+	}
+}
+
+// resetTextStats removes the statistics of attr, along with its index.
+func resetTextStats(attr string) error {
+	textStatsCommit.Lock()
+	defer textStatsCommit.Unlock()
+
+	textStatsMap.Lock()
+	delete(textStatsMap.m, attr)
+	textStatsMap.Unlock()
+	if textStatsStore == nil {
+		return nil
+	}
+	return textStatsStore.Delete(x.StatsKey(attr))
+}
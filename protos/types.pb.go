@@ -18,41 +18,44 @@ var _ = math.Inf
 type Posting_ValType int32
 
 const (
-	Posting_DEFAULT  Posting_ValType = 0
-	Posting_BINARY   Posting_ValType = 1
-	Posting_INT      Posting_ValType = 2
-	Posting_FLOAT    Posting_ValType = 3
-	Posting_BOOL     Posting_ValType = 4
-	Posting_DATETIME Posting_ValType = 5
-	Posting_GEO      Posting_ValType = 6
-	Posting_UID      Posting_ValType = 7
-	Posting_PASSWORD Posting_ValType = 8
-	Posting_STRING   Posting_ValType = 9
+	Posting_DEFAULT       Posting_ValType = 0
+	Posting_BINARY        Posting_ValType = 1
+	Posting_INT           Posting_ValType = 2
+	Posting_FLOAT         Posting_ValType = 3
+	Posting_BOOL          Posting_ValType = 4
+	Posting_DATETIME      Posting_ValType = 5
+	Posting_GEO           Posting_ValType = 6
+	Posting_UID           Posting_ValType = 7
+	Posting_PASSWORD      Posting_ValType = 8
+	Posting_STRING        Posting_ValType = 9
+	Posting_FLOAT32VECTOR Posting_ValType = 10
 )
 
 var Posting_ValType_name = map[int32]string{
-	0: "DEFAULT",
-	1: "BINARY",
-	2: "INT",
-	3: "FLOAT",
-	4: "BOOL",
-	5: "DATETIME",
-	6: "GEO",
-	7: "UID",
-	8: "PASSWORD",
-	9: "STRING",
+	0:  "DEFAULT",
+	1:  "BINARY",
+	2:  "INT",
+	3:  "FLOAT",
+	4:  "BOOL",
+	5:  "DATETIME",
+	6:  "GEO",
+	7:  "UID",
+	8:  "PASSWORD",
+	9:  "STRING",
+	10: "FLOAT32VECTOR",
 }
 var Posting_ValType_value = map[string]int32{
-	"DEFAULT":  0,
-	"BINARY":   1,
-	"INT":      2,
-	"FLOAT":    3,
-	"BOOL":     4,
-	"DATETIME": 5,
-	"GEO":      6,
-	"UID":      7,
-	"PASSWORD": 8,
-	"STRING":   9,
+	"DEFAULT":       0,
+	"BINARY":        1,
+	"INT":           2,
+	"FLOAT":         3,
+	"BOOL":          4,
+	"DATETIME":      5,
+	"GEO":           6,
+	"UID":           7,
+	"PASSWORD":      8,
+	"STRING":        9,
+	"FLOAT32VECTOR": 10,
 }
 
 func (x Posting_ValType) String() string {
@@ -968,33 +971,34 @@ var (
 func init() { proto.RegisterFile("types.proto", fileDescriptorTypes) }
 
 var fileDescriptorTypes = []byte{
-	// 448 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x5c, 0x52, 0xd1, 0x8a, 0xd3, 0x40,
-	0x14, 0xed, 0x24, 0x69, 0x92, 0xde, 0x64, 0xeb, 0x70, 0x11, 0x1d, 0x56, 0x28, 0xa1, 0x20, 0x04,
-	0x84, 0x82, 0xf5, 0x5d, 0x48, 0x69, 0x5a, 0x02, 0xb1, 0x59, 0xa6, 0xe9, 0x8a, 0x4f, 0x4b, 0xb6,
-	0x8d, 0x1a, 0x4c, 0x4d, 0x30, 0x69, 0x61, 0x7d, 0xf4, 0x2b, 0xfc, 0x24, 0x1f, 0x7d, 0xf1, 0x5d,
-	0xea, 0x8f, 0xc8, 0x24, 0x63, 0xdc, 0xed, 0xd3, 0x9c, 0x33, 0x77, 0xce, 0xc9, 0xbd, 0xe7, 0x06,
-	0xac, 0xfa, 0xae, 0x4c, 0xab, 0x49, 0xf9, 0xa5, 0xa8, 0x0b, 0xd4, 0x9b, 0xa3, 0xba, 0xb4, 0xdf,
-	0x27, 0xdb, 0xb4, 0x96, 0xb7, 0xe3, 0x5f, 0x2a, 0x18, 0x57, 0x45, 0x55, 0x67, 0x9f, 0x3f, 0x20,
-	0x05, 0xf5, 0x90, 0xed, 0x18, 0x71, 0x88, 0xab, 0x73, 0x01, 0xf1, 0x31, 0xf4, 0x8f, 0x49, 0x7e,
-	0x48, 0x99, 0xe2, 0x10, 0xd7, 0xe6, 0x2d, 0xc1, 0x29, 0x98, 0xc7, 0x24, 0xbf, 0x11, 0xe6, 0x4c,
-	0x75, 0x88, 0x3b, 0x9c, 0x3e, 0x6d, 0xdd, 0xaa, 0x89, 0xb4, 0x9a, 0x5c, 0x27, 0x79, 0x7c, 0x57,
-	0xa6, 0xdc, 0x38, 0xb6, 0x00, 0x5f, 0x83, 0x5d, 0xb6, 0xb5, 0x56, 0xa7, 0x35, 0xba, 0x67, 0xe7,
-	0x3a, 0x79, 0x36, 0x5a, 0xab, 0xfc, 0x4f, 0xf0, 0x12, 0xcc, 0x7d, 0x5a, 0x27, 0xbb, 0xa4, 0x4e,
-	0x58, 0xbf, 0x69, 0xa6, 0xe3, 0xa2, 0xcb, 0x3c, 0xb9, 0x4d, 0x73, 0xa6, 0x3b, 0xc4, 0x1d, 0xf0,
-	0x96, 0xe0, 0x13, 0xd0, 0xb7, 0xc5, 0x7e, 0x9f, 0xd5, 0xcc, 0x70, 0x88, 0xab, 0x71, 0xc9, 0xf0,
-	0x39, 0xe8, 0x6d, 0x02, 0xcc, 0x74, 0x54, 0xd7, 0x9a, 0x5e, 0xfc, 0xeb, 0x61, 0x21, 0x6e, 0xb9,
-	0x2c, 0xe2, 0x10, 0x94, 0xa2, 0x64, 0xb6, 0x43, 0xdc, 0x0b, 0xae, 0x14, 0xe5, 0xf8, 0x2b, 0x18,
-	0x72, 0x28, 0xb4, 0xc0, 0x98, 0xfb, 0x0b, 0x6f, 0x13, 0xc6, 0xb4, 0x87, 0x00, 0xfa, 0x2c, 0x58,
-	0x79, 0xfc, 0x1d, 0x25, 0x68, 0x80, 0x1a, 0xac, 0x62, 0xaa, 0xe0, 0x00, 0xfa, 0x8b, 0x30, 0xf2,
-	0x62, 0xaa, 0xa2, 0x09, 0xda, 0x2c, 0x8a, 0x42, 0xaa, 0xa1, 0x0d, 0xe6, 0xdc, 0x8b, 0xfd, 0x38,
-	0x78, 0xe3, 0xd3, 0xbe, 0x78, 0xbb, 0xf4, 0x23, 0xaa, 0x0b, 0xb0, 0x09, 0xe6, 0xd4, 0x10, 0xf5,
-	0x2b, 0x6f, 0xbd, 0x7e, 0x1b, 0xf1, 0x39, 0x35, 0x85, 0xef, 0x3a, 0xe6, 0xc1, 0x6a, 0x49, 0x07,
-	0xe3, 0x97, 0x60, 0xdd, 0x0b, 0x46, 0x28, 0xb8, 0xbf, 0xa0, 0x3d, 0xf1, 0x99, 0x6b, 0x2f, 0xdc,
-	0xf8, 0x94, 0xe0, 0x10, 0xa0, 0x81, 0x37, 0xa1, 0xb7, 0x5a, 0x52, 0x65, 0xfc, 0x8d, 0x74, 0x9a,
-	0x30, 0xab, 0x6a, 0x7c, 0x01, 0xa6, 0x8c, 0xb3, 0x62, 0xa4, 0x99, 0xfb, 0xd1, 0x59, 0xf6, 0xbc,
-	0x7b, 0x20, 0xc2, 0xde, 0x7e, 0x4c, 0xb7, 0x9f, 0xaa, 0xc3, 0x5e, 0x6e, 0xbe, 0xe3, 0xf7, 0x62,
-	0x55, 0x1f, 0xc4, 0x8a, 0xa0, 0x1d, 0xb2, 0x5d, 0xd5, 0x2c, 0xd6, 0xe6, 0x0d, 0x9e, 0xd1, 0x1f,
-	0xa7, 0x11, 0xf9, 0x79, 0x1a, 0x91, 0xdf, 0xa7, 0x11, 0xf9, 0xfe, 0x67, 0xd4, 0xbb, 0x6d, 0x7f,
-	0xc2, 0x57, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x0e, 0xdf, 0x9b, 0x65, 0x9a, 0x02, 0x00, 0x00,
+	// 449 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5d, 0x52, 0x4d, 0x4b, 0xc3, 0x40,
+	0x10, 0x6d, 0x9a, 0x34, 0x49, 0x27, 0x69, 0x5d, 0x07, 0xd1, 0xa0, 0x50, 0x4a, 0x41, 0x10, 0x84,
+	0x82, 0xf5, 0x2e, 0xa4, 0x36, 0x2d, 0x81, 0xd8, 0x94, 0x6d, 0x5a, 0xf1, 0x54, 0x62, 0x8d, 0x5a,
+	0x6c, 0x4d, 0x30, 0xa9, 0xe0, 0xd5, 0xbb, 0x77, 0x7f, 0x92, 0x47, 0x7f, 0x82, 0xe8, 0xc9, 0x7f,
+	0xe1, 0x6e, 0x12, 0xeb, 0xc7, 0x61, 0xd9, 0xf7, 0x66, 0xf6, 0xbd, 0x4c, 0x66, 0x06, 0xb4, 0xe4,
+	0x21, 0x0a, 0xe2, 0x66, 0x74, 0x17, 0x26, 0x21, 0xca, 0xe9, 0x15, 0x6f, 0xeb, 0x97, 0xfe, 0x34,
+	0x48, 0xf2, 0x68, 0xe3, 0x53, 0x04, 0x65, 0x10, 0xc6, 0xc9, 0xec, 0xf6, 0x0a, 0x09, 0x88, 0xcb,
+	0xd9, 0x85, 0x21, 0xd4, 0x85, 0x3d, 0x99, 0x72, 0x88, 0x1b, 0x50, 0xba, 0xf7, 0xe7, 0xcb, 0xc0,
+	0x28, 0xb2, 0x98, 0x4e, 0x33, 0x82, 0x2d, 0x50, 0x19, 0x98, 0x70, 0x73, 0x43, 0x64, 0x89, 0x6a,
+	0x6b, 0x2b, 0x73, 0x8b, 0x9b, 0xb9, 0x55, 0x73, 0xec, 0xcf, 0x3d, 0x96, 0xa6, 0xca, 0x7d, 0x06,
+	0xf0, 0x08, 0xf4, 0x28, 0xcb, 0x65, 0x3a, 0x29, 0xd5, 0xed, 0xfc, 0xd7, 0xe5, 0x77, 0xaa, 0xd5,
+	0xa2, 0x1f, 0x82, 0xdb, 0xa0, 0x2e, 0x82, 0xc4, 0xbf, 0xf0, 0x13, 0xdf, 0x28, 0xa5, 0xc5, 0xac,
+	0x38, 0xaf, 0x72, 0xee, 0x9f, 0x07, 0x73, 0x43, 0x66, 0x89, 0x32, 0xcd, 0x08, 0x6e, 0x82, 0x3c,
+	0x0d, 0x17, 0x8b, 0x59, 0x62, 0x28, 0x2c, 0x2c, 0xd1, 0x9c, 0xe1, 0x2e, 0xc8, 0x59, 0x07, 0x0c,
+	0xb5, 0x2e, 0xee, 0x69, 0xad, 0xca, 0x77, 0x0d, 0x5d, 0x1e, 0xa5, 0x79, 0x12, 0xab, 0x50, 0x0c,
+	0x23, 0x43, 0x67, 0xd2, 0x0a, 0x65, 0xa8, 0xf1, 0x24, 0x80, 0x92, 0xff, 0x15, 0x6a, 0xa0, 0x74,
+	0xac, 0xae, 0x39, 0x72, 0x3c, 0x52, 0x40, 0x00, 0xb9, 0x6d, 0xf7, 0x4d, 0x7a, 0x46, 0x04, 0x54,
+	0x40, 0xb4, 0xfb, 0x1e, 0x29, 0x62, 0x19, 0x4a, 0x5d, 0xc7, 0x35, 0x3d, 0x22, 0xa2, 0x0a, 0x52,
+	0xdb, 0x75, 0x1d, 0x22, 0xa1, 0x0e, 0x6a, 0xc7, 0xf4, 0x2c, 0xcf, 0x3e, 0xb1, 0x48, 0x89, 0xbf,
+	0xed, 0x59, 0x2e, 0x91, 0x39, 0x18, 0xd9, 0x1d, 0xa2, 0xf0, 0xfc, 0xc0, 0x1c, 0x0e, 0x4f, 0x5d,
+	0xda, 0x21, 0x2a, 0xf7, 0x1d, 0x7a, 0xd4, 0xee, 0xf7, 0x48, 0x19, 0xd7, 0xa1, 0x92, 0xda, 0x1d,
+	0xb6, 0xc6, 0xd6, 0xb1, 0xe7, 0x52, 0x02, 0x8d, 0x03, 0xd0, 0x7e, 0x35, 0x8b, 0x9b, 0x50, 0xab,
+	0xcb, 0xca, 0x61, 0x5f, 0x1e, 0x9b, 0xce, 0xc8, 0x62, 0xd5, 0x54, 0x01, 0x52, 0x38, 0x71, 0x4c,
+	0xe6, 0x52, 0x6c, 0x3c, 0x0a, 0x2b, 0x8d, 0x33, 0x8b, 0x13, 0xdc, 0x07, 0x35, 0x6f, 0x71, 0xcc,
+	0x86, 0xce, 0x7b, 0xb1, 0xf6, 0x6f, 0x1e, 0x74, 0xf5, 0x80, 0x0f, 0x60, 0x7a, 0x1d, 0x4c, 0x6f,
+	0xe2, 0xe5, 0x22, 0xdf, 0x86, 0x15, 0xff, 0xd5, 0x6a, 0xf1, 0x4f, 0xab, 0x11, 0x24, 0xb6, 0x45,
+	0x71, 0x3a, 0x6c, 0x9d, 0xa6, 0xb8, 0x4d, 0x5e, 0xde, 0x6b, 0xc2, 0x2b, 0x3b, 0x6f, 0xec, 0x3c,
+	0x7f, 0xd4, 0x0a, 0xe7, 0xd9, 0x62, 0x1e, 0x7e, 0x01, 0xbf, 0x8f, 0x32, 0x17, 0xae, 0x02, 0x00,
+	0x00,
 }
@@ -20,6 +20,8 @@ package query
 import (
 	"bytes"
 	"math"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/dgraph-io/dgraph/protos"
@@ -31,6 +33,42 @@ type aggregator struct {
 	name   string
 	result types.Val
 	count  int // used when we need avergae.
+
+	// Used by the aggregators which need all the values before they can
+	// compute a result.
+	param    float64 // the percentage for percentile.
+	nums     []float64
+	distinct map[string]struct{}
+	hll      *hyperLogLog
+}
+
+// newAggregator returns an aggregator for the aggregate function f.
+func newAggregator(f *Function) (*aggregator, error) {
+	ag := &aggregator{name: f.Name}
+	if f.Name == "percentile" {
+		if len(f.Args) != 1 {
+			return nil, x.Errorf("percentile expects a percentage as its second argument")
+		}
+		p, err := strconv.ParseFloat(f.Args[0].Value, 64)
+		if err != nil {
+			return nil, x.Wrapf(err, "Invalid percentile %q", f.Args[0].Value)
+		}
+		if !(p >= 0 && p <= 100) {
+			return nil, x.Errorf("Invalid percentile %q. Expected a number between 0 and 100",
+				f.Args[0].Value)
+		}
+		ag.param = p
+	}
+	return ag, nil
+}
+
+func isCollectingAggregator(f string) bool {
+	switch f {
+	case "median", "percentile", "stddev", "variance", "count_distinct",
+		"approx_count_distinct":
+		return true
+	}
+	return false
 }
 
 func isUnary(f string) bool {
@@ -255,6 +293,10 @@ func (ag *aggregator) ApplyVal(v types.Val) error {
 }
 
 func (ag *aggregator) Apply(val types.Val) {
+	if isCollectingAggregator(ag.name) {
+		ag.collect(val)
+		return
+	}
 	if ag.result.Value == nil {
 		ag.result = val
 		ag.count++
@@ -295,8 +337,122 @@ func (ag *aggregator) Apply(val types.Val) {
 	ag.result = res
 }
 
+// collect keeps what is needed from val to compute the result of a collecting
+// aggregator later on. Values which can't be used are skipped.
+func (ag *aggregator) collect(val types.Val) {
+	switch ag.name {
+	case "median", "percentile", "stddev", "variance":
+		switch val.Tid {
+		case types.IntID:
+			ag.nums = append(ag.nums, float64(val.Value.(int64)))
+		case types.FloatID:
+			ag.nums = append(ag.nums, val.Value.(float64))
+		default:
+			return
+		}
+	case "count_distinct":
+		key, ok := distinctKey(val)
+		if !ok {
+			return
+		}
+		if ag.distinct == nil {
+			ag.distinct = make(map[string]struct{})
+		}
+		ag.distinct[key] = struct{}{}
+	case "approx_count_distinct":
+		key, ok := distinctKey(val)
+		if !ok {
+			return
+		}
+		if ag.hll == nil {
+			ag.hll = newHyperLogLog()
+		}
+		ag.hll.Add([]byte(key))
+	}
+	ag.count++
+}
+
+func distinctKey(val types.Val) (string, bool) {
+	if val.Tid == types.UidID {
+		return strconv.FormatUint(val.Value.(uint64), 10), true
+	}
+	data := types.ValueForType(types.StringID)
+	if err := types.Marshal(val, &data); err != nil {
+		return "", false
+	}
+	return data.Value.(string), true
+}
+
+// summarize sets the result of a collecting aggregator. The result is left
+// empty if no numbers were seen by median, percentile, stddev or variance.
+func (ag *aggregator) summarize() {
+	switch ag.name {
+	case "median":
+		if len(ag.nums) > 0 {
+			ag.result = types.Val{Tid: types.FloatID, Value: percentile(ag.nums, 50)}
+		}
+	case "percentile":
+		if len(ag.nums) > 0 {
+			ag.result = types.Val{Tid: types.FloatID, Value: percentile(ag.nums, ag.param)}
+		}
+	case "stddev", "variance":
+		if len(ag.nums) == 0 {
+			return
+		}
+		var mean float64
+		for _, n := range ag.nums {
+			mean += n
+		}
+		mean /= float64(len(ag.nums))
+		// This is the population variance, all the values being known.
+		var v float64
+		for _, n := range ag.nums {
+			v += (n - mean) * (n - mean)
+		}
+		v /= float64(len(ag.nums))
+		if ag.name == "stddev" {
+			v = math.Sqrt(v)
+		}
+		ag.result = types.Val{Tid: types.FloatID, Value: v}
+	case "count_distinct":
+		ag.result = types.Val{Tid: types.IntID, Value: int64(len(ag.distinct))}
+	case "approx_count_distinct":
+		var c int64
+		if ag.hll != nil {
+			c = int64(ag.hll.Count())
+		}
+		ag.result = types.Val{Tid: types.IntID, Value: c}
+	}
+}
+
+// percentile returns the p-th percentile of nums, interpolating linearly
+// between the two closest ranks. nums is sorted in place.
+func percentile(nums []float64, p float64) float64 {
+	sort.Float64s(nums)
+	rank := p / 100 * float64(len(nums)-1)
+	last := len(nums) - 1
+	lo := clampIndex(math.Floor(rank), last)
+	hi := clampIndex(math.Ceil(rank), last)
+	return nums[lo] + (nums[hi]-nums[lo])*(rank-float64(lo))
+}
+
+// clampIndex returns the index i of a slice whose last index is last, within
+// its bounds.
+func clampIndex(i float64, last int) int {
+	if !(i >= 0) {
+		return 0
+	}
+	if i > float64(last) {
+		return last
+	}
+	return int(i)
+}
+
 func (ag *aggregator) ValueMarshalled() (*protos.TaskValue, error) {
 	data := types.ValueForType(types.BinaryID)
+	if isCollectingAggregator(ag.name) {
+		ag.summarize()
+	}
 	ag.divideByCount()
 	res := &protos.TaskValue{ValType: int32(ag.result.Tid), Val: x.Nilbyte}
 	if ag.result.Value == nil {
@@ -327,6 +483,9 @@ func (ag *aggregator) divideByCount() {
 }
 
 func (ag *aggregator) Value() (types.Val, error) {
+	if isCollectingAggregator(ag.name) {
+		ag.summarize()
+	}
 	if ag.result.Value == nil {
 		return ag.result, ErrEmptyVal
 	}
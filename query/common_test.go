@@ -201,10 +201,24 @@ func processToFastJsonReqCtx(t *testing.T, query string, ctx context.Context) (s
 		return "", err
 	}
 	var buf bytes.Buffer
-	err = ToJson(queryRequest.Latency, queryRequest.Subgraphs, &buf, nil, false)
+	err = ToJson(queryRequest.Latency, queryRequest.Subgraphs, &buf, nil, false, "")
 	return string(buf.Bytes()), err
 }
 
+// processToGeoFormat returns the JSON response of the query with the
+// geometries in the given format.
+func processToGeoFormat(t *testing.T, query string, geoFormat string) string {
+	res, err := gql.Parse(gql.Request{Str: query, Http: true})
+	require.NoError(t, err)
+	queryRequest := QueryRequest{Latency: &Latency{}, GqlQuery: &res}
+	_, err = queryRequest.ProcessQuery(defaultContext())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, ToJson(queryRequest.Latency, queryRequest.Subgraphs, &buf, nil, false,
+		geoFormat))
+	return buf.String()
+}
+
 func processToFastJSON(t *testing.T, query string) string {
 	res, err := processToFastJsonReq(t, query)
 	require.NoError(t, err)
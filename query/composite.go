@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/worker"
+)
+
+// isEqValue returns true if sg compares its predicate to a single value with
+// eq, as a root function or a filter.
+func isEqValue(sg *SubGraph) bool {
+	f := sg.SrcFunc
+	return f != nil && f.Name == "eq" && !f.IsCount && len(f.Args) == 1 &&
+		!f.Args[0].IsValueVar && len(sg.Params.NeedsVar) == 0 && len(sg.Params.Langs) == 0
+}
+
+// exactEq returns true if eq matches the values of pred which are equal to its
+// argument, as the tokens of a composite index do. It doesn't for lists, whose
+// values are matched one by one, or when the case of the values is ignored.
+func exactEq(pred string) bool {
+	return !schema.State().IsList(pred) && !worker.EqFoldsCase(pred)
+}
+
+// compositeLookup returns the function looking up the composite index with
+// the most predicates among those of the eq functions, along with the
+// predicate declaring the index and the eq functions it replaces.
+func compositeLookup(eqs []*SubGraph) (*Function, string, []*SubGraph) {
+	byAttr := make(map[string]*SubGraph)
+	for _, eq := range eqs {
+		if _, ok := byAttr[eq.Attr]; !ok {
+			byAttr[eq.Attr] = eq
+		}
+	}
+	var fn *Function
+	var attr string
+	var used []*SubGraph
+	for _, eq := range eqs {
+		if _, ok := schema.State().Get(eq.Attr); !ok {
+			// The predicate is served by another group.
+			continue
+		}
+	indexes:
+		for _, ci := range schema.State().CompositeIndexes(eq.Attr) {
+			if ci.Preds[0] != eq.Attr || len(ci.Preds) <= len(used) {
+				continue
+			}
+			f := &Function{Name: "composite"}
+			var u []*SubGraph
+			for _, pred := range ci.Preds {
+				peq, ok := byAttr[pred]
+				if !ok || !exactEq(pred) {
+					continue indexes
+				}
+				f.Args = append(f.Args, gql.Arg{Value: pred}, peq.SrcFunc.Args[0])
+				u = append(u, peq)
+			}
+			fn, attr, used = f, eq.Attr, u
+		}
+	}
+	return fn, attr, used
+}
+
+// withoutFilters returns the filters which aren't replaced by a composite
+// index.
+func withoutFilters(filters, used []*SubGraph) []*SubGraph {
+	var out []*SubGraph
+	for _, f := range filters {
+		if !hasSubGraph(used, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func hasSubGraph(sgs []*SubGraph, sg *SubGraph) bool {
+	for _, s := range sgs {
+		if s == sg {
+			return true
+		}
+	}
+	return false
+}
+
+// useCompositeIndexes returns the subgraph to look up the uids of sg with, and
+// the filters to run on them, where the eq functions on all the predicates of a
+// composite index, which the uids have to match together, are replaced with a
+// single lookup of the index. They are the eq functions of an and filter, or
+// the one at root along with those of its filter. The subgraphs of the query
+// aren't changed, so that the query can be run again.
+func (sg *SubGraph) useCompositeIndexes(isRoot bool) (*SubGraph, []*SubGraph) {
+	if sg.FilterOp == "and" {
+		var eqs []*SubGraph
+		for _, f := range sg.Filters {
+			if isEqValue(f) {
+				eqs = append(eqs, f)
+			}
+		}
+		fn, attr, used := compositeLookup(eqs)
+		if fn == nil {
+			return sg, sg.Filters
+		}
+		lookup := *used[0]
+		lookup.Attr, lookup.SrcFunc = attr, fn
+		var filters []*SubGraph
+		for _, f := range sg.Filters {
+			if f == used[0] {
+				filters = append(filters, &lookup)
+			} else if !hasSubGraph(used, f) {
+				filters = append(filters, f)
+			}
+		}
+		return sg, filters
+	}
+
+	if !isRoot || len(sg.Filters) != 1 || !isEqValue(sg) {
+		return sg, sg.Filters
+	}
+	filter := sg.Filters[0]
+	eqs := []*SubGraph{sg}
+	if filter.FilterOp == "and" {
+		for _, f := range filter.Filters {
+			if isEqValue(f) {
+				eqs = append(eqs, f)
+			}
+		}
+	} else if isEqValue(filter) {
+		eqs = append(eqs, filter)
+	}
+	fn, attr, used := compositeLookup(eqs)
+	if fn == nil || !hasSubGraph(used, sg) {
+		// The composite index is on the predicates of the filter, which uses
+		// it itself.
+		return sg, sg.Filters
+	}
+	lookup := *sg
+	lookup.Attr, lookup.SrcFunc = attr, fn
+	lookup.Filters = nil
+	if filter.FilterOp == "and" {
+		if rest := withoutFilters(filter.Filters, used); len(rest) > 0 {
+			and := *filter
+			and.Filters = rest
+			lookup.Filters = []*SubGraph{&and}
+		}
+	} else if !hasSubGraph(used, filter) {
+		lookup.Filters = sg.Filters
+	}
+	return &lookup, lookup.Filters
+}
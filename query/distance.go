@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// isDistanceFunc returns true for the functions which return the uids found
+// along with their distance, like the edit distance of match, the distance
+// between the vectors of similar_to or the distance in metres of knn.
+func isDistanceFunc(name string) bool {
+	switch name {
+	case "match", "similar_to", "knn":
+		return true
+	}
+	return false
+}
+
+// setDistances keeps the distances returned along with the uids found by a
+// distance function.
+func (sg *SubGraph) setDistances(result *protos.Result) error {
+	sg.distances = make(map[uint64]types.Val)
+	if len(result.UidMatrix) == 0 || len(result.ValueMatrix) == 0 {
+		return nil
+	}
+	uids := result.UidMatrix[0].Uids
+	dists := result.ValueMatrix[0].Values
+	x.AssertTrue(len(uids) == len(dists))
+	for i, uid := range uids {
+		val, err := getValue(dists[i])
+		if err != nil {
+			return err
+		}
+		if sg.distances[uid], err = types.Convert(val, val.Tid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDistanceOrderAndPagination orders the uids found by a distance function
+// by their distance, the closest first, and then applies the pagination.
+func (sg *SubGraph) applyDistanceOrderAndPagination() {
+	sg.updateUidMatrix()
+	for i, ul := range sg.uidMatrix {
+		// The list at root is the same as DestUIDs, which has to stay sorted by
+		// uid, so a copy is ordered. The uids are sorted, so the ones with the
+		// same distance stay in uid order.
+		uids := append([]uint64{}, ul.Uids...)
+		sort.SliceStable(uids, func(i, j int) bool {
+			less, _ := types.Less(sg.distances[uids[i]], sg.distances[uids[j]])
+			return less
+		})
+		start, end := x.PageRange(sg.Params.Count, sg.Params.Offset, len(uids))
+		sg.uidMatrix[i] = &protos.List{Uids: uids[start:end]}
+	}
+	sg.updateDestUids()
+}
+
+// evaluateDistance returns the distances of the uids found by the distance
+// function of the parent, for distance(pred) in its block.
+func (sg *SubGraph) evaluateDistance(parent *SubGraph) error {
+	if parent == nil || parent.distances == nil || parent.Attr != sg.Attr {
+		return x.Errorf("distance(%s) is only allowed in a block found by a distance "+
+			"function on %s", sg.Attr, sg.Attr)
+	}
+	for _, uid := range sg.SrcUIDs.Uids {
+		tv := &protos.TaskValue{Val: x.Nilbyte}
+		if d, ok := parent.distances[uid]; ok {
+			data := types.ValueForType(types.BinaryID)
+			if err := types.Marshal(d, &data); err != nil {
+				return err
+			}
+			tv = &protos.TaskValue{Val: data.Value.([]byte), ValType: int32(d.Tid)}
+		}
+		sg.uidMatrix = append(sg.uidMatrix, &protos.List{})
+		sg.valueMatrix = append(sg.valueMatrix, &protos.ValueList{
+			Values: []*protos.TaskValue{tv}})
+	}
+	sg.DestUIDs = &protos.List{}
+	return nil
+}
+
+func addDistance(pc *SubGraph, val *protos.TaskValue, dst outputNode) {
+	sv, err := convertWithBestEffort(val, pc.Attr)
+	if err != nil {
+		return
+	}
+	fieldName := fmt.Sprintf("distance(%s)", pc.Attr)
+	if pc.Params.Alias != "" {
+		fieldName = pc.Params.Alias
+	}
+	dst.AddValue(fieldName, sv)
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type distinctValue struct {
+	val   types.Val
+	count int64
+}
+
+// evaluateDistinct processes distinct(pred) and distinct(pred, "prefix") at
+// root. The distinct values of pred are read from its index by the group
+// serving it, along with the number of nodes having each of them.
+func (sg *SubGraph) evaluateDistinct(ctx context.Context) error {
+	var prefix string
+	switch len(sg.SrcFunc.Args) {
+	case 0:
+	case 1:
+		prefix = sg.SrcFunc.Args[0].Value
+	default:
+		return x.Errorf("distinct function expects a predicate and an optional prefix")
+	}
+	if sg.Params.Count < 0 || sg.Params.Offset < 0 {
+		return x.Errorf("distinct function doesn't support negative first or offset")
+	}
+	q := &protos.Query{
+		Attr: sg.Attr,
+		SrcFunc: &protos.SrcFunction{
+			Name: "distinct",
+			Args: []string{prefix, strconv.Itoa(sg.Params.Offset),
+				strconv.Itoa(sg.Params.Count)},
+		},
+	}
+	result, err := worker.ProcessTaskOverNetwork(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	sg.DistinctRes = sg.DistinctRes[:0]
+	if len(result.ValueMatrix) > 0 {
+		values := result.ValueMatrix[0].Values
+		if len(values) != len(result.Counts) {
+			return x.Errorf("Expected %d counts for distinct values of %s, got %d",
+				len(values), sg.Attr, len(result.Counts))
+		}
+		for i, tv := range values {
+			val, err := convertTo(tv)
+			if err != nil {
+				return err
+			}
+			sg.DistinctRes = append(sg.DistinctRes, distinctValue{
+				val:   val,
+				count: int64(result.Counts[i]),
+			})
+		}
+	}
+	// There are no nodes to traverse, only the values to output.
+	sg.DestUIDs = &protos.List{}
+	sg.uidMatrix = []*protos.List{sg.DestUIDs}
+	return nil
+}
+
+func (sg *SubGraph) isDistinct() bool {
+	return sg.SrcFunc != nil && sg.SrcFunc.Name == "distinct"
+}
+
+func addDistinct(n outputNode, sg *SubGraph) {
+	for _, dv := range sg.DistinctRes {
+		n1 := n.New(sg.Params.Alias)
+		n1.AddValue(sg.Attr, dv.val)
+		n1.AddValue("count", types.Val{Tid: types.IntID, Value: dv.count})
+		n.AddListChild(sg.Params.Alias, n1)
+	}
+}
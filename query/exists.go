@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"golang.org/x/net/trace"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// evaluateExists is called for an exists { ... } filter. The block is
+// processed for all the SrcUIDs at once, and only the SrcUIDs for which it
+// yields at least one result are kept in DestUIDs.
+func (sg *SubGraph) evaluateExists(ctx context.Context) error {
+	sg.DestUIDs = &protos.List{Uids: sg.SrcUIDs.Uids}
+	childChan := make(chan error, len(sg.Children))
+	for _, child := range sg.Children {
+		child.Params.ParentVars = make(map[string]varValue)
+		for k, v := range sg.Params.ParentVars {
+			child.Params.ParentVars[k] = v
+		}
+		child.SrcUIDs = sg.DestUIDs
+		if child.IsInternal() {
+			continue
+		}
+		go ProcessGraph(ctx, child, sg, childChan)
+	}
+
+	var childErr error
+	for _, child := range sg.Children {
+		if child.IsInternal() {
+			continue
+		}
+		if err := <-childChan; err != nil {
+			childErr = err
+			if tr, ok := trace.FromContext(ctx); ok {
+				tr.LazyPrintf("Error while processing exists block: %+v", err)
+			}
+		}
+	}
+	if childErr != nil {
+		return childErr
+	}
+
+	sg.DestUIDs = sg.yieldingUids()
+	return nil
+}
+
+// yieldingUids returns the SrcUIDs of the children of sg, for which at least
+// one child has a value, or a uid which yields results for its own children.
+func (sg *SubGraph) yieldingUids() *protos.List {
+	var lists []*protos.List
+	for _, child := range sg.Children {
+		if child.IsInternal() || child.SrcUIDs == nil {
+			continue
+		}
+		lists = append(lists, child.yields())
+	}
+	return algo.MergeSorted(lists)
+}
+
+func (sg *SubGraph) yields() *protos.List {
+	// The uids which survived the filters, and the ones yielding results for
+	// the children, if any.
+	dest := sg.DestUIDs
+	if dest == nil {
+		dest = &protos.List{}
+	}
+	if len(sg.Params.Order) > 0 {
+		// DestUIDs aren't sorted by uid in this case.
+		uids := append([]uint64{}, dest.Uids...)
+		sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+		dest = &protos.List{Uids: uids}
+	}
+	for _, child := range sg.Children {
+		if !child.IsInternal() {
+			dest = algo.IntersectSorted([]*protos.List{dest, sg.yieldingUids()})
+			break
+		}
+	}
+
+	out := &protos.List{}
+	for i, uid := range sg.SrcUIDs.Uids {
+		if i < len(sg.counts) && sg.counts[i] > 0 {
+			out.Uids = append(out.Uids, uid)
+			continue
+		}
+		if i < len(sg.uidMatrix) && len(sg.uidMatrix[i].Uids) > 0 {
+			for _, u := range sg.uidMatrix[i].Uids {
+				if algo.IndexOf(dest, u) >= 0 {
+					out.Uids = append(out.Uids, uid)
+					break
+				}
+			}
+			continue
+		}
+		if i < len(sg.valueMatrix) {
+			for _, tv := range sg.valueMatrix[i].Values {
+				if !bytes.Equal(tv.Val, x.Nilbyte) {
+					out.Uids = append(out.Uids, uid)
+					break
+				}
+			}
+		}
+	}
+	return out
+}
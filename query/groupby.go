@@ -18,13 +18,14 @@
 package query
 
 import (
-	"fmt"
 	"sort"
 	"strconv"
 
 	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -45,7 +46,7 @@ func (grp *groupResult) aggregateChild(child *SubGraph) error {
 			return x.Errorf("Only _uid_ predicate is allowed in count within groupby")
 		}
 		grp.aggregates = append(grp.aggregates, groupPair{
-			attr: "count",
+			attr: child.groupFieldName(),
 			key: types.Val{
 				Tid:   types.IntID,
 				Value: int64(len(grp.uids)),
@@ -54,19 +55,30 @@ func (grp *groupResult) aggregateChild(child *SubGraph) error {
 		return nil
 	}
 	if child.SrcFunc != nil && isAggregatorFn(child.SrcFunc.Name) {
-		fieldName := fmt.Sprintf("%s(%s)", child.SrcFunc.Name, child.Attr)
 		finalVal, err := aggregateGroup(grp, child)
 		if err != nil {
 			return err
 		}
 		grp.aggregates = append(grp.aggregates, groupPair{
-			attr: fieldName,
+			attr: child.groupFieldName(),
 			key:  finalVal,
 		})
 	}
 	return nil
 }
 
+// groupFieldName returns the name under which the aggregate computed by child
+// is output for each group.
+func (child *SubGraph) groupFieldName() string {
+	switch {
+	case child.Params.Alias != "":
+		return child.Params.Alias
+	case child.Params.DoCount:
+		return "count"
+	}
+	return aggFieldName(child.SrcFunc, child.Attr)
+}
+
 type groupResults struct {
 	group []*groupResult
 }
@@ -129,12 +141,17 @@ func (d *dedup) addValue(attr string, value types.Val, uid uint64) {
 		}
 	}
 	curEntity := cur.elements[strKey].entities
+	if n := len(curEntity.Uids); n > 0 && curEntity.Uids[n-1] == uid {
+		// The same value was seen on another edge of this uid.
+		return
+	}
 	curEntity.Uids = append(curEntity.Uids, uid)
 }
 
 func aggregateGroup(grp *groupResult, child *SubGraph) (types.Val, error) {
-	ag := aggregator{
-		name: child.SrcFunc.Name,
+	ag, err := newAggregator(child.SrcFunc)
+	if err != nil {
+		return types.Val{}, err
 	}
 	for _, uid := range grp.uids {
 		idx := sort.Search(len(child.SrcUIDs.Uids), func(i int) bool {
@@ -200,7 +217,20 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 		if !child.Params.ignoreResult {
 			continue
 		}
-		if len(child.DestUIDs.Uids) != 0 {
+		if child.Params.Facet != nil {
+			// Group by a facet of the edges.
+			key := child.Params.Facet.Keys[0]
+			for i, fl := range child.facetsMatrix {
+				srcUid := child.SrcUIDs.Uids[i]
+				for _, fs := range fl.FacetsList {
+					for _, f := range fs.Facets {
+						if f.Key == key {
+							dedupMap.addValue(key, facets.ValFor(f), srcUid)
+						}
+					}
+				}
+			}
+		} else if len(child.DestUIDs.Uids) != 0 {
 			// It's a UID node.
 			for i := 0; i < len(child.uidMatrix); i++ {
 				srcUid := child.SrcUIDs.Uids[i]
@@ -228,6 +258,7 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 	res.formGroups(dedupMap, &protos.List{}, []groupPair{})
 
 	// Go over the groups and aggregate the values.
+	var aggregated []*SubGraph
 	for _, child := range sg.Children {
 		if child.Params.ignoreResult {
 			continue
@@ -239,41 +270,183 @@ func (sg *SubGraph) processGroupBy(doneVars map[string]varValue, path []*SubGrap
 				return err
 			}
 		}
+		aggregated = append(aggregated, child)
+		child.Params.ignoreResult = true
+	}
+
+	if err := res.applyParams(sg.Params); err != nil {
+		return err
+	}
+
+	// Variables only get the values of the groups which are returned.
+	for _, child := range aggregated {
 		chVar := child.Params.Var
-		if chVar != "" {
-			tempMap := make(map[uint64]types.Val)
-			for _, grp := range res.group {
-				if len(grp.keys) == 0 {
-					continue
-				}
-				if len(grp.keys) > 1 {
-					return x.Errorf("Expected one UID for var in groupby but got: %d", len(grp.keys))
-				}
-				uidVal := grp.keys[0].key.Value
-				uid, ok := uidVal.(uint64)
-				if !ok {
-					return x.Errorf("Vars can be assigned only when grouped by UID attribute")
-				}
-				// grp.aggregates could be empty if schema conversion failed during aggregation
-				if len(grp.aggregates) > 0 {
-					tempMap[uid] = grp.aggregates[len(grp.aggregates)-1].key
-				}
+		if chVar == "" {
+			continue
+		}
+		tempMap := make(map[uint64]types.Val)
+		for _, grp := range res.group {
+			if len(grp.keys) == 0 {
+				continue
+			}
+			if len(grp.keys) > 1 {
+				return x.Errorf("Expected one UID for var in groupby but got: %d", len(grp.keys))
+			}
+			uidVal := grp.keys[0].key.Value
+			uid, ok := uidVal.(uint64)
+			if !ok {
+				return x.Errorf("Vars can be assigned only when grouped by UID attribute")
 			}
-			doneVars[chVar] = varValue{
-				Vals: tempMap,
-				path: append(path, pathNode),
+			// The aggregate could be missing if schema conversion failed during aggregation
+			if v, ok := grp.field(child.groupFieldName()); ok {
+				tempMap[uid] = v
 			}
 		}
-		child.Params.ignoreResult = true
+		doneVars[chVar] = varValue{
+			Vals: tempMap,
+			path: append(path, pathNode),
+		}
+	}
+	sg.GroupbyRes = res
+	return nil
+}
+
+// applyParams filters the groups using @having, sorts them and applies the
+// pagination given to @groupby.
+func (res *groupResults) applyParams(p params) error {
+	if p.groupbyHaving != nil {
+		var out []*groupResult
+		for _, grp := range res.group {
+			ok, err := grp.matches(p.groupbyHaving)
+			if err != nil {
+				return err
+			}
+			if ok {
+				out = append(out, grp)
+			}
+		}
+		res.group = out
 	}
+
 	// Sort to order the groups for determinism.
 	sort.Slice(res.group, func(i, j int) bool {
-		return groupLess(res.group[i], res.group[j])
+		a, b := res.group[i], res.group[j]
+		for _, o := range p.groupbyOrder {
+			if c := compareGroupField(a, b, o.Attr); c != 0 {
+				return (c < 0) != o.Desc
+			}
+		}
+		return groupLess(a, b)
 	})
-	sg.GroupbyRes = res
+
+	if p.groupbyOffset > 0 {
+		if p.groupbyOffset >= len(res.group) {
+			res.group = nil
+		} else {
+			res.group = res.group[p.groupbyOffset:]
+		}
+	}
+	if p.groupbyCount > 0 && p.groupbyCount < len(res.group) {
+		res.group = res.group[:p.groupbyCount]
+	}
 	return nil
 }
 
+// field returns the value of a key or an aggregate of the group.
+func (grp *groupResult) field(name string) (types.Val, bool) {
+	for _, it := range grp.keys {
+		if it.attr == name {
+			return it.key, true
+		}
+	}
+	for _, it := range grp.aggregates {
+		if it.attr == name {
+			return it.key, true
+		}
+	}
+	return types.Val{}, false
+}
+
+// compareGroupField returns -1, 0 or 1 depending on whether the field of group
+// a is less than, equal to or greater than the one of group b. Groups missing
+// the field always come last.
+func compareGroupField(a, b *groupResult, name string) int {
+	va, oka := a.field(name)
+	vb, okb := b.field(name)
+	switch {
+	case !oka && !okb:
+		return 0
+	case !oka:
+		return 1
+	case !okb:
+		return -1
+	}
+	if l, err := compareValues("<", va, vb); err == nil && l {
+		return -1
+	}
+	if l, err := compareValues("<", vb, va); err == nil && l {
+		return 1
+	}
+	return 0
+}
+
+var havingFuncs = map[string]string{
+	"eq": "==",
+	"lt": "<",
+	"le": "<=",
+	"gt": ">",
+	"ge": ">=",
+}
+
+// matches evaluates the @having filter for the group. Functions refer to the
+// keys and aggregates of the group by name, e.g. gt(count, 10).
+func (grp *groupResult) matches(ft *gql.FilterTree) (bool, error) {
+	if ft.Func == nil {
+		switch ft.Op {
+		case "not":
+			ok, err := grp.matches(ft.Child[0])
+			return !ok, err
+		case "and", "or":
+			for _, c := range ft.Child {
+				ok, err := grp.matches(c)
+				if err != nil {
+					return false, err
+				}
+				if ok != (ft.Op == "and") {
+					return ok, nil
+				}
+			}
+			return ft.Op == "and", nil
+		}
+		return false, x.Errorf("Unknown operator in @having: %v", ft.Op)
+	}
+
+	f := ft.Func
+	op, ok := havingFuncs[f.Name]
+	if !ok {
+		return false, x.Errorf("Only eq, lt, le, gt and ge are allowed in @having. Got: %v",
+			f.Name)
+	}
+	if len(f.Args) != 1 {
+		return false, x.Errorf("Expected one argument for %s in @having. Got: %v",
+			f.Name, len(f.Args))
+	}
+	v, ok := grp.field(f.Attr)
+	if !ok {
+		return false, nil
+	}
+	// Ints are compared as floats, so that gt(count, 2.5) works.
+	typ := v.Tid
+	if typ == types.IntID {
+		typ = types.FloatID
+	}
+	arg, err := types.Convert(types.Val{Tid: types.StringID, Value: []byte(f.Args[0].Value)}, typ)
+	if err != nil {
+		return false, x.Wrapf(err, "Invalid argument for %s(%s) in @having", f.Name, f.Attr)
+	}
+	return compareValues(op, v, arg)
+}
+
 func groupLess(a, b *groupResult) bool {
 	if len(a.uids) < len(b.uids) {
 		return true
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"math"
+
+	farm "github.com/dgryski/go-farm"
+)
+
+// hllPrecision is the number of bits of the hash used to pick a register. With
+// 2^14 registers the standard error of the estimate is about 0.8%.
+const hllPrecision = 14
+
+// hyperLogLog estimates the number of distinct keys added to it, using a fixed
+// amount of memory whatever the number of keys.
+type hyperLogLog struct {
+	registers []uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{registers: make([]uint8, 1<<hllPrecision)}
+}
+
+func (h *hyperLogLog) Add(key []byte) {
+	hash := farm.Fingerprint64(key)
+	idx := hash >> (64 - hllPrecision)
+	// The guard bit bounds the rank when the remaining bits are all zero.
+	w := hash<<hllPrecision | 1<<(hllPrecision-1)
+	rank := uint8(1)
+	for w&(1<<63) == 0 {
+		rank++
+		w <<= 1
+	}
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+func (h *hyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+	var sum float64
+	var zeros int
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		// Use linear counting for small cardinalities.
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(est + 0.5)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// evaluateJoin processes join(pred, val(v)). All the distinct values of the
+// variable are looked up in the index of pred with a single eq task. At root
+// we return all the matching nodes. Otherwise, each source uid is linked to the
+// nodes whose value of pred equals its own value in the variable.
+func (sg *SubGraph) evaluateJoin(ctx context.Context, isRoot bool) error {
+	// Map each uid to the string form of its value, and each distinct value
+	// to its row in the result.
+	uidToArg := make(map[uint64]string, len(sg.Params.uidToVal))
+	rows := make(map[string]int)
+	var args []string
+	for uid, v := range sg.Params.uidToVal {
+		data := types.ValueForType(types.StringID)
+		if err := types.Marshal(v, &data); err != nil {
+			return err
+		}
+		arg := data.Value.(string)
+		uidToArg[uid] = arg
+		if _, ok := rows[arg]; !ok {
+			rows[arg] = 0
+			args = append(args, arg)
+		}
+	}
+	sort.Strings(args)
+	for i, arg := range args {
+		rows[arg] = i
+	}
+
+	var matrix []*protos.List
+	if len(args) > 0 {
+		q := &protos.Query{
+			Attr:  sg.Attr,
+			Langs: sg.Params.Langs,
+			SrcFunc: &protos.SrcFunction{
+				Name: "eq",
+				Args: args,
+			},
+		}
+		result, err := worker.ProcessTaskOverNetwork(ctx, q)
+		if err != nil {
+			return err
+		}
+		if len(result.UidMatrix) != len(args) {
+			return x.Errorf("Expected %d rows for join on %s, got %d",
+				len(args), sg.Attr, len(result.UidMatrix))
+		}
+		matrix = result.UidMatrix
+	}
+
+	if isRoot {
+		sg.DestUIDs = algo.MergeSorted(matrix)
+		sg.uidMatrix = []*protos.List{sg.DestUIDs}
+		return nil
+	}
+
+	sg.uidMatrix = make([]*protos.List, len(sg.SrcUIDs.Uids))
+	for i, uid := range sg.SrcUIDs.Uids {
+		arg, ok := uidToArg[uid]
+		if !ok {
+			sg.uidMatrix[i] = &protos.List{}
+			continue
+		}
+		// Copy, as the same row can be shared by many uids.
+		row := matrix[rows[arg]].Uids
+		sg.uidMatrix[i] = &protos.List{Uids: append([]uint64{}, row...)}
+	}
+	sg.valueMatrix = emptyValueMatrix(len(sg.uidMatrix))
+	sg.DestUIDs = algo.MergeSorted(sg.uidMatrix)
+	return nil
+}
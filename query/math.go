@@ -1,6 +1,7 @@
 package query
 
 import (
+	"github.com/dgraph-io/dgraph/gql"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -195,6 +196,60 @@ func processTernary(mNode *mathTree) (err error) {
 	return nil
 }
 
+// processValueFunc handles the functions on dates, strings and types, like
+// concat, dateadd or tostring. A uid gets a value only if all the variables
+// used as arguments have a value for it.
+func processValueFunc(mNode *mathTree) error {
+	vf, ok := valueFuncs[mNode.Fn]
+	if !ok {
+		return x.Errorf("Unhandled math function: %v", mNode.Fn)
+	}
+	if len(mNode.Child) < vf.minArgs || (vf.maxArgs >= 0 && len(mNode.Child) > vf.maxArgs) {
+		return x.Errorf("Function %v expects between %d and %d arguments. But got: %v",
+			mNode.Fn, vf.minArgs, vf.maxArgs, len(mNode.Child))
+	}
+
+	var uids map[uint64]types.Val
+	for _, ch := range mNode.Child {
+		if ch.Const.Value == nil && (uids == nil || len(ch.Val) < len(uids)) {
+			uids = ch.Val
+		}
+	}
+	args := make([]types.Val, len(mNode.Child))
+	if uids == nil {
+		// All the arguments are constants.
+		for i, ch := range mNode.Child {
+			args[i] = ch.Const
+		}
+		var err error
+		mNode.Const, err = vf.fn(args)
+		return err
+	}
+
+	destMap := make(map[uint64]types.Val)
+UIDS:
+	for k := range uids {
+		for i, ch := range mNode.Child {
+			if ch.Const.Value != nil {
+				args[i] = ch.Const
+				continue
+			}
+			v, ok := ch.Val[k]
+			if !ok || v.Value == nil {
+				continue UIDS
+			}
+			args[i] = v
+		}
+		res, err := vf.fn(args)
+		if err != nil {
+			return x.Wrapf(err, "While evaluating %v", mNode.Fn)
+		}
+		destMap[k] = res
+	}
+	mNode.Val = destMap
+	return nil
+}
+
 func evalMathTree(mNode *mathTree) (err error) {
 	if mNode.Const.Value != nil {
 		return nil
@@ -240,6 +295,10 @@ func evalMathTree(mNode *mathTree) (err error) {
 		return processBinaryBoolean(mNode)
 	}
 
+	if gql.IsValueFunc(aggName) {
+		return processValueFunc(mNode)
+	}
+
 	if isTernary(aggName) {
 		if len(mNode.Child) != 3 {
 			return x.Errorf("Function %v expects 3 argument. But got: %v", aggName,
@@ -0,0 +1,370 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type valueFunc struct {
+	minArgs int
+	maxArgs int // No limit if negative.
+	fn      func(args []types.Val) (types.Val, error)
+}
+
+// valueFuncs are the implementations of gql.ValueFuncs. They are called with
+// the values of their arguments for each uid.
+var valueFuncs = map[string]valueFunc{
+	"dateadd":    {2, 2, dateAdd},
+	"truncate":   {2, 2, truncateDate},
+	"extract":    {2, 2, extractDate},
+	"concat":     {1, -1, concat},
+	"lower":      {1, 1, stringFunc(strings.ToLower)},
+	"upper":      {1, 1, stringFunc(strings.ToUpper)},
+	"length":     {1, 1, length},
+	"substring":  {2, 3, substring},
+	"tostring":   {1, 1, convertFunc(types.StringID)},
+	"toint":      {1, 1, convertFunc(types.IntID)},
+	"tofloat":    {1, 1, convertFunc(types.FloatID)},
+	"tobool":     {1, 1, convertFunc(types.BoolID)},
+	"todatetime": {1, 1, convertFunc(types.DateTimeID)},
+}
+
+// convertValue converts v to the type tid. Numbers can be converted to and
+// from dates as seconds since the Unix epoch.
+func convertValue(v types.Val, tid types.TypeID) (types.Val, error) {
+	if v.Tid == tid || (tid == types.StringID && v.Tid == types.DefaultID) {
+		return types.Val{Tid: tid, Value: v.Value}, nil
+	}
+	res := types.Val{Tid: tid}
+	switch tid {
+	case types.StringID:
+		if f, ok := v.Value.(float64); ok {
+			res.Value = strconv.FormatFloat(f, 'f', -1, 64)
+			return res, nil
+		}
+		res = types.ValueForType(types.StringID)
+		err := types.Marshal(v, &res)
+		return res, err
+	case types.IntID, types.FloatID:
+		var f float64
+		switch val := v.Value.(type) {
+		case int64:
+			f = float64(val)
+		case float64:
+			f = val
+		case bool:
+			if val {
+				f = 1
+			}
+		case time.Time:
+			f = float64(val.UnixNano()) / 1e9
+		case string:
+			var err error
+			if f, err = strconv.ParseFloat(strings.TrimSpace(val), 64); err != nil {
+				return res, x.Errorf("Can't convert %q to a number", val)
+			}
+		default:
+			return res, x.Errorf("Can't convert value of type %s to a number", v.Tid.Name())
+		}
+		if tid == types.IntID {
+			res.Value = int64(f)
+		} else {
+			res.Value = f
+		}
+		return res, nil
+	case types.BoolID:
+		switch val := v.Value.(type) {
+		case int64:
+			res.Value = val != 0
+		case float64:
+			res.Value = val != 0
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(val))
+			if err != nil {
+				return res, x.Errorf("Can't convert %q to a bool", val)
+			}
+			res.Value = b
+		default:
+			return res, x.Errorf("Can't convert value of type %s to a bool", v.Tid.Name())
+		}
+		return res, nil
+	case types.DateTimeID:
+		switch val := v.Value.(type) {
+		case int64:
+			res.Value = time.Unix(val, 0).UTC()
+		case float64:
+			sec, frac := math.Modf(val)
+			res.Value = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+		case string:
+			t, err := types.ParseTime(strings.TrimSpace(val))
+			if err != nil {
+				return res, err
+			}
+			res.Value = t
+		default:
+			return res, x.Errorf("Can't convert value of type %s to a datetime", v.Tid.Name())
+		}
+		return res, nil
+	}
+	return res, x.Errorf("Conversion to %s isn't supported", tid.Name())
+}
+
+func convertFunc(tid types.TypeID) func([]types.Val) (types.Val, error) {
+	return func(args []types.Val) (types.Val, error) {
+		return convertValue(args[0], tid)
+	}
+}
+
+func toString(v types.Val) (string, error) {
+	s, err := convertValue(v, types.StringID)
+	if err != nil {
+		return "", err
+	}
+	return s.Value.(string), nil
+}
+
+func toInt(v types.Val) (int64, error) {
+	i, err := convertValue(v, types.IntID)
+	if err != nil {
+		return 0, err
+	}
+	return i.Value.(int64), nil
+}
+
+func toTime(v types.Val) (time.Time, error) {
+	t, err := convertValue(v, types.DateTimeID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Value.(time.Time), nil
+}
+
+func stringFunc(f func(string) string) func([]types.Val) (types.Val, error) {
+	return func(args []types.Val) (types.Val, error) {
+		s, err := toString(args[0])
+		if err != nil {
+			return types.Val{}, err
+		}
+		return types.Val{Tid: types.StringID, Value: f(s)}, nil
+	}
+}
+
+func concat(args []types.Val) (types.Val, error) {
+	var buf bytes.Buffer
+	for _, arg := range args {
+		s, err := toString(arg)
+		if err != nil {
+			return types.Val{}, err
+		}
+		buf.WriteString(s)
+	}
+	return types.Val{Tid: types.StringID, Value: buf.String()}, nil
+}
+
+func length(args []types.Val) (types.Val, error) {
+	s, err := toString(args[0])
+	if err != nil {
+		return types.Val{}, err
+	}
+	return types.Val{Tid: types.IntID, Value: int64(utf8.RuneCountInString(s))}, nil
+}
+
+// substring returns the characters of a string from a start index, and up to
+// an optional length. A negative start counts from the end of the string.
+func substring(args []types.Val) (types.Val, error) {
+	s, err := toString(args[0])
+	if err != nil {
+		return types.Val{}, err
+	}
+	runes := []rune(s)
+	n := int64(len(runes))
+	start, err := toInt(args[1])
+	if err != nil {
+		return types.Val{}, err
+	}
+	if start < 0 {
+		start += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > n {
+		start = n
+	}
+	end := n
+	if len(args) == 3 {
+		l, err := toInt(args[2])
+		if err != nil {
+			return types.Val{}, err
+		}
+		if l < 0 {
+			return types.Val{}, x.Errorf("substring expects a non-negative length. Got: %d", l)
+		}
+		if start+l < end {
+			end = start + l
+		}
+	}
+	return types.Val{Tid: types.StringID, Value: string(runes[start:end])}, nil
+}
+
+// parseDateDuration parses durations like "1y2mo", "-3d" or "1h30m". Years,
+// months, weeks and days follow the calendar, the other units are exact.
+func parseDateDuration(s string) (years, months, days int, d time.Duration, err error) {
+	str := strings.TrimSpace(s)
+	sign := 1
+	if strings.HasPrefix(str, "-") {
+		sign = -1
+		str = str[1:]
+	}
+	if len(str) == 0 {
+		return 0, 0, 0, 0, x.Errorf("Invalid duration: %q", s)
+	}
+	for len(str) > 0 {
+		i := 0
+		for i < len(str) && str[i] >= '0' && str[i] <= '9' {
+			i++
+		}
+		j := i
+		for j < len(str) && (str[j] < '0' || str[j] > '9') {
+			j++
+		}
+		if i == 0 || i == j {
+			return 0, 0, 0, 0, x.Errorf("Invalid duration: %q", s)
+		}
+		n, err := strconv.Atoi(str[:i])
+		if err != nil {
+			return 0, 0, 0, 0, x.Errorf("Invalid duration: %q", s)
+		}
+		n *= sign
+		switch unit := str[i:j]; unit {
+		case "y":
+			years += n
+		case "mo":
+			months += n
+		case "w":
+			days += 7 * n
+		case "d":
+			days += n
+		case "h":
+			d += time.Duration(n) * time.Hour
+		case "m":
+			d += time.Duration(n) * time.Minute
+		case "s":
+			d += time.Duration(n) * time.Second
+		case "ms":
+			d += time.Duration(n) * time.Millisecond
+		default:
+			return 0, 0, 0, 0, x.Errorf("Invalid unit %q in duration: %q", unit, s)
+		}
+		str = str[j:]
+	}
+	return years, months, days, d, nil
+}
+
+func dateAdd(args []types.Val) (types.Val, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return types.Val{}, err
+	}
+	dur, err := toString(args[1])
+	if err != nil {
+		return types.Val{}, err
+	}
+	years, months, days, d, err := parseDateDuration(dur)
+	if err != nil {
+		return types.Val{}, err
+	}
+	return types.Val{Tid: types.DateTimeID, Value: t.AddDate(years, months, days).Add(d)}, nil
+}
+
+// truncateDate returns the start of the year, month, week (on Monday), day,
+// hour or minute of a date.
+func truncateDate(args []types.Val) (types.Val, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return types.Val{}, err
+	}
+	unit, err := toString(args[1])
+	if err != nil {
+		return types.Val{}, err
+	}
+	y, m, d := t.Date()
+	loc := t.Location()
+	var res time.Time
+	switch strings.ToLower(unit) {
+	case "year":
+		res = time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+	case "month":
+		res = time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		res = time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case "day":
+		res = time.Date(y, m, d, 0, 0, 0, 0, loc)
+	case "hour":
+		res = time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+	case "minute":
+		res = time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+	default:
+		return types.Val{}, x.Errorf("Invalid unit for truncate: %q", unit)
+	}
+	return types.Val{Tid: types.DateTimeID, Value: res}, nil
+}
+
+// extractDate returns a part of a date. The weekday is 0 for Sunday.
+func extractDate(args []types.Val) (types.Val, error) {
+	t, err := toTime(args[0])
+	if err != nil {
+		return types.Val{}, err
+	}
+	part, err := toString(args[1])
+	if err != nil {
+		return types.Val{}, err
+	}
+	var res int
+	switch strings.ToLower(part) {
+	case "year":
+		res = t.Year()
+	case "month":
+		res = int(t.Month())
+	case "day":
+		res = t.Day()
+	case "hour":
+		res = t.Hour()
+	case "minute":
+		res = t.Minute()
+	case "second":
+		res = t.Second()
+	case "weekday":
+		res = int(t.Weekday())
+	case "yearday":
+		res = t.YearDay()
+	default:
+		return types.Val{}, x.Errorf("Invalid part for extract: %q", part)
+	}
+	return types.Val{Tid: types.IntID, Value: int64(res)}, nil
+}
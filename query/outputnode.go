@@ -40,6 +40,22 @@ const (
 	normalizeLimit = 10000
 )
 
+// The formats of the geometries in the JSON response, besides the default of
+// GeoJSON geometry objects.
+const (
+	// GeoWKT outputs the geometries as strings in the Well-known text format.
+	GeoWKT = "wkt"
+	// GeoFeatures outputs the results at the root of the query as a GeoJSON
+	// FeatureCollection, instead of the usual response.
+	GeoFeatures = "geojson"
+)
+
+// ValidGeoFormat returns true if format is a valid format of the geometries
+// of the JSON response. The empty string is the default format.
+func ValidGeoFormat(format string) bool {
+	return format == "" || format == GeoWKT || format == GeoFeatures
+}
+
 // ToProtocolBuf returns the list of protos.Node which would be returned to the go
 // client.
 func ToProtocolBuf(l *Latency, sgl []*SubGraph) ([]*protos.Node, error) {
@@ -58,8 +74,9 @@ func ToProtocolBuf(l *Latency, sgl []*SubGraph) ([]*protos.Node, error) {
 }
 
 // ToJson converts the list of subgraph into a JSON response by calling toFastJSON.
+// The geometries are output in geoFormat.
 func ToJson(l *Latency, sgl []*SubGraph, w io.Writer, allocIds map[string]string,
-	addLatency bool) error {
+	addLatency bool, geoFormat string) error {
 	sgr := &SubGraph{}
 	for _, sg := range sgl {
 		if sg.Params.Alias == "var" || sg.Params.Alias == "shortest" {
@@ -70,7 +87,7 @@ func ToJson(l *Latency, sgl []*SubGraph, w io.Writer, allocIds map[string]string
 		}
 		sgr.Children = append(sgr.Children, sg)
 	}
-	return sgr.toFastJSON(l, w, allocIds, addLatency)
+	return sgr.toFastJSON(l, w, allocIds, addLatency, geoFormat)
 }
 
 // outputNode is the generic output / writer for preTraverse.
@@ -293,6 +310,8 @@ func (sg *SubGraph) ToProtocolBuffer(l *Latency) (*protos.Node, error) {
 
 	if sg.Params.isGroupBy {
 		n.addGroupby(sg, sg.Params.Alias)
+	} else if sg.isDistinct() {
+		addDistinct(n, sg)
 	} else {
 		for _, uid := range sg.uidMatrix[0].Uids {
 			// For the root, the name is stored in Alias, not Attr.
@@ -352,11 +371,16 @@ type fastJsonNode struct {
 	isChild   bool
 	scalarVal []byte
 	attrs     []*fastJsonNode
+	geo       geom.T // Geometry of scalarVal, to output it in another format.
 }
 
 func (fj *fastJsonNode) AddValue(attr string, v types.Val) {
 	if bs, err := valToBytes(v); err == nil {
-		fj.attrs = append(fj.attrs, makeScalarNode(attr, false, bs))
+		n := makeScalarNode(attr, false, bs)
+		if v.Tid == types.GeoID {
+			n.geo = v.Value.(geom.T)
+		}
+		fj.attrs = append(fj.attrs, n)
 	}
 }
 
@@ -431,6 +455,8 @@ func valToBytes(v types.Val) ([]byte, error) {
 		return []byte(fmt.Sprintf("\"%#x\"", v.Value)), nil
 	case types.PasswordID:
 		return []byte(fmt.Sprintf("%q", v.Value.(string))), nil
+	case types.Float32VectorID:
+		return json.Marshal(v.Value.([]float32))
 	default:
 		return nil, errors.New("unsupported types.Val.Tid")
 	}
@@ -532,6 +558,88 @@ func (fj *fastJsonNode) encode(out *bufio.Writer) {
 	}
 }
 
+// geoToWKT replaces the GeoJSON geometries of the node and its children with
+// their WKT strings.
+func (fj *fastJsonNode) geoToWKT() error {
+	if fj.geo != nil {
+		wkt, err := types.MarshalWKT(fj.geo)
+		if err != nil {
+			return err
+		}
+		if fj.scalarVal, err = json.Marshal(wkt); err != nil {
+			return err
+		}
+		// Nodes can be shared after normalization.
+		fj.geo = nil
+	}
+	for _, a := range fj.attrs {
+		if err := a.geoToWKT(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeFeatures writes the results at the root of the query as a GeoJSON
+// FeatureCollection. The first geometry of a result is the geometry of its
+// feature, its _uid_ is the id and its other attributes are the properties.
+// The uids allocated by the mutation and the extensions are kept as foreign
+// members of the collection, under the same keys as in the usual response.
+func (fj *fastJsonNode) encodeFeatures(w io.Writer, uids *fastJsonNode,
+	extensions []byte) error {
+	bufw := bufio.NewWriter(w)
+	bufw.WriteString(`{"type":"FeatureCollection",`)
+	if len(extensions) > 0 {
+		bufw.WriteString(`"extensions":`)
+		bufw.Write(extensions)
+		bufw.WriteRune(',')
+	}
+	if uids != nil {
+		bufw.WriteString(`"uids":`)
+		uids.encode(bufw)
+		bufw.WriteRune(',')
+	}
+	bufw.WriteString(`"features":[`)
+	for i, res := range fj.attrs {
+		if i > 0 {
+			bufw.WriteRune(',')
+		}
+		var geometry, id []byte
+		props := &fastJsonNode{}
+		for _, a := range res.attrs {
+			switch {
+			case a.geo != nil && geometry == nil:
+				geometry = a.scalarVal
+			case a.attr == "_uid_" && id == nil:
+				id = a.scalarVal
+			default:
+				props.attrs = append(props.attrs, a)
+			}
+		}
+		if geometry == nil {
+			geometry = []byte("null")
+		}
+
+		bufw.WriteString(`{"type":"Feature",`)
+		if id != nil {
+			bufw.WriteString(`"id":`)
+			bufw.Write(id)
+			bufw.WriteRune(',')
+		}
+		bufw.WriteString(`"geometry":`)
+		bufw.Write(geometry)
+		bufw.WriteString(`,"properties":`)
+		if len(props.attrs) == 0 {
+			bufw.WriteString(`{}`)
+		} else {
+			props.encode(bufw)
+		}
+		bufw.WriteRune('}')
+	}
+	bufw.WriteString(`]}`)
+	return bufw.Flush()
+}
+
 func merge(parent [][]*fastJsonNode, child [][]*fastJsonNode) ([][]*fastJsonNode, error) {
 	if len(parent) == 0 {
 		return child, nil
@@ -689,6 +797,11 @@ func processNodeUids(n *fastJsonNode, sg *SubGraph) error {
 		return nil
 	}
 
+	if sg.isDistinct() {
+		addDistinct(n, sg)
+		return nil
+	}
+
 	lenList := len(sg.uidMatrix[0].Uids)
 	for i := 0; i < lenList; i++ {
 		uid := sg.uidMatrix[0].Uids[i]
@@ -730,7 +843,8 @@ type Extensions struct {
 	Latency map[string]string `json:"server_latency"`
 }
 
-func (sg *SubGraph) toFastJSON(l *Latency, w io.Writer, allocIds map[string]string, addLatency bool) error {
+func (sg *SubGraph) toFastJSON(l *Latency, w io.Writer, allocIds map[string]string, addLatency bool,
+	geoFormat string) error {
 	var seedNode *fastJsonNode
 	var err error
 	n := seedNode.New("_root_")
@@ -741,14 +855,20 @@ func (sg *SubGraph) toFastJSON(l *Latency, w io.Writer, allocIds map[string]stri
 		}
 	}
 
+	if geoFormat == GeoWKT {
+		if err = n.(*fastJsonNode).geoToWKT(); err != nil {
+			return err
+		}
+	}
+
+	var uids *fastJsonNode
 	if allocIds != nil && len(allocIds) > 0 {
-		sl := seedNode.New("uids").(*fastJsonNode)
+		uids = seedNode.New("uids").(*fastJsonNode)
 		for k, v := range allocIds {
 			val := types.ValueForType(types.StringID)
 			val.Value = v
-			sl.AddValue(k, val)
+			uids.AddValue(k, val)
 		}
-		n.AddMapChild("uids", sl, false)
 	}
 
 	var lb []byte
@@ -761,6 +881,13 @@ func (sg *SubGraph) toFastJSON(l *Latency, w io.Writer, allocIds map[string]stri
 		}
 	}
 
+	if geoFormat == GeoFeatures {
+		return n.(*fastJsonNode).encodeFeatures(w, uids, lb)
+	}
+	if uids != nil {
+		n.AddMapChild("uids", uids, false)
+	}
+
 	// According to GraphQL spec response should only contain data, errors and extensions as top
 	// level keys. Hence we send server_latency under extensions key.
 	// https://facebook.github.io/graphql/#sec-Response-Format
@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package query
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/gql"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/worker"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// maxPathEdges is the number of edges a single path expression is allowed to
+// traverse before we give up with ErrTooBig.
+const maxPathEdges = 1000000
+
+// pathEval evaluates a regular path expression. All the lists it works on are
+// aligned with the SrcUIDs of the SubGraph, i.e. lists[i] holds the nodes
+// reached so far starting from SrcUIDs.Uids[i].
+type pathEval struct {
+	sg       *SubGraph
+	numEdges int
+}
+
+// expandPath fills the uidMatrix (and valueMatrix, if the path ends in a
+// scalar predicate) of a SubGraph which has a path expression as attribute.
+func (sg *SubGraph) expandPath(ctx context.Context) error {
+	pe := &pathEval{sg: sg}
+	src := make([]*protos.List, len(sg.SrcUIDs.Uids))
+	for i, uid := range sg.SrcUIDs.Uids {
+		src[i] = &protos.List{Uids: []uint64{uid}}
+	}
+
+	// The last step is fetched separately, so that we also get the values in
+	// case it is a scalar predicate.
+	prefix, last := splitLastStep(sg.PathExp)
+	if last == nil {
+		dest, err := pe.eval(ctx, sg.PathExp, src)
+		if err != nil {
+			return err
+		}
+		sg.uidMatrix = dest
+		sg.valueMatrix = emptyValueMatrix(len(dest))
+		return nil
+	}
+
+	var err error
+	if prefix != nil {
+		if src, err = pe.eval(ctx, prefix, src); err != nil {
+			return err
+		}
+	}
+	union := algo.MergeSorted(src)
+	result, err := pe.fetch(ctx, last.Attr, union, sg.Params.Langs)
+	if err != nil {
+		return err
+	}
+	if sg.uidMatrix, err = pe.gather(src, union, result.UidMatrix); err != nil {
+		return err
+	}
+	sg.valueMatrix = make([]*protos.ValueList, len(src))
+	for i, l := range src {
+		vl := &protos.ValueList{}
+		for _, uid := range l.Uids {
+			idx := algo.IndexOf(union, uid)
+			if idx >= len(result.ValueMatrix) {
+				continue
+			}
+			for _, tv := range result.ValueMatrix[idx].Values {
+				if !bytes.Equal(tv.Val, x.Nilbyte) {
+					vl.Values = append(vl.Values, tv)
+				}
+			}
+		}
+		if len(vl.Values) == 0 {
+			vl.Values = []*protos.TaskValue{{Val: x.Nilbyte}}
+		}
+		sg.valueMatrix[i] = vl
+	}
+	return nil
+}
+
+// splitLastStep returns the path without its last step, and the last step if
+// it is a plain predicate.
+func splitLastStep(t *gql.PathTree) (*gql.PathTree, *gql.PathTree) {
+	if t.Op == "" {
+		return nil, t
+	}
+	if t.Op != gql.PathSeq {
+		return t, nil
+	}
+	last := t.Child[len(t.Child)-1]
+	if last.Op != "" {
+		return t, nil
+	}
+	if len(t.Child) == 2 {
+		return t.Child[0], last
+	}
+	return &gql.PathTree{Op: gql.PathSeq, Child: t.Child[:len(t.Child)-1]}, last
+}
+
+func emptyValueMatrix(n int) []*protos.ValueList {
+	out := make([]*protos.ValueList, n)
+	for i := range out {
+		out[i] = &protos.ValueList{Values: []*protos.TaskValue{{Val: x.Nilbyte}}}
+	}
+	return out
+}
+
+func (pe *pathEval) eval(ctx context.Context, t *gql.PathTree,
+	src []*protos.List) ([]*protos.List, error) {
+	switch t.Op {
+	case "":
+		union := algo.MergeSorted(src)
+		result, err := pe.fetch(ctx, t.Attr, union, nil)
+		if err != nil {
+			return nil, err
+		}
+		return pe.gather(src, union, result.UidMatrix)
+	case gql.PathSeq:
+		var err error
+		for _, c := range t.Child {
+			if src, err = pe.eval(ctx, c, src); err != nil {
+				return nil, err
+			}
+		}
+		return src, nil
+	case gql.PathAlt:
+		out := make([]*protos.List, len(src))
+		for _, c := range t.Child {
+			dest, err := pe.eval(ctx, c, src)
+			if err != nil {
+				return nil, err
+			}
+			for i := range out {
+				out[i] = algo.MergeSorted([]*protos.List{out[i], dest[i]})
+			}
+		}
+		return out, nil
+	case gql.PathRepeat:
+		return pe.repeat(ctx, t, src)
+	}
+	return nil, x.Errorf("Unknown operator in path expression: %v", t.Op)
+}
+
+// repeat evaluates t.Child[0] between t.Min and t.Max times. Once we are past
+// t.Min repetitions, nodes which were already reached aren't expanded again,
+// so that unbounded repetitions stop on cycles.
+func (pe *pathEval) repeat(ctx context.Context, t *gql.PathTree,
+	src []*protos.List) ([]*protos.List, error) {
+	reached := make([]*protos.List, len(src))
+	for i := range reached {
+		if t.Min == 0 {
+			reached[i] = src[i]
+		} else {
+			reached[i] = &protos.List{}
+		}
+	}
+
+	cur := src
+	for n := 1; t.Max < 0 || n <= t.Max; n++ {
+		if isEmptyLists(cur) {
+			break
+		}
+		dest, err := pe.eval(ctx, t.Child[0], cur)
+		if err != nil {
+			return nil, err
+		}
+		if n < t.Min {
+			cur = dest
+			continue
+		}
+		for i := range dest {
+			fresh := algo.Difference(dest[i], reached[i])
+			reached[i] = algo.MergeSorted([]*protos.List{reached[i], dest[i]})
+			dest[i] = fresh
+		}
+		cur = dest
+	}
+	return reached, nil
+}
+
+// fetch gets the postings of the predicate (reverse ones if it starts with ~)
+// for all the given uids.
+func (pe *pathEval) fetch(ctx context.Context, attr string, uids *protos.List,
+	langs []string) (*protos.Result, error) {
+	if len(uids.Uids) == 0 {
+		return &protos.Result{}, nil
+	}
+	reverse := strings.HasPrefix(attr, "~")
+	q := &protos.Query{
+		Attr:    strings.TrimPrefix(attr, "~"),
+		Langs:   langs,
+		Reverse: reverse,
+		UidList: uids,
+	}
+	return worker.ProcessTaskOverNetwork(ctx, q)
+}
+
+// gather maps the uidMatrix of a fetch over union back to the lists in src.
+func (pe *pathEval) gather(src []*protos.List, union *protos.List,
+	matrix []*protos.List) ([]*protos.List, error) {
+	out := make([]*protos.List, len(src))
+	for i, l := range src {
+		lists := make([]*protos.List, 0, len(l.Uids))
+		for _, uid := range l.Uids {
+			idx := algo.IndexOf(union, uid)
+			if idx < 0 || idx >= len(matrix) {
+				continue
+			}
+			pe.numEdges += len(matrix[idx].Uids)
+			lists = append(lists, matrix[idx])
+		}
+		if pe.numEdges > maxPathEdges {
+			return nil, ErrTooBig
+		}
+		out[i] = algo.MergeSorted(lists)
+	}
+	return out, nil
+}
+
+func isEmptyLists(lists []*protos.List) bool {
+	for _, l := range lists {
+		if len(l.Uids) > 0 {
+			return false
+		}
+	}
+	return true
+}
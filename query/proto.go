@@ -73,6 +73,9 @@ func toProtoValue(v types.Val) *protos.Value {
 	case types.DefaultID:
 		return &protos.Value{&protos.Value_DefaultVal{v.Value.(string)}}
 
+	case types.Float32VectorID:
+		return &protos.Value{&protos.Value_StrVal{types.FormatVector(v.Value.([]float32))}}
+
 	default:
 		// A type that isn't supported in the proto
 		return nil
@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
@@ -126,6 +127,10 @@ type params struct {
 	DoCount    bool
 	GetUid     bool
 	Order      []*protos.Order
+	Nulls      string // Whether nodes without the value are sorted first or last.
+	Random     int    // Number of uids to pick randomly, all if zero.
+	Seed       int64
+	HasSeed    bool
 	Var        string
 	NeedsVar   []gql.VarContext
 	ParentVars map[string]varValue
@@ -134,9 +139,10 @@ type params struct {
 	Langs      []string
 
 	// directives.
-	Normalize    bool
-	Cascade      bool
-	IgnoreReflex bool
+	Normalize     bool
+	Cascade       bool
+	CascadeFields []string // Required children for cascade. All, if empty.
+	IgnoreReflex  bool
 
 	From           uint64
 	To             uint64
@@ -149,6 +155,10 @@ type params struct {
 	Expand         string // Var to use for expand.
 	isGroupBy      bool
 	groupbyAttrs   []gql.AttrLang
+	groupbyOrder   []*protos.Order
+	groupbyHaving  *gql.FilterTree // Filter on the groups.
+	groupbyCount   int             // Number of groups to return, all if zero.
+	groupbyOffset  int
 	uidCount       string
 	numPaths       int
 	parentIds      []uint64 // This is a stack that is maintained and passed down to children.
@@ -175,6 +185,9 @@ type SubGraph struct {
 	facetsMatrix []*protos.FacetsList
 	ExpandPreds  []*protos.ValueList
 	GroupbyRes   *groupResults
+	DistinctRes  []distinctValue
+	// Set for a distance function at root, to order the results by distance.
+	distances map[uint64]types.Val
 
 	// SrcUIDs is a list of unique source UIDs. They are always copies of destUIDs
 	// of parent nodes in GraphQL structure.
@@ -185,6 +198,7 @@ type SubGraph struct {
 	Filters      []*SubGraph
 	facetsFilter *protos.FilterTree
 	MathExp      *mathTree
+	PathExp      *gql.PathTree // Set if Attr is a regular path expression.
 	Children     []*SubGraph
 
 	// destUIDs is a list of destination UIDs, after applying filters, pagination.
@@ -322,12 +336,21 @@ func addCount(pc *SubGraph, count uint64, dst outputNode) {
 	dst.AddValue(fieldName, c)
 }
 
+// aggFieldName returns the name under which the result of the aggregator f
+// over in is output, e.g. sum(val(a)) or percentile(age,95).
+func aggFieldName(f *Function, in string) string {
+	if len(f.Args) > 0 {
+		return fmt.Sprintf("%s(%s,%s)", f.Name, in, f.Args[0].Value)
+	}
+	return fmt.Sprintf("%s(%s)", f.Name, in)
+}
+
 func aggWithVarFieldName(pc *SubGraph) string {
 	fieldName := fmt.Sprintf("val(%v)", pc.Params.Var)
 	if len(pc.Params.NeedsVar) > 0 {
 		fieldName = fmt.Sprintf("val(%v)", pc.Params.NeedsVar[0].Name)
 		if pc.SrcFunc != nil {
-			fieldName = fmt.Sprintf("%s(%v)", pc.SrcFunc.Name, fieldName)
+			fieldName = aggFieldName(pc.SrcFunc, fieldName)
 		}
 	}
 	if pc.Params.Alias != "" {
@@ -360,6 +383,19 @@ func addCheckPwd(pc *SubGraph, val *protos.TaskValue, dst outputNode) {
 	dst.AddListChild(pc.Attr, uc)
 }
 
+func addTextScore(pc *SubGraph, val *protos.TaskValue, dst outputNode) {
+	sv, err := convertWithBestEffort(val, pc.Attr)
+	if err != nil {
+		// The value didn't match any of the terms.
+		return
+	}
+	fieldName := fmt.Sprintf("bm25(%s)", pc.Attr)
+	if pc.Params.Alias != "" {
+		fieldName = pc.Params.Alias
+	}
+	dst.AddValue(fieldName, sv)
+}
+
 func alreadySeen(parentIds []uint64, uid uint64) bool {
 	for _, id := range parentIds {
 		if id == uid {
@@ -429,6 +465,10 @@ func (sg *SubGraph) preTraverse(uid uint64, dst outputNode) error {
 			addCount(pc, uint64(pc.counts[idx]), dst)
 		} else if pc.SrcFunc != nil && pc.SrcFunc.Name == "checkpwd" {
 			addCheckPwd(pc, pc.valueMatrix[idx].Values[0], dst)
+		} else if pc.SrcFunc != nil && pc.SrcFunc.Name == "bm25" {
+			addTextScore(pc, pc.valueMatrix[idx].Values[0], dst)
+		} else if pc.SrcFunc != nil && pc.SrcFunc.Name == "distance" {
+			addDistance(pc, pc.valueMatrix[idx].Values[0], dst)
 		} else if len(ul.Uids) > 0 {
 			var fcsList []*protos.Facets
 			if pc.Params.Facet != nil {
@@ -595,7 +635,7 @@ func mathCopy(dst *mathTree, src *gql.MathTree) error {
 	return nil
 }
 
-func filterCopy(sg *SubGraph, ft *gql.FilterTree) error {
+func filterCopy(ctx context.Context, sg *SubGraph, ft *gql.FilterTree) error {
 	// Either we'll have an operation specified, or the function specified.
 	if len(ft.Op) > 0 {
 		sg.FilterOp = ft.Op
@@ -604,6 +644,12 @@ func filterCopy(sg *SubGraph, ft *gql.FilterTree) error {
 		if !isValidFuncName(ft.Func.Name) {
 			return x.Errorf("Invalid function name : %s", ft.Func.Name)
 		}
+		if ft.Func.Name == "join" {
+			return x.Errorf("join is not supported inside filter. Use eq with a value variable")
+		}
+		if ft.Func.Name == "distinct" {
+			return x.Errorf("distinct function is only supported at root")
+		}
 
 		isUidFuncWithoutVar := isUidFnWithoutVar(ft.Func)
 		if isUidFuncWithoutVar {
@@ -616,10 +662,15 @@ func filterCopy(sg *SubGraph, ft *gql.FilterTree) error {
 			sg.createSrcFunction(ft.Func)
 			sg.Params.NeedsVar = append(sg.Params.NeedsVar, ft.Func.NeedsVar...)
 		}
+		if ft.Exists != nil {
+			if err := treeCopy(ctx, ft.Exists, sg); err != nil {
+				return err
+			}
+		}
 	}
 	for _, ftc := range ft.Child {
 		child := &SubGraph{}
-		if err := filterCopy(child, ftc); err != nil {
+		if err := filterCopy(ctx, child, ftc); err != nil {
 			return err
 		}
 		sg.Filters = append(sg.Filters, child)
@@ -671,6 +722,10 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 	// So, we work on the children, and then recurse for grand children.
 	attrsSeen := make(map[string]struct{})
 
+	if err := checkCascadeFields(gq, sg.Params.CascadeFields); err != nil {
+		return err
+	}
+
 	for _, gchild := range gq.Children {
 		if (sg.Params.Alias == "shortest" || sg.Params.Alias == "recurse") &&
 			gchild.Expand != "" {
@@ -701,7 +756,7 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 			groupbyAttrs:   gchild.GroupbyAttrs,
 			FacetVar:       gchild.FacetVar,
 			uidCount:       gchild.UidCount,
-			Cascade:        sg.Params.Cascade,
+			Cascade:        sg.Params.cascadeTo(gchild),
 			FacetOrder:     gchild.FacetOrder,
 			FacetOrderDesc: gchild.FacetDesc,
 			IgnoreReflex:   sg.Params.IgnoreReflex,
@@ -727,6 +782,10 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 		if err := args.fill(gchild); err != nil {
 			return err
 		}
+		if gchild.Cascade {
+			args.Cascade = true
+			args.CascadeFields = gchild.CascadeFields
+		}
 
 		if len(args.Order) != 0 && len(args.FacetOrder) != 0 {
 			return x.Errorf("Cannot specify order at both args and facets")
@@ -736,6 +795,13 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 			Attr:   gchild.Attr,
 			Params: args,
 		}
+		if gchild.Path != nil {
+			if args.DoCount || args.Facet != nil || gchild.FacetsFilter != nil {
+				return x.Errorf("Count and facets are not supported on path expression: %s",
+					gchild.Attr)
+			}
+			dst.PathExp = gchild.Path
+		}
 		if gchild.MathExp != nil {
 			mathExp := &mathTree{}
 			if err := mathCopy(mathExp, gchild.MathExp); err != nil {
@@ -745,7 +811,8 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 		}
 
 		if gchild.Func != nil &&
-			(gchild.Func.IsAggregator() || gchild.Func.IsPasswordVerifier()) {
+			(gchild.Func.IsAggregator() || gchild.Func.IsPasswordVerifier() ||
+				gchild.Func.IsTextScore() || gchild.Func.IsDistance()) {
 			f := gchild.Func.Name
 			if len(gchild.Children) != 0 {
 				note := fmt.Sprintf("Node with %q cant have child attr", f)
@@ -762,10 +829,13 @@ func treeCopy(ctx context.Context, gq *gql.GraphQuery, sg *SubGraph) error {
 			}
 			dst.createSrcFunction(gchild.Func)
 		}
+		if gchild.Func != nil && gchild.Func.Name == "join" {
+			dst.createSrcFunction(gchild.Func)
+		}
 
 		if gchild.Filter != nil {
 			dstf := &SubGraph{}
-			if err := filterCopy(dstf, gchild.Filter); err != nil {
+			if err := filterCopy(ctx, dstf, gchild.Filter); err != nil {
 				return err
 			}
 			dst.Filters = append(dst.Filters, dstf)
@@ -838,6 +908,55 @@ func (args *params) fill(gq *gql.GraphQuery) error {
 		}
 		args.Count = int(first)
 	}
+	if v, ok := gq.Args["random"]; ok {
+		random, err := strconv.ParseInt(v, 0, 32)
+		if err != nil {
+			return err
+		}
+		if random <= 0 {
+			return x.Errorf("Expected random to be a positive number. Got: %s", v)
+		}
+		args.Random = int(random)
+	}
+	if v, ok := gq.Args["seed"]; ok {
+		if args.Random == 0 {
+			return x.Errorf("seed can only be used along with random")
+		}
+		seed, err := strconv.ParseInt(v, 0, 64)
+		if err != nil {
+			return err
+		}
+		args.Seed = seed
+		args.HasSeed = true
+	}
+	if v, ok := gq.Args["nulls"]; ok {
+		if v != "first" && v != "last" {
+			return x.Errorf("Expected nulls to be first or last. Got: %s", v)
+		}
+		if len(gq.Order) == 0 {
+			return x.Errorf("nulls can only be used along with orderasc or orderdesc")
+		}
+		args.Nulls = v
+	}
+	if gq.GroupbyHaving != nil && !gq.IsGroupby {
+		return x.Errorf("@having can only be used along with @groupby")
+	}
+	args.groupbyOrder = gq.GroupbyOrder
+	args.groupbyHaving = gq.GroupbyHaving
+	if v, ok := gq.GroupbyArgs["first"]; ok {
+		first, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		args.groupbyCount = first
+	}
+	if v, ok := gq.GroupbyArgs["offset"]; ok {
+		offset, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		args.groupbyOffset = offset
+	}
 	return nil
 }
 
@@ -879,20 +998,21 @@ func newGraph(ctx context.Context, gq *gql.GraphQuery) (*SubGraph, error) {
 	// For the root, the name to be used in result is stored in Alias, not Attr.
 	// The attr at root (if present) would stand for the source functions attr.
 	args := params{
-		GetUid:       isDebug(ctx),
-		Alias:        gq.Alias,
-		Langs:        gq.Langs,
-		Var:          gq.Var,
-		ParentVars:   make(map[string]varValue),
-		Normalize:    gq.Normalize,
-		Cascade:      gq.Cascade,
-		isGroupBy:    gq.IsGroupby,
-		groupbyAttrs: gq.GroupbyAttrs,
-		uidCount:     gq.UidCount,
-		IgnoreReflex: gq.IgnoreReflex,
-		IsEmpty:      gq.IsEmpty,
-		Order:        gq.Order,
-		upsert:       gq.Upsert,
+		GetUid:        isDebug(ctx),
+		Alias:         gq.Alias,
+		Langs:         gq.Langs,
+		Var:           gq.Var,
+		ParentVars:    make(map[string]varValue),
+		Normalize:     gq.Normalize,
+		Cascade:       gq.Cascade,
+		CascadeFields: gq.CascadeFields,
+		isGroupBy:     gq.IsGroupby,
+		groupbyAttrs:  gq.GroupbyAttrs,
+		uidCount:      gq.UidCount,
+		IgnoreReflex:  gq.IgnoreReflex,
+		IsEmpty:       gq.IsEmpty,
+		Order:         gq.Order,
+		upsert:        gq.Upsert,
 	}
 	if gq.Facets != nil {
 		args.Facet = &protos.Param{gq.Facets.AllKeys, gq.Facets.Keys}
@@ -936,7 +1056,7 @@ func newGraph(ctx context.Context, gq *gql.GraphQuery) (*SubGraph, error) {
 	// Copy roots filter.
 	if gq.Filter != nil {
 		sgf := &SubGraph{}
-		if err := filterCopy(sgf, gq.Filter); err != nil {
+		if err := filterCopy(ctx, sgf, gq.Filter); err != nil {
 			return nil, err
 		}
 		sg.Filters = append(sg.Filters, sgf)
@@ -1042,8 +1162,9 @@ func evalLevelAgg(doneVars map[string]varValue, sg, parent *SubGraph) (mp map[ui
 			return mp, nil
 		}
 
-		ag := aggregator{
-			name: sg.SrcFunc.Name,
+		ag, err := newAggregator(sg.SrcFunc)
+		if err != nil {
+			return mp, err
 		}
 		for _, val := range vals {
 			ag.Apply(val)
@@ -1085,8 +1206,9 @@ func evalLevelAgg(doneVars map[string]varValue, sg, parent *SubGraph) (mp map[ui
 	mp = make(map[uint64]types.Val)
 	// Go over the sibling node and aggregate.
 	for i, list := range relSG.uidMatrix {
-		ag := aggregator{
-			name: sg.SrcFunc.Name,
+		ag, err := newAggregator(sg.SrcFunc)
+		if err != nil {
+			return mp, err
 		}
 		for _, uid := range list.Uids {
 			if val, ok := vals[uid]; ok {
@@ -1321,7 +1443,7 @@ func (sg *SubGraph) updateFacetMatrix() {
 func (sg *SubGraph) updateUidMatrix() {
 	sg.updateFacetMatrix()
 	for _, l := range sg.uidMatrix {
-		if len(sg.Params.Order) > 0 {
+		if len(sg.Params.Order) > 0 || sg.distances != nil {
 			// We can't do intersection directly as the list is not sorted by UIDs.
 			// So do filter.
 			algo.ApplyFilter(l, func(uid uint64, idx int) bool {
@@ -1358,7 +1480,7 @@ func (sg *SubGraph) populateVarMap(doneVars map[string]varValue,
 			return err
 		}
 		sgPath = sgPath[:len(sgPath)-1] // Backtrack
-		if !sg.Params.Cascade {
+		if !sg.Params.Cascade && !child.Params.Cascade {
 			continue
 		}
 
@@ -1377,7 +1499,7 @@ func (sg *SubGraph) populateVarMap(doneVars map[string]varValue,
 		for _, child := range sg.Children {
 			// For _uid_ we dont actually populate the uidMatrix or values. So a node asking for
 			// _uid_ would always be excluded. Therefore we skip it.
-			if child.Attr == "_uid_" {
+			if child.Attr == "_uid_" || !sg.Params.isCascadeField(child.Attr, child.Params.Alias) {
 				continue
 			}
 
@@ -1404,6 +1526,54 @@ AssignStep:
 	return sg.assignVars(doneVars, sgPath)
 }
 
+// isCascadeField returns true if a child with the given attr and alias is
+// required by the cascade directive.
+func (p *params) isCascadeField(attr, alias string) bool {
+	if len(p.CascadeFields) == 0 {
+		return true
+	}
+	for _, f := range p.CascadeFields {
+		if f == attr || (alias != "" && f == alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCascadeFields returns an error if a field of @cascade(...) doesn't name
+// a child of gq, by predicate or alias. The children given by expand() aren't
+// known yet, so the fields aren't checked then.
+func checkCascadeFields(gq *gql.GraphQuery, fields []string) error {
+	for _, gchild := range gq.Children {
+		if gchild.Expand != "" {
+			return nil
+		}
+	}
+	for _, f := range fields {
+		found := false
+		for _, gchild := range gq.Children {
+			if f == gchild.Attr || (gchild.Alias != "" && f == gchild.Alias) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			name := gq.Attr
+			if gq.Alias != "" {
+				name = gq.Alias
+			}
+			return x.Errorf("Field %s in @cascade isn't a child of %s", f, name)
+		}
+	}
+	return nil
+}
+
+// cascadeTo returns whether the cascade should be propagated to the child.
+// With @cascade(name, email) only the listed children are cascaded.
+func (p *params) cascadeTo(gchild *gql.GraphQuery) bool {
+	return p.Cascade && p.isCascadeField(gchild.Attr, gchild.Alias)
+}
+
 func (sg *SubGraph) assignVars(doneVars map[string]varValue, sgPath []*SubGraph) error {
 	if doneVars == nil || (sg.Params.Var == "" && sg.Params.FacetVar == nil) {
 		return nil
@@ -1585,7 +1755,9 @@ func (sg *SubGraph) fillVars(mp map[string]varValue) error {
 // that as of now.
 func (sg *SubGraph) replaceVarInFunc() error {
 	// Attr would be set to val if the query is of type eq(val(myscore), 35)
-	if sg.SrcFunc == nil || sg.Attr == "val" {
+	// A join needs to know which value belongs to which uid, so it uses
+	// uidToVal directly.
+	if sg.SrcFunc == nil || sg.Attr == "val" || sg.SrcFunc.Name == "join" {
 		return nil
 	}
 	var args []gql.Arg
@@ -1689,6 +1861,8 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 		return
 	}
 	var err error
+	// The lookup and filters of sg, which may use composite indexes.
+	lookup, filters := sg.useCompositeIndexes(parent == nil)
 	if parent == nil && sg.SrcFunc != nil && sg.SrcFunc.Name == "uid" {
 		// I'm root and I'm using some variable that has been populated.
 		// Retain the actual order in uidMatrix. But sort the destUids.
@@ -1705,6 +1879,11 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 			sort.Slice(sg.DestUIDs.Uids, func(i, j int) bool { return sg.DestUIDs.Uids[i] < sg.DestUIDs.Uids[j] })
 		}
 	} else if len(sg.Attr) == 0 {
+		if sg.SrcFunc != nil && sg.SrcFunc.Name == "exists" {
+			// This is an exists { ... } filter, its children hold the block.
+			rch <- sg.evaluateExists(ctx)
+			return
+		}
 		// This is when we have uid function in children.
 		if sg.SrcFunc != nil && sg.SrcFunc.Name == "uid" {
 			// If its a uid() filter, we just have to intersect the SrcUIDs with DestUIDs
@@ -1734,8 +1913,41 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 				rch <- err
 				return
 			}
+		} else if sg.isDistinct() {
+			if parent != nil {
+				rch <- x.Errorf("distinct function is only supported at root")
+				return
+			}
+			if len(sg.Filters) > 0 {
+				rch <- x.Errorf("distinct function doesn't support filters")
+				return
+			}
+			rch <- sg.evaluateDistinct(ctx)
+			return
+		} else if sg.SrcFunc != nil && sg.SrcFunc.Name == "distance" {
+			if err = sg.evaluateDistance(parent); err != nil {
+				rch <- err
+				return
+			}
+		} else if sg.SrcFunc != nil && sg.SrcFunc.Name == "join" {
+			if err = sg.evaluateJoin(ctx, parent == nil); err != nil {
+				if tr, ok := trace.FromContext(ctx); ok {
+					tr.LazyPrintf("Error while processing join: %+v", err)
+				}
+				rch <- err
+				return
+			}
+		} else if sg.PathExp != nil {
+			if err = sg.expandPath(ctx); err != nil {
+				if tr, ok := trace.FromContext(ctx); ok {
+					tr.LazyPrintf("Error while processing path expression: %+v", err)
+				}
+				rch <- err
+				return
+			}
+			sg.DestUIDs = algo.MergeSorted(sg.uidMatrix)
 		} else {
-			taskQuery, err := createTaskQuery(sg)
+			taskQuery, err := createTaskQuery(lookup)
 			if err != nil {
 				if tr, ok := trace.FromContext(ctx); ok {
 					tr.LazyPrintf("Error while processing task: %+v", err)
@@ -1757,8 +1969,15 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 			sg.facetsMatrix = result.FacetMatrix
 			sg.counts = result.Counts
 
+			if parent == nil && sg.SrcFunc != nil && isDistanceFunc(sg.SrcFunc.Name) {
+				if err = sg.setDistances(result); err != nil {
+					rch <- err
+					return
+				}
+			}
+
 			if sg.Params.DoCount {
-				if len(sg.Filters) == 0 {
+				if len(filters) == 0 {
 					// If there is a filter, we need to do more work to get the actual count.
 					if tr, ok := trace.FromContext(ctx); ok {
 						tr.LazyPrintf("Zero uids. Only count requested")
@@ -1800,10 +2019,10 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 	}
 
 	// Run filters if any.
-	if len(sg.Filters) > 0 {
+	if len(filters) > 0 {
 		// Run all filters in parallel.
-		filterChan := make(chan error, len(sg.Filters))
-		for _, filter := range sg.Filters {
+		filterChan := make(chan error, len(filters))
+		for _, filter := range filters {
 			isUidFuncWithoutVar := filter.SrcFunc != nil && filter.SrcFunc.Name == "uid" &&
 				len(filter.Params.NeedsVar) == 0
 			// For uid function filter, no need for processing. User already gave us the
@@ -1821,7 +2040,7 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 		}
 
 		var filterErr error
-		for range sg.Filters {
+		for range filters {
 			if err = <-filterChan; err != nil {
 				// Store error in a variable and wait for all filters to run
 				// before returning. Else tracing causes crashes.
@@ -1839,14 +2058,14 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 
 		// Now apply the results from filter.
 		var lists []*protos.List
-		for _, filter := range sg.Filters {
+		for _, filter := range filters {
 			lists = append(lists, filter.DestUIDs)
 		}
 		if sg.FilterOp == "or" {
 			sg.DestUIDs = algo.MergeSorted(lists)
 		} else if sg.FilterOp == "not" {
-			x.AssertTrue(len(sg.Filters) == 1)
-			sg.DestUIDs = algo.Difference(sg.DestUIDs, sg.Filters[0].DestUIDs)
+			x.AssertTrue(len(filters) == 1)
+			sg.DestUIDs = algo.Difference(sg.DestUIDs, filters[0].DestUIDs)
 		} else if sg.FilterOp == "and" {
 			sg.DestUIDs = algo.IntersectSorted(lists)
 		} else {
@@ -1861,9 +2080,17 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 		}
 	}
 
+	// Sampling is done after filtering, so that the sample is picked out of
+	// the uids which match.
+	sg.applyRandom()
+
 	if len(sg.Params.Order) == 0 && len(sg.Params.FacetOrder) == 0 {
 		// There is no ordering. Just apply pagination and return.
-		if err = sg.applyPagination(ctx); err != nil {
+		if sg.distances != nil {
+			// Unless the results are from a distance function, which come
+			// closest first.
+			sg.applyDistanceOrderAndPagination()
+		} else if err = sg.applyPagination(ctx); err != nil {
 			rch <- err
 			return
 		}
@@ -1892,7 +2119,7 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 	// should return a count of 0, not 10.
 	// take care of the order
 	if sg.Params.DoCount {
-		x.AssertTrue(len(sg.Filters) > 0)
+		x.AssertTrue(len(filters) > 0)
 		sg.counts = make([]uint32, len(sg.uidMatrix))
 		sg.updateUidMatrix()
 		for i, ul := range sg.uidMatrix {
@@ -1955,13 +2182,18 @@ func ProcessGraph(ctx context.Context, sg, parent *SubGraph, rch chan error) {
 		// Add the attrs required by groupby nodes
 		for _, it := range sg.Params.groupbyAttrs {
 			// TODO - Throw error if Attr is of list type.
-			sg.Children = append(sg.Children, &SubGraph{
-				Attr: it.Attr,
+			child := &SubGraph{
+				Attr:    it.Attr,
+				PathExp: it.Path,
 				Params: params{
 					ignoreResult: true,
 					Langs:        it.Langs,
 				},
-			})
+			}
+			if it.Facet != "" {
+				child.Params.Facet = &protos.Param{Keys: []string{it.Facet}}
+			}
+			sg.Children = append(sg.Children, child)
 		}
 	}
 
@@ -2016,6 +2248,40 @@ func (sg *SubGraph) applyPagination(ctx context.Context) error {
 	return nil
 }
 
+// applyRandom replaces each posting list by a random sample of its uids.
+func (sg *SubGraph) applyRandom() {
+	if sg.Params.Random == 0 {
+		return
+	}
+	seed := sg.Params.Seed
+	if !sg.Params.HasSeed {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	sg.updateUidMatrix()
+	for i, ul := range sg.uidMatrix {
+		sample := algo.Sample(ul, sg.Params.Random, r)
+		if sg.Params.Facet != nil {
+			// The sampled uids keep their order, so their facets are picked
+			// along with them.
+			fl := sg.facetsMatrix[i].FacetsList
+			out := fl[:0]
+			j := 0
+			for idx, uid := range ul.Uids {
+				if j < len(sample.Uids) && sample.Uids[j] == uid {
+					out = append(out, fl[idx])
+					j++
+				}
+			}
+			sg.facetsMatrix[i].FacetsList = out
+		}
+		sg.uidMatrix[i] = sample
+	}
+	// Re-merge the UID matrix.
+	sg.DestUIDs = algo.MergeSorted(sg.uidMatrix)
+}
+
 // applyOrderAndPagination orders each posting list by a given attribute
 // before applying pagination.
 func (sg *SubGraph) applyOrderAndPagination(ctx context.Context) error {
@@ -2053,12 +2319,31 @@ func (sg *SubGraph) applyOrderAndPagination(ctx context.Context) error {
 		Offset:    int32(sg.Params.Offset),
 		Count:     int32(sg.Params.Count),
 	}
+	if sg.Params.Nulls != "" {
+		// The nodes without a value are added back after sorting, so all of
+		// them are sorted and pagination is applied afterwards.
+		var maxLen int
+		for _, ul := range sg.uidMatrix {
+			if len(ul.Uids) > maxLen {
+				maxLen = len(ul.Uids)
+			}
+		}
+		sort.Offset = 0
+		sort.Count = int32(maxLen)
+	}
 	result, err := worker.SortOverNetwork(ctx, sort)
 	if err != nil {
 		return err
 	}
 
 	x.AssertTrue(len(result.UidMatrix) == len(sg.uidMatrix))
+	if sg.Params.Nulls != "" {
+		for i, ul := range result.UidMatrix {
+			uids := addMissingUids(ul.Uids, sg.uidMatrix[i].Uids, sg.Params.Nulls == "first")
+			start, end := x.PageRange(sg.Params.Count, sg.Params.Offset, len(uids))
+			ul.Uids = uids[start:end]
+		}
+	}
 	sg.uidMatrix = result.UidMatrix
 	// Update the destUids as we might have removed some UIDs for which we didn't find any values
 	// while sorting.
@@ -2066,6 +2351,25 @@ func (sg *SubGraph) applyOrderAndPagination(ctx context.Context) error {
 	return nil
 }
 
+// addMissingUids adds the uids of all which aren't in sorted, in the order of
+// all, before or after the sorted ones.
+func addMissingUids(sorted, all []uint64, first bool) []uint64 {
+	present := make(map[uint64]struct{}, len(sorted))
+	for _, uid := range sorted {
+		present[uid] = struct{}{}
+	}
+	missing := make([]uint64, 0, len(all)-len(sorted))
+	for _, uid := range all {
+		if _, ok := present[uid]; !ok {
+			missing = append(missing, uid)
+		}
+	}
+	if first {
+		return append(missing, sorted...)
+	}
+	return append(sorted, missing...)
+}
+
 func (sg *SubGraph) updateDestUids() {
 	// Update sg.destUID. Iterate over the UID matrix (which is not sorted by
 	// UID). For each element in UID matrix, we do a binary search in the
@@ -2158,6 +2462,10 @@ func (sg *SubGraph) sortAndPaginateUsingVar(ctx context.Context) error {
 		if err := types.Sort(values, &protos.List{uids}, []bool{sg.Params.Order[0].Desc}); err != nil {
 			return err
 		}
+		if sg.Params.Nulls != "" {
+			// The nodes without a value are kept before or after the others.
+			uids = addMissingUids(uids, ul.Uids, sg.Params.Nulls == "first")
+		}
 		sg.uidMatrix[i].Uids = uids
 	}
 
@@ -2177,7 +2485,8 @@ func (sg *SubGraph) sortAndPaginateUsingVar(ctx context.Context) error {
 // isValidArg checks if arg passed is valid keyword.
 func isValidArg(a string) bool {
 	switch a {
-	case "numpaths", "from", "to", "orderasc", "orderdesc", "first", "offset", "after", "depth":
+	case "numpaths", "from", "to", "orderasc", "orderdesc", "nulls", "first", "offset", "after",
+		"depth", "random", "seed":
 		return true
 	}
 	return false
@@ -2187,7 +2496,8 @@ func isValidArg(a string) bool {
 func isValidFuncName(f string) bool {
 	switch f {
 	case "anyofterms", "allofterms", "val", "regexp", "anyoftext", "alloftext",
-		"has", "uid", "uid_in":
+		"has", "uid", "uid_in", "exists", "join", "distinct", "phrase", "near_words",
+		"prefix", "match", "icontains", "sounds_like", "similar_to", "knn":
 		return true
 	}
 	return isCompareFn(f) || types.IsGeoFunc(f)
@@ -2211,7 +2521,8 @@ func isInequalityFn(f string) bool {
 
 func isAggregatorFn(f string) bool {
 	switch f {
-	case "min", "max", "sum", "avg":
+	case "min", "max", "sum", "avg", "median", "percentile", "stddev", "variance",
+		"count_distinct", "approx_count_distinct":
 		return true
 	}
 	return false
@@ -2249,7 +2560,11 @@ func GetAllPredicates(subGraphs []*SubGraph) (predicates []string) {
 }
 
 func (sg *SubGraph) getAllPredicates(predicates map[string]bool) {
-	if len(sg.Attr) != 0 {
+	if sg.PathExp != nil {
+		for _, attr := range sg.PathExp.Attrs() {
+			predicates[attr] = true
+		}
+	} else if len(sg.Attr) != 0 {
 		predicates[sg.Attr] = true
 	}
 	if len(sg.Params.Order) != 0 {
@@ -2259,6 +2574,12 @@ func (sg *SubGraph) getAllPredicates(predicates map[string]bool) {
 	}
 	if len(sg.Params.groupbyAttrs) != 0 {
 		for _, pred := range sg.Params.groupbyAttrs {
+			if pred.Path != nil {
+				for _, attr := range pred.Path.Attrs() {
+					predicates[attr] = true
+				}
+				continue
+			}
 			predicates[pred.Attr] = true
 		}
 	}
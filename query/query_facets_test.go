@@ -18,6 +18,8 @@
 package query
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -1219,3 +1221,49 @@ func TestFilterUidFacetMismatch(t *testing.T) {
 	js := processToFastJSON(t, query)
 	require.JSONEq(t, `{"data": {"me":[{"friend":[{"name":"Glenn Rhee","@facets":{"_":{"close":true,"family":true,"since":"2004-05-02T15:04:05Z","tag":"Domain3"}}},{"@facets":{"_":{"age":33,"close":true,"family":false,"since":"2005-05-02T15:04:05Z"}}}]}]}}`, js)
 }
+
+func TestRandomFacets(t *testing.T) {
+	populateGraphWithFacets(t)
+	defer teardownGraphWithFacets(t)
+	since := map[string]string{
+		"0x17": "2006-01-02T15:04:05Z",
+		"0x18": "2004-05-02T15:04:05Z",
+		"0x19": "2007-05-02T15:04:05Z",
+		"0x1f": "2006-01-02T15:04:05Z",
+		"0x65": "2005-05-02T15:04:05Z",
+	}
+	for seed := 1; seed <= 5; seed++ {
+		query := fmt.Sprintf(`
+		{
+			me(func: uid(0x1)) {
+				friend(random: 2, seed: %d) @facets(since) {
+					_uid_
+				}
+			}
+		}
+		`, seed)
+		js := processToFastJSON(t, query)
+		var res struct {
+			Data struct {
+				Me []struct {
+					Friend []struct {
+						Uid    string `json:"_uid_"`
+						Facets struct {
+							Edge struct {
+								Since string `json:"since"`
+							} `json:"_"`
+						} `json:"@facets"`
+					} `json:"friend"`
+				} `json:"me"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(js), &res))
+		require.Equal(t, 1, len(res.Data.Me))
+		friends := res.Data.Me[0].Friend
+		require.Equal(t, 2, len(friends))
+		for _, f := range friends {
+			// The facets are the ones of the sampled edges.
+			require.Equal(t, since[f.Uid], f.Facets.Edge.Since, f.Uid)
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"io/ioutil"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
@@ -46,6 +47,7 @@ import (
 	"github.com/dgraph-io/dgraph/protos"
 
 	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/worker"
 	"github.com/dgraph-io/dgraph/x"
@@ -303,6 +305,72 @@ func populateGraph(t *testing.T) {
 	// data for bug (#945), also used by test for #1010
 	addEdgeToLangValue(t, "name", 0x1004, "Артём Ткаченко", "ru", nil)
 	addEdgeToLangValue(t, "name", 0x1004, "Artem Tkachenko", "en", nil)
+	// data for language aware sorting
+	for uid, name := range map[uint64]string{
+		0x2001: "Ågren",
+		0x2002: "Zetterberg",
+		0x2003: "Öberg",
+		0x2004: "Andersson",
+		0x2005: "Ängström",
+	} {
+		addEdgeToLangValue(t, "surname", uid, name, "sv", nil)
+		addEdgeToLangValue(t, "surname", uid, name, "de", nil)
+	}
+	// data for relevance scoring
+	addEdgeToValue(t, "description", 0x3001, "Red running shoes", nil)
+	addEdgeToValue(t, "description", 0x3002, "Red shoes with red laces", nil)
+	addEdgeToValue(t, "description", 0x3003, "Blue shoes", nil)
+	addEdgeToValue(t, "description", 0x3004,
+		"Brown leather boots with a red sole, warm lining and a sturdy heel", nil)
+	addEdgeToValue(t, "description", 0x3005, "Green hat", nil)
+	// data for CJK full text search
+	addEdgeToLangValue(t, "title", 0x3301, "北京大学图书馆", "zh", nil)
+	addEdgeToLangValue(t, "title", 0x3302, "上海大学", "zh", nil)
+	addEdgeToLangValue(t, "title", 0x3303, "東京タワー", "ja", nil)
+	// data for case insensitive indexes
+	for uid, user := range map[uint64][2]string{
+		0x3401: {"Alice@Example.com", "alice"},
+		0x3402: {"bob@example.com", "Bob"},
+		0x3403: {"CAROL@Example.org", "carol"},
+		0x3404: {"alice@example.net", "Dave"},
+	} {
+		addEdgeToValue(t, "email", uid, user[0], nil)
+		addEdgeToValue(t, "username", uid, user[1], nil)
+	}
+	// data for nearest neighbor search
+	addEdgeToValue(t, "embedding", 0x3501, "[2, 2, 0]", nil)
+	addEdgeToValue(t, "embedding", 0x3502, "[1, 0, 0]", nil)
+	addEdgeToValue(t, "embedding", 0x3503, "[0, 1, 0]", nil)
+	addEdgeToValue(t, "embedding", 0x3504, "[0, 0, 1]", nil)
+	addEdgeToValue(t, "embedding", 0x3505, "[-1, 0, 0]", nil)
+	// data for composite index
+	for uid, cs := range map[uint64][2]string{
+		0x3601: {"US", "active"},
+		0x3602: {"US", "inactive"},
+		0x3603: {"IN", "active"},
+		0x3604: {"US", "active"},
+	} {
+		addEdgeToValue(t, "country", uid, cs[0], nil)
+		addEdgeToValue(t, "status", uid, cs[1], nil)
+	}
+	addEdgeToValue(t, "country", 0x3605, "US", nil)
+	// data for facet index
+	addEdgeToUID(t, "rated", 0x3701, 0x3711, map[string]string{"score": "5"})
+	addEdgeToUID(t, "rated", 0x3701, 0x3712, map[string]string{"score": "2"})
+	addEdgeToUID(t, "rated", 0x3702, 0x3711, map[string]string{"score": "3"})
+	addEdgeToUID(t, "rated", 0x3703, 0x3712, map[string]string{"score": "4.5"})
+	addEdgeToUID(t, "rated", 0x3704, 0x3713, nil)
+	// data for phonetic search
+	for uid, name := range map[uint64]string{
+		0x3101: "John Smith",
+		0x3102: "Joan Smyth",
+		0x3103: "Jon Schmidt",
+		0x3104: "Mary Jones",
+		0x3105: "Jane Doe",
+	} {
+		addEdgeToValue(t, "contact", uid, name, nil)
+		addEdgeToValue(t, "contact_code", uid, name, nil)
+	}
 	// data for bug (#1118)
 	addEdgeToLangValue(t, "lossy", 0x1001, "Badger", "", nil)
 	addEdgeToLangValue(t, "lossy", 0x1001, "European badger", "en", nil)
@@ -441,7 +509,7 @@ func TestReturnUids(t *testing.T) {
 	mp := map[string]string{
 		"a": "123",
 	}
-	require.NoError(t, ToJson(qr.Latency, qr.Subgraphs, &buf, mp, false))
+	require.NoError(t, ToJson(qr.Latency, qr.Subgraphs, &buf, mp, false, ""))
 	js := buf.String()
 	require.JSONEq(t,
 		`{"data": {"uids":{"a":"123"},"me":[{"_uid_":"0x1","alive":true,"friend":[{"_uid_":"0x17","name":"Rick Grimes"},{"_uid_":"0x18","name":"Glenn Rhee"},{"_uid_":"0x19","name":"Daryl Dixon"},{"_uid_":"0x1f","name":"Andrea"},{"_uid_":"0x65"}],"gender":"female","name":"Michonne"}]}}`,
@@ -2267,7 +2335,7 @@ func TestDebug1(t *testing.T) {
 	require.NoError(t, err)
 
 	var buf bytes.Buffer
-	require.NoError(t, ToJson(qr.Latency, qr.Subgraphs, &buf, nil, true))
+	require.NoError(t, ToJson(qr.Latency, qr.Subgraphs, &buf, nil, true, ""))
 
 	var mp map[string]interface{}
 	require.NoError(t, json.Unmarshal([]byte(buf.Bytes()), &mp))
@@ -2332,7 +2400,7 @@ func TestDebug3(t *testing.T) {
 	require.NoError(t, err)
 
 	var buf bytes.Buffer
-	require.NoError(t, ToJson(qr.Latency, qr.Subgraphs, &buf, nil, true))
+	require.NoError(t, ToJson(qr.Latency, qr.Subgraphs, &buf, nil, true, ""))
 
 	var mp map[string]interface{}
 	require.NoError(t, json.Unmarshal([]byte(buf.Bytes()), &mp))
@@ -6784,6 +6852,16 @@ func TestSchemaBlock1(t *testing.T) {
 		{Predicate: "occupations", Type: "string"},
 		{Predicate: "_predicate_", Type: "string"},
 		{Predicate: "salary", Type: "float"},
+		{Predicate: "description", Type: "string"},
+		{Predicate: "contact", Type: "string"},
+		{Predicate: "contact_code", Type: "string"},
+		{Predicate: "title", Type: "string"},
+		{Predicate: "email", Type: "string"},
+		{Predicate: "username", Type: "string"},
+		{Predicate: "embedding", Type: "float32vector"},
+		{Predicate: "country", Type: "string"},
+		{Predicate: "status", Type: "string"},
+		{Predicate: "rated", Type: "uid"},
 	}
 	checkSchemaNodes(t, expected, actual)
 }
@@ -6862,6 +6940,175 @@ func TestSchemaBlock5(t *testing.T) {
 	checkSchemaNodes(t, expected, actual)
 }
 
+func TestExistsFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				friend @filter(exists { friend }) {
+					name
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"name":"Rick Grimes"},{"name":"Andrea"}]}]}}`,
+		js)
+}
+
+func TestNotExistsFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				friend @filter(not exists { friend } and has(name)) {
+					name
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"name":"Glenn Rhee"},{"name":"Daryl Dixon"}]}]}}`,
+		js)
+}
+
+func TestExistsFilterNested(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				friend @filter(exists { friend @filter(anyofterms(name, "Michonne")) }) {
+					name
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"name":"Rick Grimes"}]}]}}`,
+		js)
+}
+
+func TestCascadeFields(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				friend @cascade(alive) {
+					name
+					alive
+					address
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"address":"21, mark street, Mars","alive":true,"name":"Rick Grimes"},{"alive":false,"name":"Daryl Dixon"},{"alive":false,"name":"Andrea"}]}]}}`,
+		js)
+}
+
+func TestCascadeFieldsPropagation(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01, 23, 24)) @cascade(friend) {
+				name
+				gender
+				friend {
+					name
+					address
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"address":"21, mark street, Mars","name":"Rick Grimes"}],"gender":"female","name":"Michonne"},{"friend":[{"address":"31, 32 street, Jupiter","name":"Michonne"}],"gender":"male","name":"Rick Grimes"}]}}`,
+		js)
+}
+
+func TestCascadeFieldsUnknown(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				friend @cascade(alive, unknownpred) {
+					name
+					alive
+				}
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Field unknownpred in @cascade isn't a child of friend")
+}
+
+func TestJoinValueVar(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(0x01)) {
+				friend {
+					a as age
+				}
+			}
+			me(func: uid(a)) {
+				name
+				same_age: join(age, val(a)) {
+					name
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Rick Grimes","same_age":[{"name":"Rick Grimes"},{"name":"Glenn Rhee"}]},{"name":"Glenn Rhee","same_age":[{"name":"Rick Grimes"},{"name":"Glenn Rhee"}]},{"name":"Daryl Dixon","same_age":[{"name":"Daryl Dixon"}]},{"name":"Andrea","same_age":[{"name":"Andrea"}]}]}}`,
+		js)
+}
+
+func TestJoinValueVarAtRoot(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(0x01)) {
+				friend {
+					a as age
+				}
+			}
+			me(func: join(age, val(a))) {
+				name
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Rick Grimes"},{"name":"Glenn Rhee"},{"name":"Daryl Dixon"},{"name":"Andrea"}]}}`,
+		js)
+}
+
+func TestJoinInFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(0x01)) {
+				friend {
+					a as age
+				}
+			}
+			me(func: uid(0x01)) @filter(join(age, val(a))) {
+				name
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "join is not supported inside filter")
+}
+
 const schemaStr = `
 name                           : string @index(term, exact, trigram) @count .
 alias                          : string @index(exact, term, fulltext) .
@@ -6885,6 +7132,16 @@ lossy                          : string @index(term) .
 occupations                    : [string] @index(term) .
 graduation                     : [dateTime] @index(year) @count .
 salary                         : float @index(float) .
+description                    : string @index(fulltext, edgengram(2, 5)) .
+contact                        : string @index(phonetic) .
+title                          : string @index(fulltext) .
+contact_code                   : string @index(soundex) .
+email                          : string @index(ihash, itrigram) .
+username                       : string @index(iexact, trigram, composite(email)) .
+embedding                      : float32vector @index(vector(width: 2)) .
+country                        : string @index(exact, composite(status)) .
+status                         : string @index(exact) .
+rated                          : uid @index(facet(score)) .
 `
 
 // Duplicate implemention as in cmd/dgraph/main_test.go
@@ -7593,7 +7850,7 @@ func TestDebugUid(t *testing.T) {
 	_, err = queryRequest.ProcessQuery(ctx)
 	require.NoError(t, err)
 	var buf bytes.Buffer
-	err = ToJson(queryRequest.Latency, queryRequest.Subgraphs, &buf, nil, false)
+	err = ToJson(queryRequest.Latency, queryRequest.Subgraphs, &buf, nil, false, "")
 	require.NoError(t, err)
 	var mp map[string]interface{}
 	require.NoError(t, json.Unmarshal([]byte(buf.Bytes()), &mp))
@@ -9043,114 +9300,298 @@ func TestAggregateRootError(t *testing.T) {
 	require.Contains(t, err.Error(), "Only aggregated variables allowed within empty block.")
 }
 
-func TestFilterLang(t *testing.T) {
-	// This tests the fix for #1334. While getting uids for filter, we fetch data keys when number
-	// of uids is less than number of tokens. Lang tag was not passed correctly while fetching these
-	// data keys.
+func TestAggregateRootDistribution(t *testing.T) {
 	populateGraph(t)
 	query := `
 		{
-			me(func: uid(0x1001, 0x1002, 0x1003)) @filter(ge(name@en, "D"))  {
-				name@en
+			var(func: anyofterms(name, "Rick Michonne Andrea")) {
+				a as age
+			}
+
+			me() {
+				median(val(a))
+				percentile(val(a), 90)
+				variance(val(a))
+				stddev(val(a))
 			}
 		}
 	`
 	js := processToFastJSON(t, query)
-	require.JSONEq(t,
-		`{"data": {"me":[{"name@en":"European badger"},{"name@en":"Honey badger"},{"name@en":"Honey bee"}]}}`, js)
+	require.JSONEq(t, `{"data": {"me":[{"median(val(a))":19.000000},{"percentile(val(a),90)":34.200000},{"variance(val(a))":100.666667},{"stddev(val(a))":10.033278}]}}`, js)
 }
 
-func TestMathCeil1(t *testing.T) {
+func TestPercentileNaN(t *testing.T) {
+	for _, p := range []string{"NaN", "+Inf", "-1"} {
+		_, err := newAggregator(&Function{Name: "percentile", Args: []gql.Arg{{Value: p}}})
+		require.Error(t, err, p)
+		require.Contains(t, err.Error(), "Invalid percentile")
+	}
+	// The ranks out of the values are clamped to them.
+	require.Equal(t, 3.0, percentile([]float64{3, 1, 2}, 100))
+	require.Equal(t, 1.0, percentile([]float64{1}, 50))
+}
+
+func TestAggregateRootCountDistinct(t *testing.T) {
 	populateGraph(t)
 	query := `
-	{
-		me as var(func: eq(name, "Xyz"))
-		var(func: uid(me)) {
-			friend {
-				x as age
+		{
+			var(func: uid(1)) {
+				friend {
+					a as age
+				}
 			}
-			x2 as sum(val(x))
-			c as count(friend)
-		}
 
-		me(func: uid(me)) {
-			ceilAge: math(ceil(x2/c))
+			me() {
+				count_distinct(val(a))
+				approx_count_distinct(val(a))
+			}
 		}
-	}
 	`
 	js := processToFastJSON(t, query)
-	require.JSONEq(t, `{"data": {}}`, js)
+	require.JSONEq(t, `{"data": {"me":[{"count_distinct(val(a))":3},{"approx_count_distinct(val(a))":3}]}}`, js)
 }
 
-func TestMathCeil2(t *testing.T) {
+func TestGroupByDistributionAgg(t *testing.T) {
 	populateGraph(t)
 	query := `
-	{
-		me as var(func: eq(name, "Michonne"))
-		var(func: uid(me)) {
-			friend {
-				x as age
+		{
+			var(func: uid(1)) {
+				friend @groupby(school) {
+					a as median(age)
+					b as percentile(age, 75)
+				}
 			}
-			x2 as sum(val(x))
-			c as count(friend)
-		}
 
-		me(func: uid(me)) {
-			ceilAge: math(ceil(x2/c))
+			med(func: uid(a), orderasc: name) {
+				name
+				val(a)
+			}
+
+			perc(func: uid(b), orderasc: name) {
+				name
+				val(b)
+			}
 		}
-	}
 	`
 	js := processToFastJSON(t, query)
-	require.JSONEq(t, `{"data": {"me":[{"ceilAge":14.000000}]}}`, js)
+	require.JSONEq(t,
+		`{"data": {"med":[{"name":"School A","val(a)":16.000000},{"name":"School B","val(a)":17.000000}],"perc":[{"name":"School A","val(b)":16.500000},{"name":"School B","val(b)":18.000000}]}}`,
+		js)
 }
 
-func TestAppendDummyValuesPanic(t *testing.T) {
-	// This is a fix for #1359. We should check that SrcUIDs is not nil before accessing Uids.
+func TestGroupByCountDistinct(t *testing.T) {
 	populateGraph(t)
 	query := `
-	{
-		n(func:ge(_uid_, 0)) {
-			count()
+		{
+			me(func: uid(1)) {
+				friend @groupby(age) {
+					count_distinct(name)
+				}
+			}
 		}
-	}`
+	`
 	js := processToFastJSON(t, query)
-	require.JSONEq(t, `{"data": {}}`, js)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"@groupby":[{"age":17,"count_distinct(name)":1},{"age":19,"count_distinct(name)":1},{"age":15,"count_distinct(name)":2}]}]}]}}`,
+		js)
 }
 
-func TestMultipleValueFilter(t *testing.T) {
+func TestGroupByHaving(t *testing.T) {
 	populateGraph(t)
 	query := `
-	{
-		me(func: ge(graduation, "1930")) {
-			name
-			graduation
+		{
+			me(func: uid(1)) {
+				friend @groupby(age) @having(gt(count, 1) or eq(age, 19)) {
+					count(_uid_)
+				}
+			}
 		}
-	}
 	`
 	js := processToFastJSON(t, query)
-	require.JSONEq(t, `{"data": {"me":[{"name":"Michonne","graduation":"1932-01-01T00:00:00Z"},{"name":"Andrea","graduation":["1935-01-01T00:00:00Z","1933-01-01T00:00:00Z"]}]}}`, js)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"@groupby":[{"age":19,"count":1},{"age":15,"count":2}]}]}]}}`,
+		js)
 }
 
-func TestMultipleValueFilter2(t *testing.T) {
+func TestGroupByOrderPagination(t *testing.T) {
 	populateGraph(t)
 	query := `
-	{
-		me(func: le(graduation, "1933")) {
-			name
-			graduation
+		{
+			me(func: uid(1)) {
+				friend @groupby(age, orderdesc: age, first: 2, offset: 1) {
+					c: count(_uid_)
+				}
+			}
 		}
-	}
 	`
 	js := processToFastJSON(t, query)
-	require.Equal(t, `{"data": {"me":[{"name":"Michonne","graduation":"1932-01-01T00:00:00Z"}]}}`, js)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"@groupby":[{"age":17,"c":1},{"age":15,"c":2}]}]}]}}`,
+		js)
 }
 
-func TestMultipleValueArray(t *testing.T) {
-	// Skip for now, fix later.
-	t.Skip()
+func TestGroupByOrderAggregate(t *testing.T) {
 	populateGraph(t)
 	query := `
-	{
+		{
+			me(func: uid(1)) {
+				friend @groupby(age, orderdesc: n, first: 1) {
+					n: count_distinct(name)
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"@groupby":[{"age":15,"n":2}]}]}]}}`,
+		js)
+}
+
+func TestGroupByFacet(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1, 31, 1000, 1001, 1002)) @groupby(path @facets(weight), orderdesc: count, first: 2) {
+				count(_uid_)
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"@groupby":[{"weight":0.1,"count":4},{"weight":0.2,"count":1}]}]}}`,
+		js)
+}
+
+func TestGroupByPath(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(1)) {
+				friend @groupby(friend/school) {
+					a as count(_uid_)
+				}
+			}
+
+			me(func: uid(a)) {
+				name
+				val(a)
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"School A","val(a)":2}]}}`,
+		js)
+}
+
+func TestFilterLang(t *testing.T) {
+	// This tests the fix for #1334. While getting uids for filter, we fetch data keys when number
+	// of uids is less than number of tokens. Lang tag was not passed correctly while fetching these
+	// data keys.
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x1001, 0x1002, 0x1003)) @filter(ge(name@en, "D"))  {
+				name@en
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name@en":"European badger"},{"name@en":"Honey badger"},{"name@en":"Honey bee"}]}}`, js)
+}
+
+func TestMathCeil1(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me as var(func: eq(name, "Xyz"))
+		var(func: uid(me)) {
+			friend {
+				x as age
+			}
+			x2 as sum(val(x))
+			c as count(friend)
+		}
+
+		me(func: uid(me)) {
+			ceilAge: math(ceil(x2/c))
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {}}`, js)
+}
+
+func TestMathCeil2(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me as var(func: eq(name, "Michonne"))
+		var(func: uid(me)) {
+			friend {
+				x as age
+			}
+			x2 as sum(val(x))
+			c as count(friend)
+		}
+
+		me(func: uid(me)) {
+			ceilAge: math(ceil(x2/c))
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[{"ceilAge":14.000000}]}}`, js)
+}
+
+func TestAppendDummyValuesPanic(t *testing.T) {
+	// This is a fix for #1359. We should check that SrcUIDs is not nil before accessing Uids.
+	populateGraph(t)
+	query := `
+	{
+		n(func:ge(_uid_, 0)) {
+			count()
+		}
+	}`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {}}`, js)
+}
+
+func TestMultipleValueFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: ge(graduation, "1930")) {
+			name
+			graduation
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[{"name":"Michonne","graduation":"1932-01-01T00:00:00Z"},{"name":"Andrea","graduation":["1935-01-01T00:00:00Z","1933-01-01T00:00:00Z"]}]}}`, js)
+}
+
+func TestMultipleValueFilter2(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: le(graduation, "1933")) {
+			name
+			graduation
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.Equal(t, `{"data": {"me":[{"name":"Michonne","graduation":"1932-01-01T00:00:00Z"}]}}`, js)
+}
+
+func TestMultipleValueArray(t *testing.T) {
+	// Skip for now, fix later.
+	t.Skip()
+	populateGraph(t)
+	query := `
+	{
 		me(func: uid(1)) {
 			name
 			graduation
@@ -9303,6 +9744,103 @@ func TestNearPointMultiPolygon(t *testing.T) {
 	require.Equal(t, `{"data": {"me":[{"name":"Rick Grimes"}]}}`, js)
 }
 
+func TestLineString(t *testing.T) {
+	populateGraph(t)
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-40.0, -60.0}, {-40.5, -60.2}, {-41.0, -60.1}})
+	addGeoData(t, ps, 5109, l, "Drake Passage route")
+
+	query := `{
+		crossing(func: intersects(geometry, "[[[-40.2, -60.5], [-40.2, -59.5], [-40.3, -59.5], [-40.3, -60.5], [-40.2, -60.5]]]")) {
+			name
+		}
+		within(func: within(geometry, "[[[-39.0, -61.0], [-39.0, -59.0], [-42.0, -59.0], [-42.0, -61.0], [-39.0, -61.0]]]")) {
+			name
+		}
+		near(func: near(geometry, [-40.75, -60.16], 1000)) {
+			name
+		}
+		far(func: near(geometry, [-40.75, -60.5], 1000)) {
+			name
+		}
+	}`
+
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"crossing":[{"name":"Drake Passage route"}],
+		"within":[{"name":"Drake Passage route"}],
+		"near":[{"name":"Drake Passage route"}]}}`, js)
+}
+
+func TestGeoWKT(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: uid(5101, 5102, 5105)) @filter(near(geometry, "POINT(-122.082506 37.4249518)", 1)) {
+			name
+			geometry
+		}
+		within(func: within(geometry, "POLYGON((-122.06 37.37, -122.1 37.36, -122.12 37.4, -122.11 37.43, -122.04 37.43, -122.06 37.37))")) {
+			name
+		}
+	}`
+
+	js := processToGeoFormat(t, query, GeoWKT)
+	require.JSONEq(t, `{"data": {
+		"me":[{"name":"Googleplex","geometry":"POINT(-122.082506 37.4249518)"},
+			{"name":"Mountain View","geometry":"POLYGON((-122.06 37.37, -122.1 37.36, -122.12 37.4, -122.11 37.43, -122.04 37.43, -122.06 37.37))"}],
+		"within":[{"name":"Googleplex"},{"name":"Shoreline Amphitheater"}]}}`, js)
+
+	_, err := processToFastJsonReq(t, `{
+		me(func: within(geometry, "LINESTRING(-122.06 37.37, -122.1 37.36)")) {
+			name
+		}
+	}`)
+	require.Error(t, err)
+}
+
+func TestGeoFeatures(t *testing.T) {
+	populateGraph(t)
+	query := `{
+		me(func: uid(5101, 5105, 1)) {
+			_uid_
+			name
+			geometry
+		}
+		var(func: uid(5102)) {
+			name
+		}
+	}`
+
+	js := processToGeoFormat(t, query, GeoFeatures)
+	require.JSONEq(t, `{"type":"FeatureCollection","features":[
+		{"type":"Feature","id":"0x1","geometry":null,"properties":{"name":"Michonne"}},
+		{"type":"Feature","id":"0x13ed","geometry":{"type":"Point","coordinates":[-122.082506,37.4249518]},
+			"properties":{"name":"Googleplex"}},
+		{"type":"Feature","id":"0x13f1","geometry":{"type":"Polygon","coordinates":[[[-122.06,37.37],[-122.1,37.36],[-122.12,37.4],[-122.11,37.43],[-122.04,37.43],[-122.06,37.37]]]},
+			"properties":{"name":"Mountain View"}}]}`, js)
+
+	// The allocated uids and the latency are kept in the collection.
+	res, err := gql.Parse(gql.Request{Str: query, Http: true})
+	require.NoError(t, err)
+	queryRequest := QueryRequest{Latency: &Latency{}, GqlQuery: &res}
+	_, err = queryRequest.ProcessQuery(defaultContext())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, ToJson(queryRequest.Latency, queryRequest.Subgraphs, &buf,
+		map[string]string{"a": "0x1"}, true, GeoFeatures))
+	var out struct {
+		Type       string
+		Uids       map[string]string
+		Extensions map[string]interface{}
+		Features   []interface{}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Equal(t, "FeatureCollection", out.Type)
+	require.Equal(t, map[string]string{"a": "0x1"}, out.Uids)
+	require.Contains(t, out.Extensions, "server_latency")
+	require.Len(t, out.Features, 3)
+}
+
 func TestMultiSort1(t *testing.T) {
 	populateGraph(t)
 
@@ -9432,3 +9970,1055 @@ func TestFilterRoot(t *testing.T) {
 	js := processToFastJSON(t, query)
 	require.JSONEq(t, `{"data": {}}`, js)
 }
+
+func TestPathExpression(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				friend/friend {
+					name
+				}
+				follows: follow*{2,} {
+					name
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend/friend":[{"name":"Michonne"},{"name":"Glenn Rhee"}],"follows":[{"name":"Alice"},{"name":"Bob"},{"name":"Matt"},{"name":"John"}]}]}}`,
+		js)
+}
+
+func TestPathExpressionAlternation(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x01)) {
+				p: friend/(follow|friend) {
+					name
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"p":[{"name":"Michonne"},{"name":"Glenn Rhee"},{"name":"Bob"}]}]}}`,
+		js)
+}
+
+func TestSortNullsFirst(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1)) {
+				friend(orderasc: age, nulls: first) {
+					_uid_
+					name
+					age
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"_uid_":"0x65"},{"_uid_":"0x17","name":"Rick Grimes","age":15},{"_uid_":"0x18","name":"Glenn Rhee","age":15},{"_uid_":"0x19","name":"Daryl Dixon","age":17},{"_uid_":"0x1f","name":"Andrea","age":19}]}]}}`,
+		js)
+}
+
+func TestSortNullsLastPagination(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1)) {
+				friend(orderdesc: age, nulls: last, first: 4, offset: 2) {
+					_uid_
+					name
+					age
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"friend":[{"_uid_":"0x17","name":"Rick Grimes","age":15},{"_uid_":"0x18","name":"Glenn Rhee","age":15},{"_uid_":"0x65"}]}]}}`,
+		js)
+}
+
+func TestSortNullsFirstValueVar(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			var(func: uid(1)) {
+				f as friend {
+					a as age
+				}
+			}
+			me(func: uid(f), orderdesc: val(a), nulls: first, first: 3) {
+				_uid_
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"_uid_":"0x65"},{"_uid_":"0x1f"},{"_uid_":"0x19"}]}}`,
+		js)
+}
+
+func TestSortNullsWithoutOrder(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1)) {
+				friend(nulls: last) {
+					name
+				}
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+}
+
+func TestSortCollationSwedish(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x2001, 0x2002, 0x2003, 0x2004, 0x2005), orderasc: surname@sv) {
+				surname@sv
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"surname@sv":"Andersson"},{"surname@sv":"Zetterberg"},{"surname@sv":"Ågren"},{"surname@sv":"Ängström"},{"surname@sv":"Öberg"}]}}`,
+		js)
+}
+
+func TestSortCollationGermanDesc(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(0x2001, 0x2002, 0x2003, 0x2004, 0x2005), orderdesc: surname@de) {
+				surname@de
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"surname@de":"Zetterberg"},{"surname@de":"Öberg"},{"surname@de":"Ängström"},{"surname@de":"Andersson"},{"surname@de":"Ågren"}]}}`,
+		js)
+}
+
+func TestRandomChild(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1)) {
+				friend(random: 2, seed: 7) {
+					_uid_
+				}
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	var res struct {
+		Data struct {
+			Me []struct {
+				Friend []struct {
+					Uid string `json:"_uid_"`
+				} `json:"friend"`
+			} `json:"me"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(js), &res))
+	require.Equal(t, 1, len(res.Data.Me))
+	friends := res.Data.Me[0].Friend
+	require.Equal(t, 2, len(friends))
+	for _, f := range friends {
+		require.Contains(t, []string{"0x17", "0x18", "0x19", "0x1f", "0x65"}, f.Uid)
+	}
+	require.True(t, friends[0].Uid != friends[1].Uid)
+
+	// The same seed gives the same sample.
+	require.Equal(t, js, processToFastJSON(t, query))
+}
+
+func TestRandomRootAfterFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1, 23, 24, 25, 31), random: 3) @filter(le(age, 17)) {
+				name
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	// Only three nodes match the filter, so all of them are returned.
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Rick Grimes"},{"name":"Glenn Rhee"},{"name":"Daryl Dixon"}]}}`,
+		js)
+}
+
+func TestRandomInvalid(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1)) {
+				friend(random: 0) {
+					name
+				}
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+
+	query = `
+		{
+			me(func: uid(1)) {
+				friend(seed: 3) {
+					name
+				}
+			}
+		}
+	`
+	_, err = processToFastJsonReq(t, query)
+	require.Error(t, err)
+}
+
+func TestDistinctValues(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: distinct(alive)) {
+				alive
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"alive":false,"count":2},{"alive":true,"count":2}]}}`, js)
+}
+
+func TestDistinctValuesPagination(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: distinct(age), first: 3, offset: 1) {
+				age
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"age":17,"count":1},{"age":19,"count":1},{"age":25,"count":4}]}}`, js)
+}
+
+func TestDistinctValuesPrefix(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: distinct(name, "Al")) {
+				name
+			}
+		}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t,
+		`{"data": {"me":[{"name":"Alice","count":5},{"name":"Alice\"","count":1}]}}`, js)
+}
+
+func TestDistinctValuesPrefixNotString(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: distinct(age, "1")) {
+				age
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Prefix can only be used to list distinct string values")
+}
+
+func TestDistinctValuesLossyIndex(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: distinct(title)) {
+				title
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Attribute title doesn't have an index keeping whole values")
+}
+
+func TestDistinctValuesInFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+		{
+			me(func: uid(1)) @filter(distinct(age)) {
+				age
+			}
+		}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+}
+
+func TestMathDateFuncs(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1, 23)) {
+			d as dob
+			added: math(dateadd(d, "1y2mo3d"))
+			back: math(dateadd(d, "-1d12h"))
+			month: math(truncate(d, "month"))
+			week: math(truncate(d, "week"))
+			year: math(extract(d, "year"))
+			weekday: math(extract(d, "weekday"))
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[
+		{"dob":"1910-01-01T00:00:00Z","added":"1911-03-04T00:00:00Z","back":"1909-12-30T12:00:00Z","month":"1910-01-01T00:00:00Z","week":"1909-12-27T00:00:00Z","year":1910,"weekday":6},
+		{"dob":"1910-01-02T00:00:00Z","added":"1911-03-05T00:00:00Z","back":"1909-12-31T12:00:00Z","month":"1910-01-01T00:00:00Z","week":"1909-12-27T00:00:00Z","year":1910,"weekday":0}]}}`,
+		js)
+}
+
+func TestMathStringFuncs(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1, 23)) {
+			n as name
+			label: math(concat(upper(n), " (", tostring(length(n)), ")"))
+			short: math(substring(lower(n), 0, 4))
+			last: math(substring(n, -3))
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[
+		{"name":"Michonne","label":"MICHONNE (8)","short":"mich","last":"nne"},
+		{"name":"Rick Grimes","label":"RICK GRIMES (11)","short":"rick","last":"mes"}]}}`,
+		js)
+}
+
+func TestMathConversionFuncs(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1, 23)) {
+			a as age
+			d as dob
+			half: math(tostring(a / 2))
+			adult: math(tobool(toint(a >= 18)))
+			ts: math(todatetime(tofloat(toint(d))))
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[
+		{"age":38,"dob":"1910-01-01T00:00:00Z","half":"19","adult":true,"ts":"1910-01-01T00:00:00Z"},
+		{"age":15,"dob":"1910-01-02T00:00:00Z","half":"7.5","adult":false,"ts":"1910-01-02T00:00:00Z"}]}}`,
+		js)
+}
+
+func TestMathValueFuncError(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1)) {
+			d as dob
+			bad: math(truncate(d, "fortnight"))
+		}
+	}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid unit for truncate")
+}
+
+func TestTextScore(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		var(func: anyoftext(description, "red shoes")) {
+			s as bm25(description, "red shoes")
+		}
+
+		me(func: uid(s), orderdesc: val(s)) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[
+		{"_uid_":"0x3002"},{"_uid_":"0x3001"},{"_uid_":"0x3003"},{"_uid_":"0x3004"}]}}`,
+		js)
+}
+
+func TestTextScoreOutput(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(0x3003, 0x3005)) {
+			description
+			bm25(description, "blue")
+			score: bm25(description, "hat")
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[
+		{"description":"Blue shoes","bm25(description)":1.74277},
+		{"description":"Green hat","score":1.74277}]}}`,
+		js)
+}
+
+func TestTextScoreNotIndexed(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1)) {
+			bm25(name, "Michonne")
+		}
+	}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not indexed with type fulltext")
+}
+
+func TestPhrase(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: phrase(description, "red shoes")) {
+			description
+		}
+		reversed(func: phrase(description, "shoes red")) {
+			description
+		}
+		stopwords(func: phrase(description, "boots with a red sole")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"description":"Red shoes with red laces"}],
+		"stopwords":[{"_uid_":"0x3004"}]}}`,
+		js)
+}
+
+func TestNearWords(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: near_words(description, "red shoes", 2)) {
+			description
+		}
+		reversed(func: near_words(description, "shoes red", 1)) {
+			description
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"description":"Red running shoes"},{"description":"Red shoes with red laces"}],
+		"reversed":[{"description":"Red shoes with red laces"}]}}`,
+		js)
+}
+
+func TestPhraseFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: anyoftext(description, "shoes boots")) @filter(phrase(description, "red laces") or near_words(description, "red heel", 7)) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[{"_uid_":"0x3002"},{"_uid_":"0x3004"}]}}`, js)
+}
+
+func TestNearWordsInvalidDistance(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: near_words(description, "red shoes", 0)) {
+			description
+		}
+	}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "near_words expects a positive distance")
+}
+
+func TestPrefix(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: prefix(description, "sh")) {
+			_uid_
+		}
+		words(func: prefix(description, "re SH")) {
+			_uid_
+		}
+		long(func: prefix(description, "leather")) {
+			_uid_
+		}
+		longer(func: prefix(description, "leathery")) {
+			_uid_
+		}
+		filtered(func: uid(0x3001, 0x3003, 0x3005)) @filter(prefix(description, "sho")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"_uid_":"0x3001"},{"_uid_":"0x3002"},{"_uid_":"0x3003"}],
+		"words":[{"_uid_":"0x3001"},{"_uid_":"0x3002"}],
+		"long":[{"_uid_":"0x3004"}],
+		"filtered":[{"_uid_":"0x3001"},{"_uid_":"0x3003"}]}}`,
+		js)
+}
+
+func TestPrefixTooShort(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: prefix(description, "r")) {
+			_uid_
+		}
+	}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is shorter than the minimum length 2 of the index")
+}
+
+func TestPrefixNotIndexed(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: prefix(name, "Mi")) {
+			_uid_
+		}
+	}
+	`
+	_, err := processToFastJsonReq(t, query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Attribute name is not indexed with type edgengram")
+}
+
+func TestMatch(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: match(name, "Andreas", 2)) {
+			_uid_
+			name
+		}
+		typo(func: match(name, "Michone", 1)) {
+			name
+		}
+		first(func: match(name, "Andre", 1), first: 1) {
+			name
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"_uid_":"0x1f","name":"Andrea"},{"_uid_":"0x8fc","name":"Andre"}],
+		"typo":[{"name":"Michonne"}],
+		"first":[{"name":"Andre"}]}}`,
+		js)
+}
+
+func TestMatchFilter(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: uid(1, 31, 2300)) @filter(match(name, "Andreas", 1)) {
+			name
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {"me":[{"name":"Andrea"}]}}`, js)
+}
+
+func TestMatchErrors(t *testing.T) {
+	populateGraph(t)
+	for _, tc := range []struct {
+		fn  string
+		err string
+	}{
+		{`match(name, "Al", 1)`, "match needs a text of at least 3 characters"},
+		{`match(alias, "Bob Joe", 1)`, "does not have trigram index for fuzzy matching"},
+		{`match(name, "Andrea", -1)`, "match expects a non-negative maximum distance"},
+	} {
+		query := fmt.Sprintf(`{ me(func: %s) { name } }`, tc.fn)
+		_, err := processToFastJsonReq(t, query)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestContains(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: contains(name, "ndre")) {
+			name
+		}
+		regex(func: contains(name, "Rhee (")) {
+			name
+		}
+		nocase(func: icontains(name, "ALICE")) @filter(uid(10000, 10001, 10002)) {
+			_uid_
+		}
+		filtered(func: uid(1, 31, 2300)) @filter(contains(name, "ndrea")) {
+			name
+		}
+		geo(func: contains(loc, [2.0, 0.0])) {
+			name
+		}
+		# On string predicates, a geometry is looked for as a substring.
+		text(func: contains(name, "[2.0, 0.0]")) {
+			name
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"name":"Andrea"},{"name":"Andrea With no friends"},{"name":"Andre"}],
+		"nocase":[{"_uid_":"0x2710"},{"_uid_":"0x2712"}],
+		"filtered":[{"name":"Andrea"}],
+		"geo":[{"name":"Rick Grimes"}]}}`,
+		js)
+}
+
+func TestContainsErrors(t *testing.T) {
+	populateGraph(t)
+	for _, tc := range []struct {
+		fn  string
+		err string
+	}{
+		{`contains(name, "Al")`, "contains needs a substring of at least 3 characters"},
+		{`icontains(alias, "Bob")`, "does not have trigram index for substring search"},
+	} {
+		query := fmt.Sprintf(`{ me(func: %s) { name } }`, tc.fn)
+		_, err := processToFastJsonReq(t, query)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestSoundsLike(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: sounds_like(contact, "Jon")) {
+			contact
+		}
+		words(func: sounds_like(contact, "smith jon")) {
+			_uid_
+		}
+		soundex(func: sounds_like(contact_code, "Smit")) {
+			_uid_
+		}
+		filtered(func: uid(0x3101, 0x3104, 0x3105)) @filter(sounds_like(contact, "Jonas")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"contact":"John Smith"},{"contact":"Joan Smyth"},{"contact":"Jon Schmidt"},
+			{"contact":"Jane Doe"}],
+		"words":[{"_uid_":"0x3101"},{"_uid_":"0x3102"},{"_uid_":"0x3103"}],
+		"soundex":[{"_uid_":"0x3101"},{"_uid_":"0x3102"},{"_uid_":"0x3103"}],
+		"filtered":[{"_uid_":"0x3104"}]}}`,
+		js)
+}
+
+func TestSoundsLikeErrors(t *testing.T) {
+	populateGraph(t)
+	for _, tc := range []struct {
+		fn  string
+		err string
+	}{
+		{`sounds_like(name, "Alice")`, "is not indexed with type phonetic or soundex"},
+		{`sounds_like(contact, "42")`, "sounds_like needs a text with at least one word"},
+	} {
+		query := fmt.Sprintf(`{ me(func: %s) { name } }`, tc.fn)
+		_, err := processToFastJsonReq(t, query)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestFullTextAnalyzerConfig(t *testing.T) {
+	populateGraph(t)
+	dir, err := ioutil.TempDir("", "analyzer_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tok.Config.AnalyzerDir = dir
+	defer func() { tok.Config.AnalyzerDir = "" }()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stopwords.txt"),
+		[]byte("# Too common.\npatient\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "synonyms.txt"),
+		[]byte("heart, myocardial\nkidney, renal\n"), 0644))
+
+	require.NoError(t, schema.ParseBytes([]byte(schemaStr+`
+diagnosis : string @index(fulltext(stemming: false, stopwords: <stopwords.txt>, synonyms: <synonyms.txt>)) .
+`), 1))
+	defer schema.ParseBytes([]byte(schemaStr), 1)
+	addEdgeToValue(t, "diagnosis", 0x3201, "Patient with myocardial infarction", nil)
+	addEdgeToValue(t, "diagnosis", 0x3202, "Heart failure", nil)
+	addEdgeToValue(t, "diagnosis", 0x3203, "Renal failures", nil)
+
+	query := `
+	{
+		synonyms(func: anyoftext(diagnosis, "heart")) {
+			_uid_
+		}
+		stemming(func: alloftext(diagnosis, "kidney failure")) {
+			_uid_
+		}
+		stopwords(func: anyoftext(diagnosis, "patient with")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"synonyms":[{"_uid_":"0x3201"},{"_uid_":"0x3202"}],
+		"stopwords":[{"_uid_":"0x3201"}]}}`,
+		js)
+}
+
+func TestFullTextAnalyzerFileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analyzer_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "stopwords.txt"),
+		[]byte("patient\nsecret phrase\n"), 0644))
+
+	for _, tc := range []struct {
+		dir  string
+		file string
+		err  string
+	}{
+		{"", "synonyms.txt", "can't be used without an analyzer directory"},
+		{dir, "/etc/passwd", "must be relative to the analyzer directory"},
+		{dir, "../../etc/passwd", "is outside of the analyzer directory"},
+		{dir, "synonyms.txt", "Analyzer file synonyms.txt doesn't exist"},
+		{dir, "stopwords.txt", "Expected a single word on line 2 of stopwords.txt"},
+	} {
+		tok.Config.AnalyzerDir = tc.dir
+		_, err := schema.Parse(fmt.Sprintf(
+			"diagnosis : string @index(fulltext(stopwords: <%s>)) .", tc.file))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Invalid analyzer for attr diagnosis")
+		require.Contains(t, err.Error(), tc.err)
+		require.NotContains(t, err.Error(), "secret")
+	}
+	tok.Config.AnalyzerDir = ""
+}
+
+func TestFullTextCJK(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		any(func: anyoftext(title@zh, "大学")) {
+			title@zh
+		}
+		all(func: alloftext(title@zh, "北京大学")) {
+			_uid_
+		}
+		phrase(func: phrase(title@zh, "大学图书")) {
+			_uid_
+		}
+		ja(func: anyoftext(title@ja, "タワー")) {
+			title@ja
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"any":[{"title@zh":"北京大学图书馆"},{"title@zh":"上海大学"}],
+		"all":[{"_uid_":"0x3301"}],
+		"phrase":[{"_uid_":"0x3301"}],
+		"ja":[{"title@ja":"東京タワー"}]}}`,
+		js)
+}
+
+func TestCaseInsensitiveIndex(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		eq(func: eq(email, "ALICE@example.COM")) {
+			email
+		}
+		regexi(func: regexp(email, /^alice@/i)) {
+			_uid_
+		}
+		regex(func: regexp(email, /^bob@example/)) {
+			email
+		}
+		class(func: regexp(email, /^[B-C]aro/i)) {
+			email
+		}
+		user(func: eq(username, "BOB")) {
+			username
+		}
+		ge(func: ge(username, "C")) {
+			username
+		}
+		sorted(func: uid(0x3401, 0x3402, 0x3403, 0x3404), orderasc: username) {
+			username
+		}
+		filtered(func: uid(0x3401, 0x3402, 0x3403, 0x3404)) @filter(eq(username, "Carol")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"eq":[{"email":"Alice@Example.com"}],
+		"regexi":[{"_uid_":"0x3401"},{"_uid_":"0x3404"}],
+		"regex":[{"email":"bob@example.com"}],
+		"class":[{"email":"CAROL@Example.org"}],
+		"user":[{"username":"Bob"}],
+		"ge":[{"username":"carol"},{"username":"Dave"}],
+		"sorted":[{"username":"alice"},{"username":"Bob"},{"username":"carol"},
+			{"username":"Dave"}],
+		"filtered":[{"_uid_":"0x3403"}]}}`,
+		js)
+}
+
+func TestSimilarTo(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: similar_to(embedding, 2, "[2, 0, 0]")) {
+			_uid_
+			embedding
+			distance(embedding)
+		}
+		# Only the vectors in the buckets of the given one or next to them are
+		# looked for, so fewer than k of them may be found. Which ones are
+		# depends on the projections seeded with the dimension: with the width
+		# of 2, [0, 1, 0] and [0, 0, 1] aren't found.
+		var(func: similar_to(embedding, 3, "[2, 0, 0]")) {
+			d as distance(embedding)
+		}
+		farthest(func: uid(d), orderdesc: val(d), first: 1) {
+			_uid_
+			val(d)
+		}
+		filtered(func: uid(0x3501, 0x3503, 0x3505)) @filter(similar_to(embedding, 1, "[2, 0, 0]")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"_uid_":"0x3502","embedding":[1,0,0],"distance(embedding)":1},
+			{"_uid_":"0x3501","embedding":[2,2,0],"distance(embedding)":2}],
+		"farthest":[{"_uid_":"0x3501","val(d)":2}],
+		"filtered":[{"_uid_":"0x3501"}]}}`,
+		js)
+}
+
+func TestSimilarToErrors(t *testing.T) {
+	populateGraph(t)
+	for _, tc := range []struct {
+		fn  string
+		err string
+	}{
+		{`similar_to(name, 2, "[1, 0]")`, "is not indexed with type vector"},
+		{`similar_to(embedding, 0, "[1, 0, 0]")`, "similar_to expects a positive number"},
+		{`similar_to(embedding, 2, "1, 0, 0")`, "Vector should be a list of numbers"},
+		{`similar_to(embedding, 2, "[NaN, 0, 0]")`, "Vector can't have NaN or infinite numbers"},
+	} {
+		query := fmt.Sprintf(`{ me(func: %s) { _uid_ } }`, tc.fn)
+		_, err := processToFastJsonReq(t, query)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestCompositeIndex(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		root(func: eq(country, "US")) @filter(eq(status, "active")) {
+			_uid_
+		}
+		reversed(func: eq(status, "active")) @filter(eq(country, "US")) {
+			_uid_
+		}
+		and(func: uid(0x3601, 0x3602, 0x3603, 0x3604, 0x3605))
+			@filter(eq(status, "inactive") AND eq(country, "US")) {
+			_uid_
+		}
+		or(func: eq(country, "US")) @filter(eq(status, "inactive") OR eq(country, "IN")) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"root":[{"_uid_":"0x3601"},{"_uid_":"0x3604"}],
+		"reversed":[{"_uid_":"0x3601"},{"_uid_":"0x3604"}],
+		"and":[{"_uid_":"0x3602"}],
+		"or":[{"_uid_":"0x3602"}]}}`,
+		js)
+
+	res, err := gql.Parse(gql.Request{Str: `{
+		me(func: eq(country, "US")) @filter(eq(status, "active")) { _uid_ }
+	}`})
+	require.NoError(t, err)
+	queryRequest := QueryRequest{Latency: &Latency{}, GqlQuery: &res}
+	_, err = queryRequest.ProcessQuery(defaultContext())
+	require.NoError(t, err)
+	// The lookup of the composite index doesn't change the query.
+	sg := queryRequest.Subgraphs[0]
+	require.Equal(t, "eq", sg.SrcFunc.Name)
+	require.Len(t, sg.Filters, 1)
+	lookup, filters := sg.useCompositeIndexes(true)
+	require.Equal(t, "composite", lookup.SrcFunc.Name)
+	require.Empty(t, filters)
+	require.Equal(t, "eq", sg.SrcFunc.Name)
+	require.Len(t, sg.Filters, 1)
+
+	// The composite index isn't used when eq ignores the case of the values.
+	js = processToFastJSON(t, `{
+		me(func: eq(username, "ALICE")) @filter(eq(email, "alice@example.com")) { _uid_ }
+	}`)
+	require.JSONEq(t, `{"data": {"me":[{"_uid_":"0x3401"}]}}`, js)
+
+	// The composite index follows the changes of all its predicates.
+	addEdgeToValue(t, "status", 0x3604, "inactive", nil)
+	addEdgeToValue(t, "status", 0x3605, "active", nil)
+	js = processToFastJSON(t, `{
+		me(func: eq(country, "US")) @filter(eq(status, "active")) { _uid_ }
+	}`)
+	require.JSONEq(t, `{"data": {"me":[{"_uid_":"0x3601"},{"_uid_":"0x3605"}]}}`, js)
+	delEdgeToLangValue(t, "status", 0x3605, "active", "")
+}
+
+func TestFacetIndex(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		gt(func: has(rated)) @facets(gt(score, 4)) {
+			_uid_
+		}
+		le(func: has(rated)) @facets(le(score, 3)) {
+			_uid_
+		}
+		eq(func: has(rated)) @facets(eq(score, 4.5)) {
+			_uid_
+		}
+		and(func: has(rated)) @facets(ge(score, 3) AND le(score, 4)) {
+			_uid_
+		}
+		or(func: has(rated)) @facets(eq(score, 2) OR eq(score, 3)) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"gt":[{"_uid_":"0x3701"},{"_uid_":"0x3703"}],
+		"le":[{"_uid_":"0x3701"},{"_uid_":"0x3702"}],
+		"eq":[{"_uid_":"0x3703"}],
+		"and":[{"_uid_":"0x3702"}],
+		"or":[{"_uid_":"0x3701"},{"_uid_":"0x3702"}]}}`,
+		js)
+
+	// The index follows the changes of the facets of the edges.
+	addEdgeToUID(t, "rated", 0x3702, 0x3711, map[string]string{"score": "6"})
+	delEdgeToUID(t, "rated", 0x3701, 0x3711)
+	js = processToFastJSON(t, `{
+		me(func: has(rated)) @facets(gt(score, 4)) { _uid_ }
+	}`)
+	require.JSONEq(t, `{"data": {"me":[{"_uid_":"0x3702"},{"_uid_":"0x3703"}]}}`, js)
+
+	// Without an indexed facet, has needs the count index.
+	_, err := processToFastJsonReq(t, `{
+		me(func: has(rated)) @facets(not(gt(score, 4))) { _uid_ }
+	}`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Need @count directive in schema for attr: rated")
+}
+
+func TestKnn(t *testing.T) {
+	populateGraph(t)
+	query := `
+	{
+		me(func: knn(loc, [-1.0, 1.0], 2)) {
+			_uid_
+			distance(loc)
+		}
+		var(func: knn(loc, [-1.0, 1.0], 3)) {
+			d as distance(loc)
+		}
+		farthest(func: uid(d), orderdesc: val(d), first: 1) {
+			_uid_
+		}
+		filtered(func: uid(1, 24, 31)) @filter(knn(loc, [-1.0, 1.0], 1)) {
+			_uid_
+		}
+	}
+	`
+	js := processToFastJSON(t, query)
+	require.JSONEq(t, `{"data": {
+		"me":[{"_uid_":"0x17","distance(loc)":111177.989399},
+			{"_uid_":"0x1","distance(loc)":258557.871050}],
+		"farthest":[{"_uid_":"0x18"}],
+		"filtered":[{"_uid_":"0x1"}]}}`,
+		js)
+}
+
+func TestKnnErrors(t *testing.T) {
+	populateGraph(t)
+	for _, tc := range []struct {
+		fn  string
+		err string
+	}{
+		{`knn(name, [1.0, 2.0], 2)`, "is not indexed with type geo"},
+		{`knn(loc, [1.0, 2.0], 0)`, "knn function requires a positive number of neighbors"},
+		{`knn(loc, [[[0.0, 0.0], [1.0, 0.0], [1.0, 1.0], [0.0, 0.0]]], 2)`,
+			"knn function requires a point"},
+	} {
+		query := fmt.Sprintf(`{ me(func: %s) { _uid_ } }`, tc.fn)
+		_, err := processToFastJsonReq(t, query)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), tc.err)
+	}
+}
+
+func TestValueFuncsImplemented(t *testing.T) {
+	require.Equal(t, len(gql.ValueFuncs), len(valueFuncs))
+	for _, f := range gql.ValueFuncs {
+		require.Contains(t, valueFuncs, f)
+	}
+}
@@ -334,7 +334,9 @@ var typeMap = map[string]types.TypeID{
 	"xs:float":                                         types.FloatID,
 	"xs:base64Binary":                                  types.BinaryID,
 	"geo:geojson":                                      types.GeoID,
+	"geo:wktLiteral":                                   types.GeoID,
 	"pwd:password":                                     types.PasswordID,
+	"float32vector":                                    types.Float32VectorID,
 	"http://www.w3.org/2001/XMLSchema#string":          types.StringID,
 	"http://www.w3.org/2001/XMLSchema#dateTime":        types.DateTimeID,
 	"http://www.w3.org/2001/XMLSchema#date":            types.DateTimeID,
@@ -346,4 +348,5 @@ var typeMap = map[string]types.TypeID{
 	"http://www.w3.org/2001/XMLSchema#float":           types.FloatID,
 	"http://www.w3.org/2001/XMLSchema#gYear":           types.DateTimeID,
 	"http://www.w3.org/2001/XMLSchema#gYearMonth":      types.DateTimeID,
+	"http://www.opengis.net/ont/geosparql#wktLiteral":  types.GeoID,
 }
@@ -251,6 +251,10 @@ var testNQuads = []struct {
 		input:       `_:alice <age> "thirteen"^^<xs:int> .`,
 		expectedErr: true,
 	},
+	{
+		input:       `_:alice <loc> "POINT(1)"^^<geo:wktLiteral> .`,
+		expectedErr: true,
+	},
 	{
 		input:       `<alice> <knows> <*> .`,
 		expectedErr: true,
@@ -18,6 +18,7 @@
 package schema
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/dgraph-io/dgraph/lex"
@@ -53,6 +54,7 @@ func ParseBytes(s []byte, gid uint32) (rerr error) {
 		reset()
 	}
 	pstate.predicate = make(map[string]*protos.SchemaUpdate)
+	pstate.composites = make(map[string][]tok.CompositeIndex)
 	updates, err := Parse(string(s))
 	if err != nil {
 		return err
@@ -186,7 +188,8 @@ func parseIndexDirective(it *lex.ItemIterator, predicate string,
 	var seen = make(map[string]bool)
 	var seenSortableTok bool
 
-	if typ == types.UidID || typ == types.DefaultID || typ == types.PasswordID {
+	// The uid predicates can only have facet indexes, which are checked below.
+	if typ == types.DefaultID || typ == types.PasswordID {
 		return tokenizers, x.Errorf("Indexing not allowed on predicate %s of type %s",
 			predicate, typ.Name())
 	}
@@ -222,10 +225,53 @@ func parseIndexDirective(it *lex.ItemIterator, predicate string,
 		if !expectArg {
 			return tokenizers, x.Errorf("Expected a comma but got: %v", next)
 		}
+		name := strings.ToLower(next.Val)
+		if peek, ok := it.PeekOne(); ok && peek.Typ == itemLeftRound {
+			var err error
+			if name, err = parseTokenizerArgs(it, name); err != nil {
+				return tokenizers, err
+			}
+		}
+		if tok.IsComposite(name) {
+			ci, err := tok.ParseComposite(predicate, name)
+			if err != nil {
+				return tokenizers, err
+			}
+			if seen[ci.Name()] {
+				return tokenizers, x.Errorf("Duplicate tokenizers defined for pred %v",
+					predicate)
+			}
+			tokenizers = append(tokenizers, ci.Name())
+			seen[ci.Name()] = true
+			expectArg = false
+			continue
+		}
+		if tok.IsFacetIndex(name) {
+			if typ != types.UidID {
+				return tokenizers, x.Errorf("Facet index %s isn't valid for predicate: %s "+
+					"of type: %s", name, predicate, typ.Name())
+			}
+			fi, err := tok.ParseFacetIndex(name)
+			if err != nil {
+				return tokenizers, err
+			}
+			if seen[fi.Name()] {
+				return tokenizers, x.Errorf("Duplicate tokenizers defined for pred %v",
+					predicate)
+			}
+			tokenizers = append(tokenizers, fi.Name())
+			seen[fi.Name()] = true
+			expectArg = false
+			continue
+		}
+		if typ == types.UidID {
+			return tokenizers, x.Errorf("Predicate %s of type uid can only have facet "+
+				"indexes", predicate)
+		}
 		// Look for custom tokenizer.
-		tokenizer, has := tok.GetTokenizer(strings.ToLower(next.Val))
+		tokenizer, has := tok.GetTokenizer(name)
 		if !has {
-			return tokenizers, x.Errorf("Invalid tokenizer %s", next.Val)
+			return tokenizers, x.Errorf("Invalid tokenizer %s", name)
 		}
 		if tokenizer.Type() != typ {
 			return tokenizers,
@@ -250,18 +296,59 @@ func parseIndexDirective(it *lex.ItemIterator, predicate string,
 	return tokenizers, nil
 }
 
+// parseTokenizerArgs reads the arguments of a tokenizer, like the lengths in
+// edgengram(2, 15) or the options in fulltext(stemming: false), and returns the
+// name of the tokenizer with them.
+func parseTokenizerArgs(it *lex.ItemIterator, name string) (string, error) {
+	it.Next() // Skip the left round bracket.
+	var args []string
+	expectArg := true
+	for it.Next() {
+		next := it.Item()
+		switch {
+		case next.Typ == itemRightRound && !expectArg:
+			return fmt.Sprintf("%s(%s)", name, strings.Join(args, ",")), nil
+		case next.Typ == itemComma && !expectArg:
+			expectArg = true
+		case next.Typ == itemText && expectArg:
+			arg := next.Val
+			if peek, ok := it.PeekOne(); ok && peek.Typ == itemColon {
+				// The value of an option, which may be a file given as an IRI.
+				it.Next()
+				if !it.Next() || it.Item().Typ != itemText {
+					return "", x.Errorf("Invalid value of option %s for tokenizer %s",
+						arg, name)
+				}
+				arg = fmt.Sprintf("%s:<%s>", strings.ToLower(arg), it.Item().Val)
+			}
+			args = append(args, arg)
+			expectArg = false
+		default:
+			return "", x.Errorf("Invalid arguments for tokenizer %s: %v", name, next.Val)
+		}
+	}
+	return "", x.Errorf("Invalid ending in arguments for tokenizer %s", name)
+}
+
 // resolveTokenizers resolves default tokenizers and verifies tokenizers definitions.
 func resolveTokenizers(updates []*protos.SchemaUpdate) error {
+	preds := make(map[string]*protos.SchemaUpdate)
+	for _, schema := range updates {
+		preds[schema.Predicate] = schema
+	}
 	for _, schema := range updates {
 		typ := types.TypeID(schema.ValueType)
 
-		if (typ == types.UidID || typ == types.DefaultID || typ == types.PasswordID) &&
+		if (typ == types.DefaultID || typ == types.PasswordID) &&
 			schema.Directive == protos.SchemaUpdate_INDEX {
 			return x.Errorf("Indexing not allowed on predicate %s of type %s",
 				schema.Predicate, typ.Name())
 		}
 
 		if typ == types.UidID {
+			if err := checkFacetIndexes(schema); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -274,7 +361,14 @@ func resolveTokenizers(updates []*protos.SchemaUpdate) error {
 		// check for valid tokeniser types and duplicates
 		var seen = make(map[string]bool)
 		var seenSortableTok bool
-		for _, t := range schema.Tokenizer {
+		var fullText []tok.FullTextTokenizer
+		for i, t := range schema.Tokenizer {
+			if tok.IsComposite(t) {
+				if err := checkComposite(schema.Predicate, t, preds); err != nil {
+					return err
+				}
+				continue
+			}
 			tokenizer, has := tok.GetTokenizer(t)
 			if !has {
 				return x.Errorf("Invalid tokenizer %s", t)
@@ -288,6 +382,21 @@ func resolveTokenizers(updates []*protos.SchemaUpdate) error {
 			} else {
 				return x.Errorf("Duplicate tokenizers present for attr %s", schema.Predicate)
 			}
+			if ft, ok := tokenizer.(tok.FullTextTokenizer); ok {
+				fullText = append(fullText, ft)
+				if ft.Config != nil {
+					// Load the files of the analyzer configured for the
+					// predicate, and keep the digest of their current contents
+					// in the schema.
+					config := *ft.Config
+					config.Digest = ""
+					loaded, err := tok.FullTextTokenizer{Lang: ft.Lang, Config: &config}.Load()
+					if err != nil {
+						return x.Wrapf(err, "Invalid analyzer for attr %s", schema.Predicate)
+					}
+					schema.Tokenizer[i] = loaded.Name()
+				}
+			}
 			if tokenizer.IsSortable() {
 				if seenSortableTok {
 					return x.Errorf("More than one sortable index encountered for: %v",
@@ -296,6 +405,67 @@ func resolveTokenizers(updates []*protos.SchemaUpdate) error {
 				seenSortableTok = true
 			}
 		}
+		for _, ft := range fullText {
+			// The tokens of the full text indexes share the same identifier.
+			if ft.Config != nil && len(fullText) > 1 {
+				return x.Errorf("Configured fulltext index can't be used with another "+
+					"fulltext index for attr %s", schema.Predicate)
+			}
+		}
+	}
+	return nil
+}
+
+// checkFacetIndexes verifies the index of a uid predicate, which can only have
+// facet indexes.
+func checkFacetIndexes(schema *protos.SchemaUpdate) error {
+	if schema.Directive != protos.SchemaUpdate_INDEX {
+		if len(schema.Tokenizer) > 0 {
+			return x.Errorf("Tokenizers present without indexing on attr %s", schema.Predicate)
+		}
+		return nil
+	}
+	if len(schema.Tokenizer) == 0 {
+		return x.Errorf("Indexing not allowed on predicate %s of type uid without "+
+			"facet indexes", schema.Predicate)
+	}
+	seen := make(map[string]bool)
+	for _, t := range schema.Tokenizer {
+		fi, err := tok.ParseFacetIndex(t)
+		if err != nil {
+			return x.Errorf("Predicate %s of type uid can only have facet indexes",
+				schema.Predicate)
+		}
+		if seen[fi.Name()] {
+			return x.Errorf("Duplicate tokenizers present for attr %s", schema.Predicate)
+		}
+		seen[fi.Name()] = true
+	}
+	return nil
+}
+
+// checkComposite verifies the composite index of attr, whose predicates have to
+// be scalar and not lists. Only the predicates whose schema is given along can
+// be checked.
+func checkComposite(attr, name string, preds map[string]*protos.SchemaUpdate) error {
+	ci, err := tok.ParseComposite(attr, name)
+	if err != nil {
+		return err
+	}
+	for _, pred := range ci.Preds {
+		schema, ok := preds[pred]
+		if !ok {
+			continue
+		}
+		typ := types.TypeID(schema.ValueType)
+		if !typ.IsScalar() || typ == types.PasswordID {
+			return x.Errorf("Predicate %s of type %s can't be in composite index of %s",
+				pred, typ.Name(), attr)
+		}
+		if schema.List {
+			return x.Errorf("Predicate %s of type [%s] can't be in composite index of %s",
+				pred, typ.Name(), attr)
+		}
 	}
 	return nil
 }
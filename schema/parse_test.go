@@ -355,3 +355,141 @@ func TestParseUnderscore(t *testing.T) {
 	_, err := Parse("_share_:string @index(term) .")
 	require.NoError(t, err)
 }
+
+func TestParseEdgeNgram(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		name: string @index(edgengram) .
+		city: string @index(exact, edgengram(2, 15)) .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(schemas))
+	require.Equal(t, []string{"edgengram"}, schemas[0].Tokenizer)
+	require.Equal(t, []string{"exact", "edgengram(2,15)"}, schemas[1].Tokenizer)
+}
+
+func TestParseEdgeNgramError(t *testing.T) {
+	reset()
+	_, err := Parse("name: string @index(edgengram(5, 2)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid tokenizer edgengram(5,2)")
+
+	_, err = Parse("name: string @index(edgengram(2,)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid arguments for tokenizer edgengram")
+	// Numbers are only lexed in the arguments of tokenizers.
+	_, err = Parse("1pred: string .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Unexpected 1")
+
+	_, err = Parse("name: string @index(2) .")
+	require.Error(t, err)
+}
+
+func TestParseAnalyzer(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		text: string @index(fulltext(stemming: false)) .
+		title: string @index(fulltextde(Stemming: true)) .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(schemas))
+	require.Equal(t, []string{"fulltext(stemming:false)"}, schemas[0].Tokenizer)
+	require.Equal(t, []string{"fulltextde"}, schemas[1].Tokenizer)
+}
+
+func TestParseAnalyzerError(t *testing.T) {
+	reset()
+	_, err := Parse("text: string @index(fulltext(stemmer: false)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid tokenizer fulltext(stemmer:<false>)")
+
+	_, err = Parse("text: string @index(fulltext(stemming:)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Invalid value of option stemming for tokenizer fulltext")
+
+	_, err = Parse("text: string @index(fulltext, fulltext(stemming: false)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Configured fulltext index can't be used with another")
+}
+
+func TestParseComposite(t *testing.T) {
+	reset()
+	schemas, err := Parse(`
+		country: string @index(exact, composite(status, city)) .
+		status: string .
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(schemas))
+	require.Equal(t, []string{"exact", "composite(status,city)"}, schemas[0].Tokenizer)
+}
+
+func TestCompositeIndexes(t *testing.T) {
+	require.NoError(t, ParseBytes([]byte(`
+		country: string @index(exact, composite(status, city)) .
+		status: string @index(composite(city)) .
+		city: string .
+	`), 1))
+	require.Len(t, State().CompositeIndexes("country"), 1)
+	require.Len(t, State().CompositeIndexes("status"), 2)
+	require.Len(t, State().CompositeIndexes("city"), 2)
+
+	// Setting the schema of a predicate replaces the indexes it declares.
+	State().Set("status", protos.SchemaUpdate{ValueType: uint32(types.StringID)})
+	require.Len(t, State().CompositeIndexes("status"), 1)
+	require.Len(t, State().CompositeIndexes("city"), 1)
+	require.Equal(t, "country", State().CompositeIndexes("city")[0].Preds[0])
+}
+
+func TestParseCompositeError(t *testing.T) {
+	reset()
+	_, err := Parse("country: string @index(composite(status, status)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Predicate status is repeated in composite index")
+
+	_, err = Parse(`
+		country: string @index(composite(friend)) .
+		friend: uid .
+	`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Predicate friend of type uid can't be in composite index")
+
+	_, err = Parse(`
+		country: string @index(composite(tags)) .
+		tags: [string] .
+	`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Predicate tags of type [string] can't be in composite index")
+
+	_, err = Parse("countries: [string] @index(composite(status)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Predicate countries of type [string] can't be in composite index")
+}
+
+func TestParseFacetIndex(t *testing.T) {
+	reset()
+	schemas, err := Parse("rated: uid @index(facet(score), facet(since)) @count .")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(schemas))
+	require.Equal(t, []string{"facet(score)", "facet(since)"}, schemas[0].Tokenizer)
+	require.True(t, schemas[0].Count)
+}
+
+func TestParseFacetIndexError(t *testing.T) {
+	reset()
+	_, err := Parse("rated: uid @index(exact) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Predicate rated of type uid can only have facet indexes")
+
+	_, err = Parse("name: string @index(facet(score)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Facet index facet(score) isn't valid for predicate: name")
+
+	_, err = Parse("rated: uid @index(facet(score), facet(score)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Duplicate tokenizers defined for pred rated")
+
+	_, err = Parse("rated: uid @index(facet(score, since)) .")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "should have a single facet key")
+}
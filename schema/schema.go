@@ -46,14 +46,48 @@ type stateGroup struct {
 	sync.RWMutex // x.SafeMutex is slow.
 	// Map containing predicate to type information.
 	predicate map[string]*protos.SchemaUpdate
-	elog      trace.EventLog
+	// Map containing predicate to the composite indexes having it, computed
+	// whenever the schema changes.
+	composites map[string][]tok.CompositeIndex
+	elog       trace.EventLog
 }
 
 func (s *stateGroup) init() {
 	s.predicate = make(map[string]*protos.SchemaUpdate)
+	s.composites = make(map[string][]tok.CompositeIndex)
 	s.elog = trace.NewEventLog("Dgraph", "Schema")
 }
 
+// indexComposites updates the composite indexes having every predicate, after
+// the schema of attr was set. The slices are replaced rather than modified, as
+// they are returned by CompositeIndexes. The lock must be held.
+func (s *stateGroup) indexComposites(attr string) {
+	for pred, cis := range s.composites {
+		var out []tok.CompositeIndex
+		for _, ci := range cis {
+			if ci.Preds[0] != attr {
+				out = append(out, ci)
+			}
+		}
+		if len(out) == 0 {
+			delete(s.composites, pred)
+		} else if len(out) < len(cis) {
+			s.composites[pred] = out
+		}
+	}
+	for _, it := range s.predicate[attr].Tokenizer {
+		if !tok.IsComposite(it) {
+			continue
+		}
+		ci, err := tok.ParseComposite(attr, it)
+		x.AssertTruef(err == nil, "Invalid composite index %s", it)
+		for _, p := range ci.Preds {
+			cis := s.composites[p]
+			s.composites[p] = append(cis[:len(cis):len(cis)], ci)
+		}
+	}
+}
+
 type state struct {
 	sync.RWMutex
 	m    map[uint32]*stateGroup
@@ -72,6 +106,7 @@ func (s *stateGroup) Update(se SyncEntry) {
 	defer s.Unlock()
 
 	s.predicate[se.Attr] = &se.Schema
+	s.indexComposites(se.Attr)
 	se.Water.Begin(se.Index)
 	syncCh <- se
 	s.elog.Printf(logUpdate(se.Schema, se.Attr))
@@ -94,6 +129,7 @@ func (s *stateGroup) Set(pred string, schema protos.SchemaUpdate) {
 	s.Lock()
 	defer s.Unlock()
 	s.predicate[pred] = &schema
+	s.indexComposites(pred)
 	s.elog.Printf(logUpdate(schema, pred))
 }
 
@@ -152,7 +188,8 @@ func (s *stateGroup) Predicates() []string {
 	return out
 }
 
-// Tokenizer returns the tokenizer for given predicate
+// Tokenizer returns the tokenizer for given predicate. The composite and facet
+// indexes aren't tokenizers and are left out.
 func (s *stateGroup) Tokenizer(pred string) []tok.Tokenizer {
 	s.RLock()
 	defer s.RUnlock()
@@ -160,6 +197,9 @@ func (s *stateGroup) Tokenizer(pred string) []tok.Tokenizer {
 	x.AssertTruef(ok, "schema state not found for %s", pred)
 	var tokenizers []tok.Tokenizer
 	for _, it := range schema.Tokenizer {
+		if tok.IsComposite(it) || tok.IsFacetIndex(it) {
+			continue
+		}
 		t, has := tok.GetTokenizer(it)
 		x.AssertTruef(has, "Invalid tokenizer %s", it)
 		tokenizers = append(tokenizers, t)
@@ -175,6 +215,10 @@ func (s *stateGroup) TokenizerNames(pred string) []string {
 	x.AssertTruef(ok, "schema state not found for %s", pred)
 	var tokenizers []string
 	for _, it := range schema.Tokenizer {
+		if tok.IsComposite(it) || tok.IsFacetIndex(it) {
+			tokenizers = append(tokenizers, it)
+			continue
+		}
 		t, found := tok.GetTokenizer(it)
 		x.AssertTruef(found, "Tokenizer not found for %s", it)
 		tokenizers = append(tokenizers, t.Name())
@@ -182,6 +226,34 @@ func (s *stateGroup) TokenizerNames(pred string) []string {
 	return tokenizers
 }
 
+// CompositeIndexes returns the composite indexes having the predicate, either
+// declared by it or by another predicate. The slice must not be modified.
+func (s *stateGroup) CompositeIndexes(pred string) []tok.CompositeIndex {
+	s.RLock()
+	defer s.RUnlock()
+	return s.composites[pred]
+}
+
+// FacetIndexes returns the indexes of the facets of the uid predicate.
+func (s *stateGroup) FacetIndexes(pred string) []tok.FacetIndex {
+	s.RLock()
+	defer s.RUnlock()
+	schema, ok := s.predicate[pred]
+	if !ok {
+		return nil
+	}
+	var out []tok.FacetIndex
+	for _, it := range schema.Tokenizer {
+		if !tok.IsFacetIndex(it) {
+			continue
+		}
+		fi, err := tok.ParseFacetIndex(it)
+		x.AssertTruef(err == nil, "Invalid facet index %s", it)
+		out = append(out, fi)
+	}
+	return out
+}
+
 // IsReversed returns whether the predicate has reverse edge or not
 func (s *stateGroup) IsReversed(pred string) bool {
 	s.RLock()
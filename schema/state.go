@@ -47,6 +47,11 @@ Loop:
 		case isNameBegin(r):
 			l.Backup()
 			return lexWord
+		case r >= '0' && r <= '9' && l.ArgDepth > 1:
+			// Only the arguments of a tokenizer, e.g. edgengram(2, 15) in
+			// @index(...), can be numbers.
+			l.Backup()
+			return lexWord
 		case isSpace(r):
 			l.Ignore()
 		case isEndOfLine(r):
@@ -65,8 +70,12 @@ Loop:
 			l.Emit(itemRightCurl)
 		case r == '(':
 			l.Emit(itemLeftRound)
+			l.ArgDepth++
 		case r == ')':
 			l.Emit(itemRightRound)
+			if l.ArgDepth > 0 {
+				l.ArgDepth--
+			}
 		case r == ':':
 			l.Emit(itemColon)
 		case r == '@':
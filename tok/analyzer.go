@@ -0,0 +1,462 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/analysis/token/porter"
+	"github.com/blevesearch/bleve/analysis/token/stop"
+	"github.com/blevesearch/bleve/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/analysis/tokenmap"
+	"github.com/blevesearch/bleve/registry"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const synonymFilterName = "synonyms"
+
+// AnalyzerConfig changes how the values of a full text index are analyzed. It
+// is set in the schema with the arguments of the tokenizer, like
+// fulltext(stopwords: <medical_stopwords.txt>, synonyms: <medical.txt>, stemming: false).
+type AnalyzerConfig struct {
+	// Stopwords is a file of the analyzer directory with a stop word per line,
+	// which replace the stop words of the language.
+	Stopwords string
+	// Synonyms is a file of the analyzer directory with a group of comma
+	// separated synonyms per line. Every word of a group is indexed and
+	// searched as the first one.
+	Synonyms string
+	// NoStem disables the stemming of the words.
+	NoStem bool
+	// Digest is the checksum of the contents of the files. It is added when
+	// the schema is parsed, so that every server checks that it has the same
+	// files, and that the index is rebuilt when they change.
+	Digest string
+}
+
+// args returns the arguments of the tokenizer for the configuration.
+func (c *AnalyzerConfig) args() string {
+	var args []string
+	if c.NoStem {
+		args = append(args, "stemming:false")
+	}
+	if c.Stopwords != "" {
+		args = append(args, "stopwords:<"+c.Stopwords+">")
+	}
+	if c.Synonyms != "" {
+		args = append(args, "synonyms:<"+c.Synonyms+">")
+	}
+	if c.Digest != "" {
+		args = append(args, "digest:<"+c.Digest+">")
+	}
+	return "(" + strings.Join(args, ",") + ")"
+}
+
+// analyzers keeps the analyzers of the configured full text tokenizers by
+// name. The map is replaced by a copy under the lock when an analyzer is
+// added, so that it can be read by the tokenizers without locking.
+var analyzers = struct {
+	sync.Mutex
+	defined  map[string]bool
+	prepared atomic.Value // map[string]*analysis.Analyzer
+}{defined: make(map[string]bool)}
+
+// define calls fn to add the item to the bleve cache, once for every name. The
+// lock of analyzers must be held.
+func define(name string, fn func() error) error {
+	if analyzers.defined[name] {
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	analyzers.defined[name] = true
+	return nil
+}
+
+// analyzer returns the analyzer of the tokenizer, loading the files of its
+// configuration the first time.
+func (t FullTextTokenizer) analyzer() (*analysis.Analyzer, error) {
+	if t.Config == nil {
+		return bleveCache.AnalyzerNamed(t.Name())
+	}
+	if prepared, ok := analyzers.prepared.Load().(map[string]*analysis.Analyzer); ok {
+		if analyzer, ok := prepared[t.Name()]; ok {
+			return analyzer, nil
+		}
+	}
+	if _, err := t.Load(); err != nil {
+		return nil, err
+	}
+	return analyzers.prepared.Load().(map[string]*analysis.Analyzer)[t.Name()], nil
+}
+
+// Load reads the files of the configuration of the tokenizer and defines its
+// analyzer. It returns the tokenizer with the digest of the files, which must
+// be the one of the tokenizer if it has any.
+func (t FullTextTokenizer) Load() (FullTextTokenizer, error) {
+	if t.Config == nil {
+		return t, nil
+	}
+	analyzers.Lock()
+	defer analyzers.Unlock()
+
+	cjk := isCJKLang(t.Lang)
+	filters := []string{lowercase.Name, normalizerName}
+	if cjk {
+		filters = append(filters, cjkBigramName)
+	}
+	digest := fnv.New64a()
+	if t.Config.Synonyms != "" {
+		data, err := readAnalyzerFile(t.Config.Synonyms)
+		if err != nil {
+			return t, err
+		}
+		name, err := defineSynonyms(t.Config.Synonyms, data)
+		if err != nil {
+			return t, err
+		}
+		filters = append(filters, name)
+		digest.Write(data)
+	}
+	if t.Config.Stopwords != "" {
+		data, err := readAnalyzerFile(t.Config.Stopwords)
+		if err != nil {
+			return t, err
+		}
+		name, err := defineStopWordsFile(t.Config.Stopwords, data)
+		if err != nil {
+			return t, err
+		}
+		filters = append(filters, name)
+		// The separator tells a stop words file apart from a synonyms file
+		// with the same contents.
+		digest.Write([]byte{0})
+		digest.Write(data)
+	} else if !cjk {
+		filters = append(filters, stopWordsListName(t.stopWordsLang()))
+	}
+	if !t.Config.NoStem && !cjk {
+		if t.Lang == "" {
+			filters = append(filters, porter.Name)
+		} else {
+			filters = append(filters, stemmerName(t.Lang))
+		}
+	}
+
+	config := *t.Config
+	if config.Stopwords != "" || config.Synonyms != "" {
+		config.Digest = fmt.Sprintf("%016x", digest.Sum64())
+	}
+	if t.Config.Digest != "" && t.Config.Digest != config.Digest {
+		return t, x.Errorf("The analyzer files of %s have changed since the schema was set",
+			t.Name())
+	}
+	loaded := FullTextTokenizer{Lang: t.Lang, Config: &config}
+	name := loaded.Name()
+	if err := define(name, func() error {
+		_, err := bleveCache.DefineAnalyzer(name, map[string]interface{}{
+			"type":          custom.Name,
+			"tokenizer":     unicode.Name,
+			"token_filters": filters,
+		})
+		return err
+	}); err != nil {
+		return t, err
+	}
+	analyzer, err := bleveCache.AnalyzerNamed(name)
+	if err != nil {
+		return t, err
+	}
+
+	old, _ := analyzers.prepared.Load().(map[string]*analysis.Analyzer)
+	prepared := make(map[string]*analysis.Analyzer, len(old)+2)
+	for k, v := range old {
+		prepared[k] = v
+	}
+	prepared[name] = analyzer
+	prepared[t.Name()] = analyzer
+	analyzers.prepared.Store(prepared)
+	return loaded, nil
+}
+
+func (t FullTextTokenizer) stopWordsLang() string {
+	if t.Lang == "" {
+		return "en"
+	}
+	return t.Lang
+}
+
+// ForLang returns the tokenizer used for the values in the language, with the
+// same configuration.
+func (t FullTextTokenizer) ForLang(lang string) (FullTextTokenizer, error) {
+	if _, ok := tokenizers[FtsTokenizerName(lang)]; !ok {
+		return t, x.Errorf("Tokenizer not available for language: %s", lang)
+	}
+	return FullTextTokenizer{Lang: lang, Config: t.Config}, nil
+}
+
+// readAnalyzerFile returns the contents of a file of the analyzer directory.
+// The file can't be outside of the directory, even through a symbolic link.
+func readAnalyzerFile(file string) ([]byte, error) {
+	if Config.AnalyzerDir == "" {
+		return nil, x.Errorf("Analyzer file %s can't be used without an analyzer directory", file)
+	}
+	if filepath.IsAbs(file) {
+		return nil, x.Errorf("Analyzer file %s must be relative to the analyzer directory", file)
+	}
+	dir, err := filepath.EvalSymlinks(Config.AnalyzerDir)
+	if err != nil {
+		return nil, x.Errorf("Analyzer directory %s doesn't exist", Config.AnalyzerDir)
+	}
+	path := filepath.Join(dir, file)
+	if !inDir(dir, path) {
+		return nil, x.Errorf("Analyzer file %s is outside of the analyzer directory", file)
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return nil, x.Errorf("Analyzer file %s doesn't exist", file)
+	}
+	if !inDir(dir, path) {
+		return nil, x.Errorf("Analyzer file %s is outside of the analyzer directory", file)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, x.Errorf("Unable to read analyzer file %s", file)
+	}
+	return data, nil
+}
+
+// inDir returns true if the path is in the directory or one of its
+// subdirectories. Both have to be clean.
+func inDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// analyzerLines calls fn for the lines of the file, without the empty ones and
+// the comments starting with #. The lines are numbered from 1.
+func analyzerLines(data []byte, fn func(num int, line string) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(num, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// normalizeWord returns the word as the term analyzer outputs it. The word
+// isn't part of the error, which is returned to the client.
+func normalizeWord(file string, num int, word string) (string, error) {
+	words, err := TermWords(word)
+	if err != nil {
+		return "", err
+	}
+	if len(words) != 1 {
+		return "", x.Errorf("Expected a single word on line %d of %s", num, file)
+	}
+	return words[0], nil
+}
+
+// fileDigest returns the checksum of the contents of a file, which names the
+// items of the bleve cache defined for it.
+func fileDigest(data []byte) string {
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func defineStopWordsFile(file string, data []byte) (string, error) {
+	name := stop.Name + ":" + fileDigest(data)
+	return name, define(name, func() error {
+		words := make([]interface{}, 0)
+		if err := analyzerLines(data, func(num int, line string) error {
+			word, err := normalizeWord(file, num, line)
+			if err != nil {
+				return err
+			}
+			words = append(words, word)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if _, err := bleveCache.DefineTokenMap(name, map[string]interface{}{
+			"type":   tokenmap.Name,
+			"tokens": words,
+		}); err != nil {
+			return err
+		}
+		_, err := bleveCache.DefineTokenFilter(name, map[string]interface{}{
+			"type":           stop.Name,
+			"stop_token_map": name,
+		})
+		return err
+	})
+}
+
+func defineSynonyms(file string, data []byte) (string, error) {
+	name := synonymFilterName + ":" + fileDigest(data)
+	return name, define(name, func() error {
+		synonyms := make(map[string]string)
+		if err := analyzerLines(data, func(num int, line string) error {
+			var first string
+			for _, entry := range strings.Split(line, ",") {
+				word, err := normalizeWord(file, num, entry)
+				if err != nil {
+					return err
+				}
+				if first == "" {
+					first = word
+				}
+				if prev, ok := synonyms[word]; ok && prev != first {
+					return x.Errorf("A word on line %d of %s is in another group of synonyms",
+						num, file)
+				}
+				synonyms[word] = first
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		_, err := bleveCache.DefineTokenFilter(name, map[string]interface{}{
+			"type":     synonymFilterName,
+			"synonyms": synonyms,
+		})
+		return err
+	})
+}
+
+// synonymFilter replaces the words by the first word of their synonyms.
+type synonymFilter struct {
+	synonyms map[string]string
+}
+
+func (f *synonymFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		if s, ok := f.synonyms[string(token.Term)]; ok {
+			token.Term = []byte(s)
+		}
+	}
+	return input
+}
+
+func synonymFilterConstructor(config map[string]interface{},
+	cache *registry.Cache) (analysis.TokenFilter, error) {
+	synonyms, ok := config["synonyms"].(map[string]string)
+	if !ok {
+		return nil, x.Errorf("Synonyms are missing in the configuration of the filter")
+	}
+	return &synonymFilter{synonyms: synonyms}, nil
+}
+
+func init() {
+	registry.RegisterTokenFilter(synonymFilterName, synonymFilterConstructor)
+}
+
+// parseFullText returns the full text tokenizer for names like
+// fulltextde(stemming:false,synonyms:<synonyms.txt>).
+func parseFullText(name string) (Tokenizer, bool) {
+	open := strings.IndexByte(name, '(')
+	if open < 0 || !strings.HasSuffix(name, ")") {
+		return nil, false
+	}
+	base, ok := tokenizers[name[:open]].(FullTextTokenizer)
+	if !ok {
+		return nil, false
+	}
+	args, ok := splitArgs(name[open+1 : len(name)-1])
+	if !ok || len(args) == 0 {
+		return nil, false
+	}
+	config := &AnalyzerConfig{}
+	for _, arg := range args {
+		kv := strings.SplitN(arg, ":", 2)
+		if len(kv) != 2 {
+			return nil, false
+		}
+		key := strings.TrimSpace(kv[0])
+		val := strings.TrimSpace(kv[1])
+		if strings.HasPrefix(val, "<") && strings.HasSuffix(val, ">") {
+			val = val[1 : len(val)-1]
+		}
+		if val == "" {
+			return nil, false
+		}
+		switch key {
+		case "stemming":
+			stem, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, false
+			}
+			config.NoStem = !stem
+		case "stopwords":
+			config.Stopwords = val
+		case "synonyms":
+			config.Synonyms = val
+		case "digest":
+			config.Digest = val
+		default:
+			return nil, false
+		}
+	}
+	if *config == (AnalyzerConfig{}) {
+		return base, true
+	}
+	return FullTextTokenizer{Lang: base.Lang, Config: config}, true
+}
+
+// splitArgs splits the arguments on the commas which aren't in angle brackets.
+func splitArgs(s string) ([]string, bool) {
+	var args []string
+	var arg bytes.Buffer
+	inIRI := false
+	for _, r := range s {
+		switch {
+		case r == '<' && !inIRI:
+			inIRI = true
+		case r == '>' && inIRI:
+			inIRI = false
+		case r == ',' && !inIRI:
+			args = append(args, arg.String())
+			arg.Reset()
+			continue
+		}
+		arg.WriteRune(r)
+	}
+	if inIRI {
+		return nil, false
+	}
+	return append(args, arg.String()), true
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/registry"
+)
+
+const cjkBigramName = "cjk_bigram"
+
+// Chinese, Japanese and Korean are written without spaces between words, so
+// their text is indexed as bigrams of characters.
+var cjkLangs = []string{"chinese", "simplifiedchinese", "traditionalchinese", "japanese",
+	"korean"}
+
+func isCJKLang(lang string) bool {
+	return lang == "zh" || strings.HasPrefix(lang, "zh-") || lang == "ja" || lang == "ko"
+}
+
+func isCJK(r rune) bool {
+	// The prolonged sound and iteration marks are common to several scripts.
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		r == 'ー' || r == '々'
+}
+
+type cjkChar struct {
+	r          rune
+	start, end int
+}
+
+// cjkBigramFilter replaces the tokens written in CJK scripts by the bigrams of
+// their characters. Characters of adjacent tokens are paired too, as the
+// tokenizer splits Chinese text at every character. A character which isn't
+// adjacent to another one is kept as it is. Tokens are numbered again, so that
+// the positions of adjacent bigrams differ by one.
+type cjkBigramFilter struct{}
+
+func (f *cjkBigramFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	output := make(analysis.TokenStream, 0, len(input))
+	var run []cjkChar
+	var runEnd int // End of the last token of the run in the input.
+	flush := func() {
+		if len(run) == 1 {
+			output = append(output, &analysis.Token{
+				Term:  []byte(string(run[0].r)),
+				Start: run[0].start,
+				End:   run[0].end,
+				Type:  analysis.Single,
+			})
+		}
+		for i := 0; i+1 < len(run); i++ {
+			output = append(output, &analysis.Token{
+				Term:  []byte(string([]rune{run[i].r, run[i+1].r})),
+				Start: run[i].start,
+				End:   run[i+1].end,
+				Type:  analysis.Double,
+			})
+		}
+		run = run[:0]
+	}
+
+	for _, token := range input {
+		if !allCJK(token.Term) {
+			flush()
+			output = append(output, token)
+			continue
+		}
+		if len(run) > 0 && runEnd != token.Start {
+			flush()
+		}
+		runEnd = token.End
+		offset := token.Start
+		for _, r := range string(token.Term) {
+			size := utf8.RuneLen(r)
+			run = append(run, cjkChar{r: r, start: offset, end: offset + size})
+			offset += size
+		}
+	}
+	flush()
+
+	for i, token := range output {
+		token.Position = i + 1
+	}
+	return output
+}
+
+func allCJK(term []byte) bool {
+	if len(term) == 0 {
+		return false
+	}
+	for _, r := range string(term) {
+		if !isCJK(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func cjkBigramFilterConstructor(config map[string]interface{},
+	cache *registry.Cache) (analysis.TokenFilter, error) {
+	return &cjkBigramFilter{}, nil
+}
+
+func init() {
+	registry.RegisterTokenFilter(cjkBigramName, cjkBigramFilterConstructor)
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const compositeName = "composite"
+
+// CompositeIndex indexes the values of several scalar predicates of the same
+// node together, so that the nodes with given values for all of them are found
+// with a single lookup. It's declared in the index of the first predicate,
+// with the other ones as arguments, like country: string @index(exact,
+// composite(status)). Its tokens are kept in the index of the first predicate.
+type CompositeIndex struct {
+	// Preds are the predicates whose values are indexed, the one declaring the
+	// index first.
+	Preds []string
+}
+
+// IsComposite returns true if name is the one of a composite index, which
+// isn't a tokenizer.
+func IsComposite(name string) bool {
+	return strings.HasPrefix(name, compositeName+"(")
+}
+
+// ParseComposite returns the composite index named like composite(status,city)
+// in the index of attr.
+func ParseComposite(attr, name string) (CompositeIndex, error) {
+	if !IsComposite(name) || !strings.HasSuffix(name, ")") {
+		return CompositeIndex{}, x.Errorf("Invalid composite index %s", name)
+	}
+	ci := CompositeIndex{Preds: []string{attr}}
+	seen := map[string]bool{attr: true}
+	for _, pred := range strings.Split(name[len(compositeName)+1:len(name)-1], ",") {
+		pred = strings.TrimSpace(pred)
+		if len(pred) == 0 {
+			return CompositeIndex{}, x.Errorf("Missing predicate in composite index %s", name)
+		}
+		if seen[pred] {
+			return CompositeIndex{}, x.Errorf("Predicate %s is repeated in composite index %s",
+				pred, name)
+		}
+		seen[pred] = true
+		ci.Preds = append(ci.Preds, pred)
+	}
+	return ci, nil
+}
+
+func (ci CompositeIndex) Name() string {
+	return compositeName + "(" + strings.Join(ci.Preds[1:], ",") + ")"
+}
+func (ci CompositeIndex) Identifier() byte { return 0x13 }
+
+// Tokens returns the tokens of the values of every predicate, given in the
+// order of Preds and converted to their schema types. There is a token for
+// every combination of the values of predicates of list type, and none if a
+// predicate has no value.
+func (ci CompositeIndex) Tokens(vals [][]types.Val) ([]string, error) {
+	x.AssertTrue(len(vals) == len(ci.Preds))
+	keys := []string{""}
+	for _, pv := range vals {
+		var next []string
+		for _, v := range pv {
+			data := types.ValueForType(types.BinaryID)
+			if err := types.Marshal(v, &data); err != nil {
+				return nil, err
+			}
+			// The values are prefixed with their length, so that the keys of
+			// different values can't be the same.
+			var buf bytes.Buffer
+			var n [binary.MaxVarintLen64]byte
+			value := data.Value.([]byte)
+			buf.Write(n[:binary.PutUvarint(n[:], uint64(len(value)))])
+			buf.Write(value)
+			for _, key := range keys {
+				next = append(next, key+buf.String())
+			}
+		}
+		keys = next
+	}
+	tokens := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tokens = append(tokens, encodeToken(key, ci.Identifier()))
+	}
+	return tokens, nil
+}
+
+// Prefix returns the prefix of all the tokens of composite indexes.
+func (ci CompositeIndex) Prefix() string {
+	return encodeToken("", ci.Identifier())
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const facetName = "facet"
+
+// FacetIndex indexes the values of a facet of the edges of a uid predicate,
+// to find the nodes with edges having given facet values. It's declared in
+// the index of the predicate, like rated: uid @index(facet(score)). The tokens
+// of the values of every type sort like the values.
+type FacetIndex struct {
+	Key string
+}
+
+// IsFacetIndex returns true if name is the one of a facet index, which isn't a
+// tokenizer.
+func IsFacetIndex(name string) bool {
+	return strings.HasPrefix(name, facetName+"(")
+}
+
+// ParseFacetIndex returns the facet index named like facet(score).
+func ParseFacetIndex(name string) (FacetIndex, error) {
+	if !IsFacetIndex(name) || !strings.HasSuffix(name, ")") {
+		return FacetIndex{}, x.Errorf("Invalid facet index %s", name)
+	}
+	key := strings.TrimSpace(name[len(facetName)+1 : len(name)-1])
+	if len(key) == 0 || strings.ContainsAny(key, ",:") {
+		return FacetIndex{}, x.Errorf("Facet index %s should have a single facet key", name)
+	}
+	return FacetIndex{Key: key}, nil
+}
+
+func (fi FacetIndex) Name() string     { return facetName + "(" + fi.Key + ")" }
+func (fi FacetIndex) Identifier() byte { return 0x14 }
+
+// Prefix returns the prefix of the tokens of the facet values of type typ.
+func (fi FacetIndex) Prefix(typ types.TypeID) string {
+	var n [binary.MaxVarintLen64]byte
+	key := string(n[:binary.PutUvarint(n[:], uint64(len(fi.Key)))]) + fi.Key
+	return encodeToken(key+string([]byte{byte(typ)}), fi.Identifier())
+}
+
+// Token returns the token of a facet value.
+func (fi FacetIndex) Token(v types.Val) (string, error) {
+	var b []byte
+	switch v.Tid {
+	case types.IntID:
+		b = make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(v.Value.(int64))^(1<<63))
+	case types.FloatID:
+		bits := math.Float64bits(v.Value.(float64))
+		if bits&(1<<63) == 0 {
+			bits ^= 1 << 63
+		} else {
+			bits = ^bits
+		}
+		b = make([]byte, 8)
+		binary.BigEndian.PutUint64(b, bits)
+	case types.DateTimeID:
+		t := v.Value.(time.Time)
+		b = make([]byte, 12)
+		binary.BigEndian.PutUint64(b, uint64(t.Unix())^(1<<63))
+		binary.BigEndian.PutUint32(b[8:], uint32(t.Nanosecond()))
+	case types.BoolID:
+		b = []byte{0}
+		if v.Value.(bool) {
+			b[0] = 1
+		}
+	case types.StringID:
+		b = []byte(v.Value.(string))
+	default:
+		return "", x.Errorf("Facet index of %s isn't supported for type %s", fi.Key,
+			v.Tid.Name())
+	}
+	return fi.Prefix(v.Tid) + string(b), nil
+}
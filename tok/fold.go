@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// FoldRune returns the folded case of r. The runes which only differ by case,
+// like k, K and the Kelvin sign, have the same folded case.
+func FoldRune(r rune) rune {
+	return unicode.ToLower(unicode.ToUpper(r))
+}
+
+// FoldCase returns s with the case of its letters folded.
+func FoldCase(s string) string {
+	return strings.Map(FoldRune, s)
+}
+
+// FoldsCase returns true if t is one of the case insensitive tokenizers, which
+// fold the case of the values before indexing them.
+func FoldsCase(t Tokenizer) bool {
+	switch t.(type) {
+	case FoldedExactTokenizer, FoldedHashTokenizer, FoldedTrigramTokenizer:
+		return true
+	}
+	return false
+}
+
+// foldedTokens returns the tokens of base for the value with its case folded,
+// encoded with the identifier of t.
+func foldedTokens(base, t Tokenizer, sv types.Val) ([]string, error) {
+	term, ok := sv.Value.(string)
+	if !ok {
+		return nil, x.Errorf("Tokenizer %s only supported for string types", t.Name())
+	}
+	tokens, err := base.Tokens(types.Val{Tid: types.StringID, Value: FoldCase(term)})
+	if err != nil {
+		return nil, err
+	}
+	for i, token := range tokens {
+		tokens[i] = encodeToken(token[1:], t.Identifier())
+	}
+	return tokens, nil
+}
+
+// FoldedExactTokenizer is the case insensitive variant of ExactTokenizer. The
+// eq function and sorting ignore the case of the values indexed with it.
+type FoldedExactTokenizer struct{}
+
+func (t FoldedExactTokenizer) Name() string       { return "iexact" }
+func (t FoldedExactTokenizer) Type() types.TypeID { return types.StringID }
+func (t FoldedExactTokenizer) Tokens(sv types.Val) ([]string, error) {
+	return foldedTokens(ExactTokenizer{}, t, sv)
+}
+func (t FoldedExactTokenizer) Identifier() byte { return 0xF }
+func (t FoldedExactTokenizer) IsSortable() bool { return true }
+func (t FoldedExactTokenizer) IsLossy() bool    { return true }
+
+// FoldedHashTokenizer is the case insensitive variant of HashTokenizer.
+type FoldedHashTokenizer struct{}
+
+func (t FoldedHashTokenizer) Name() string       { return "ihash" }
+func (t FoldedHashTokenizer) Type() types.TypeID { return types.StringID }
+func (t FoldedHashTokenizer) Tokens(sv types.Val) ([]string, error) {
+	return foldedTokens(HashTokenizer{}, t, sv)
+}
+func (t FoldedHashTokenizer) Identifier() byte { return 0x10 }
+func (t FoldedHashTokenizer) IsSortable() bool { return false }
+func (t FoldedHashTokenizer) IsLossy() bool    { return true }
+
+// FoldedTrigramTokenizer is the case insensitive variant of TrigramTokenizer,
+// used by regexp(/.../i) to look up a single trigram for all its cases.
+type FoldedTrigramTokenizer struct{}
+
+func (t FoldedTrigramTokenizer) Name() string       { return "itrigram" }
+func (t FoldedTrigramTokenizer) Type() types.TypeID { return types.StringID }
+func (t FoldedTrigramTokenizer) Tokens(sv types.Val) ([]string, error) {
+	return foldedTokens(TrigramTokenizer{}, t, sv)
+}
+func (t FoldedTrigramTokenizer) Identifier() byte { return 0x11 }
+func (t FoldedTrigramTokenizer) IsSortable() bool { return false }
+func (t FoldedTrigramTokenizer) IsLossy() bool    { return true }
+
+// EncodeTokens prefixes the tokens with the identifier of a tokenizer.
+func EncodeTokens(tokens []string, id byte) {
+	for i := range tokens {
+		tokens[i] = encodeToken(tokens[i], id)
+	}
+}
@@ -60,7 +60,12 @@ func initFullTextTokenizers() {
 		defineStemmer(lang)
 		defineStopWordsList(lang)
 		defineAnalyzer(lang)
-		RegisterTokenizer(&FullTextTokenizer{Lang: countryCode(lang)})
+		RegisterTokenizer(FullTextTokenizer{Lang: countryCode(lang)})
+	}
+
+	for _, lang := range cjkLangs {
+		defineAnalyzer(lang)
+		RegisterTokenizer(FullTextTokenizer{Lang: countryCode(lang)})
 	}
 
 	// Default full text tokenizer, with Porter stemmer (it works with English only).
@@ -125,18 +130,20 @@ func defineDefaultFullTextAnalyzer() {
 	x.Check(err)
 }
 
-// full text search analyzer - does language-specific stop-words removal and stemming
+// full text search analyzer - does language-specific stop-words removal and stemming, or
+// splits the text in bigrams for CJK languages
 func defineAnalyzer(lang string) {
 	ln := countryCode(lang)
+	filters := []string{lowercase.Name, normalizerName}
+	if isCJKLang(ln) {
+		filters = append(filters, cjkBigramName)
+	} else {
+		filters = append(filters, stopWordsListName(ln), stemmerName(ln))
+	}
 	_, err := bleveCache.DefineAnalyzer(FtsTokenizerName(ln), map[string]interface{}{
-		"type":      custom.Name,
-		"tokenizer": unicode.Name,
-		"token_filters": []string{
-			lowercase.Name,
-			normalizerName,
-			stopWordsListName(ln),
-			stemmerName(ln),
-		},
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": filters,
 	})
 	x.Check(err)
 }
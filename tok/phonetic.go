@@ -0,0 +1,721 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// PhoneticTokenizer is implemented by the tokenizers indexing how the words of
+// a value sound, which are used by sounds_like.
+type PhoneticTokenizer interface {
+	Tokenizer
+
+	// WordTokens returns the tokens of a single word. Words which may be
+	// pronounced in different ways have more than one token.
+	WordTokens(word string) []string
+}
+
+func phoneticTokens(t PhoneticTokenizer, sv types.Val) ([]string, error) {
+	value, ok := sv.Value.(string)
+	if !ok {
+		return nil, x.Errorf("%s tokenizer only supported for string types", t.Name())
+	}
+	words, err := TermWords(value)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []string
+	for _, word := range words {
+		tokens = append(tokens, t.WordTokens(word)...)
+	}
+	return x.RemoveDuplicates(tokens), nil
+}
+
+// SoundexTokenizer indexes the American Soundex codes of the words.
+type SoundexTokenizer struct{}
+
+func (t SoundexTokenizer) Name() string       { return "soundex" }
+func (t SoundexTokenizer) Type() types.TypeID { return types.StringID }
+func (t SoundexTokenizer) Tokens(sv types.Val) ([]string, error) {
+	return phoneticTokens(t, sv)
+}
+func (t SoundexTokenizer) WordTokens(word string) []string {
+	code := Soundex(word)
+	if code == "" {
+		return nil
+	}
+	return []string{encodeToken(code, t.Identifier())}
+}
+func (t SoundexTokenizer) Identifier() byte { return 0xD }
+func (t SoundexTokenizer) IsSortable() bool { return false }
+func (t SoundexTokenizer) IsLossy() bool    { return true }
+
+// MetaphoneTokenizer indexes the Double Metaphone codes of the words. It is
+// used in the schema as phonetic.
+type MetaphoneTokenizer struct{}
+
+func (t MetaphoneTokenizer) Name() string       { return "phonetic" }
+func (t MetaphoneTokenizer) Type() types.TypeID { return types.StringID }
+func (t MetaphoneTokenizer) Tokens(sv types.Val) ([]string, error) {
+	return phoneticTokens(t, sv)
+}
+func (t MetaphoneTokenizer) WordTokens(word string) []string {
+	primary, alternate := DoubleMetaphone(word)
+	if primary == "" {
+		return nil
+	}
+	tokens := []string{encodeToken(primary, t.Identifier())}
+	if alternate != "" && alternate != primary {
+		tokens = append(tokens, encodeToken(alternate, t.Identifier()))
+	}
+	return tokens
+}
+func (t MetaphoneTokenizer) Identifier() byte { return 0xE }
+func (t MetaphoneTokenizer) IsSortable() bool { return false }
+func (t MetaphoneTokenizer) IsLossy() bool    { return true }
+
+var soundexCodes = map[rune]byte{
+	'B': '1', 'F': '1', 'P': '1', 'V': '1',
+	'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+	'D': '3', 'T': '3',
+	'L': '4',
+	'M': '5', 'N': '5',
+	'R': '6',
+}
+
+// foldLetters returns the word in upper case, with the letters with diacritics
+// replaced by their base letters, except the ones in keep.
+func foldLetters(word, keep string) string {
+	var folded bytes.Buffer
+	for _, r := range strings.ToLower(word) {
+		if strings.ContainsRune(keep, r) {
+			folded.WriteRune(r)
+			continue
+		}
+		folded.WriteString(types.BaseLetters(r))
+	}
+	return strings.ToUpper(folded.String())
+}
+
+// Soundex returns the American Soundex code of the word, or an empty string if
+// it has no latin letters. The letters with diacritics are coded as their base
+// letters.
+func Soundex(word string) string {
+	var code bytes.Buffer
+	var last byte
+	for _, r := range foldLetters(word, "") {
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+		c := soundexCodes[r]
+		if code.Len() == 0 {
+			code.WriteRune(r)
+			last = c
+			continue
+		}
+		switch {
+		case r == 'H' || r == 'W':
+			// They don't separate letters with the same code.
+		case c == 0:
+			// Vowels do.
+			last = 0
+		case c != last:
+			code.WriteByte(c)
+			last = c
+		}
+		if code.Len() == 4 {
+			break
+		}
+	}
+	if code.Len() == 0 {
+		return ""
+	}
+	for code.Len() < 4 {
+		code.WriteByte('0')
+	}
+	return code.String()
+}
+
+const metaphoneMaxLen = 4
+
+// metaphone holds the state of the Double Metaphone encoding of a word.
+type metaphone struct {
+	value         []rune
+	primary       bytes.Buffer
+	alternate     bytes.Buffer
+	slavoGermanic bool
+}
+
+// DoubleMetaphone returns the primary and the alternate Double Metaphone codes
+// of the word, as described by Lawrence Philips. The alternate code is the same
+// as the primary one if the word has a single pronunciation. The letters with
+// diacritics other than Ç and Ñ, which have their own rules, are encoded as
+// their base letters.
+func DoubleMetaphone(word string) (string, string) {
+	value := foldLetters(strings.TrimSpace(word), "çñ")
+	if value == "" {
+		return "", ""
+	}
+	m := &metaphone{
+		value: []rune(value),
+		slavoGermanic: strings.ContainsAny(value, "WK") ||
+			strings.Contains(value, "CZ") || strings.Contains(value, "WITZ"),
+	}
+	m.encode()
+	return truncate(m.primary.String()), truncate(m.alternate.String())
+}
+
+func truncate(code string) string {
+	if len(code) > metaphoneMaxLen {
+		return code[:metaphoneMaxLen]
+	}
+	return code
+}
+
+func (m *metaphone) add(primary, alternate string) {
+	m.primary.WriteString(primary)
+	m.alternate.WriteString(alternate)
+}
+
+func (m *metaphone) addBoth(code string) { m.add(code, code) }
+
+func (m *metaphone) complete() bool {
+	return m.primary.Len() >= metaphoneMaxLen && m.alternate.Len() >= metaphoneMaxLen
+}
+
+// at returns the letter at index, or zero if it is out of the word.
+func (m *metaphone) at(index int) rune {
+	if index < 0 || index >= len(m.value) {
+		return 0
+	}
+	return m.value[index]
+}
+
+// has returns whether the letters from start are one of the strings, which
+// must all have the given length.
+func (m *metaphone) has(start, length int, strs ...string) bool {
+	if start < 0 || start+length > len(m.value) {
+		return false
+	}
+	sub := string(m.value[start : start+length])
+	for _, s := range strs {
+		if sub == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metaphone) isVowel(index int) bool {
+	return m.at(index) != 0 && strings.ContainsRune("AEIOUY", m.at(index))
+}
+
+func (m *metaphone) last() int { return len(m.value) - 1 }
+
+// skip returns the index after the letter at index, skipping the next letter
+// too if it is one of the letters.
+func (m *metaphone) skip(index int, letters string) int {
+	if m.at(index+1) != 0 && strings.ContainsRune(letters, m.at(index+1)) {
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) encode() {
+	index := 0
+	if m.has(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		// The first letter is silent.
+		index = 1
+	}
+	if m.at(0) == 'X' {
+		// Initial X is pronounced Z, which maps to S, e.g. Xavier.
+		m.addBoth("S")
+		index = 1
+	}
+
+	for !m.complete() && index <= m.last() {
+		switch m.at(index) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			// Vowels are only kept at the beginning.
+			if index == 0 {
+				m.addBoth("A")
+			}
+			index++
+		case 'B':
+			m.addBoth("P")
+			index = m.skip(index, "B")
+		case 'Ç':
+			m.addBoth("S")
+			index++
+		case 'C':
+			index = m.handleC(index)
+		case 'D':
+			index = m.handleD(index)
+		case 'F':
+			m.addBoth("F")
+			index = m.skip(index, "F")
+		case 'G':
+			index = m.handleG(index)
+		case 'H':
+			index = m.handleH(index)
+		case 'J':
+			index = m.handleJ(index)
+		case 'K':
+			m.addBoth("K")
+			index = m.skip(index, "K")
+		case 'L':
+			index = m.handleL(index)
+		case 'M':
+			m.addBoth("M")
+			if m.at(index+1) == 'M' || (m.has(index-1, 3, "UMB") &&
+				(index+1 == m.last() || m.has(index+2, 2, "ER"))) {
+				// Dumb, thumb.
+				index += 2
+			} else {
+				index++
+			}
+		case 'N':
+			m.addBoth("N")
+			index = m.skip(index, "N")
+		case 'Ñ':
+			m.addBoth("N")
+			index++
+		case 'P':
+			if m.at(index+1) == 'H' {
+				m.addBoth("F")
+				index += 2
+			} else {
+				m.addBoth("P")
+				index = m.skip(index, "PB")
+			}
+		case 'Q':
+			m.addBoth("K")
+			index = m.skip(index, "Q")
+		case 'R':
+			index = m.handleR(index)
+		case 'S':
+			index = m.handleS(index)
+		case 'T':
+			index = m.handleT(index)
+		case 'V':
+			m.addBoth("F")
+			index = m.skip(index, "V")
+		case 'W':
+			index = m.handleW(index)
+		case 'X':
+			index = m.handleX(index)
+		case 'Z':
+			index = m.handleZ(index)
+		default:
+			index++
+		}
+	}
+}
+
+func (m *metaphone) isGermanic() bool {
+	return m.has(0, 4, "VAN ", "VON ") || m.has(0, 3, "SCH")
+}
+
+func (m *metaphone) handleC(index int) int {
+	switch {
+	case m.isGermanicCH(index):
+		// Various germanic, e.g. Bacher, Macher.
+		m.addBoth("K")
+		return index + 2
+	case index == 0 && m.has(index, 6, "CAESAR"):
+		m.addBoth("S")
+		return index + 2
+	case m.has(index, 2, "CH"):
+		return m.handleCH(index)
+	case m.has(index, 2, "CZ") && !m.has(index-2, 4, "WICZ"):
+		// Czerny.
+		m.add("S", "X")
+		return index + 2
+	case m.has(index+1, 3, "CIA"):
+		// Focaccia.
+		m.addBoth("X")
+		return index + 3
+	case m.has(index, 2, "CC") && !(index == 1 && m.at(0) == 'M'):
+		// Double C, but not McClellan.
+		return m.handleCC(index)
+	case m.has(index, 2, "CK", "CG", "CQ"):
+		m.addBoth("K")
+		return index + 2
+	case m.has(index, 2, "CI", "CE", "CY"):
+		// Italian or English.
+		if m.has(index, 3, "CIO", "CIE", "CIA") {
+			m.add("S", "X")
+		} else {
+			m.addBoth("S")
+		}
+		return index + 2
+	}
+	m.addBoth("K")
+	switch {
+	case m.has(index+1, 2, " C", " Q", " G"):
+		// Mac Caffrey, Mac Gregor.
+		return index + 3
+	case m.has(index+1, 1, "C", "K", "Q") && !m.has(index+1, 2, "CE", "CI"):
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) isGermanicCH(index int) bool {
+	switch {
+	case m.has(index, 4, "CHIA"):
+		return true
+	case index <= 1, m.isVowel(index - 2), !m.has(index-1, 3, "ACH"):
+		return false
+	}
+	c := m.at(index + 2)
+	return (c != 'I' && c != 'E') || m.has(index-2, 6, "BACHER", "MACHER")
+}
+
+func (m *metaphone) handleCC(index int) int {
+	if m.has(index+2, 1, "I", "E", "H") && !m.has(index+2, 2, "HU") {
+		// Bellocchio, but not Bacchus.
+		if (index == 1 && m.at(index-1) == 'A') || m.has(index-1, 5, "UCCEE", "UCCES") {
+			// Accident, accede, succeed.
+			m.addBoth("KS")
+		} else {
+			// Bacci, Bertucci and other Italian.
+			m.addBoth("X")
+		}
+		return index + 3
+	}
+	m.addBoth("K")
+	return index + 2
+}
+
+func (m *metaphone) handleCH(index int) int {
+	switch {
+	case index > 0 && m.has(index, 4, "CHAE"):
+		// Michael.
+		m.add("K", "X")
+	case index == 0 && (m.has(index+1, 5, "HARAC", "HARIS") ||
+		m.has(index+1, 3, "HOR", "HYM", "HIA", "HEM")) && !m.has(0, 5, "CHORE"):
+		// Greek roots, e.g. chemistry, chorus.
+		m.addBoth("K")
+	case m.isGermanic() || m.has(index-2, 6, "ORCHES", "ARCHIT", "ORCHID") ||
+		m.has(index+2, 1, "T", "S") ||
+		((m.has(index-1, 1, "A", "O", "U", "E") || index == 0) &&
+			(m.has(index+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") ||
+				index+1 == m.last())):
+		// Germanic, Greek, or otherwise CH for KH sound.
+		m.addBoth("K")
+	case index > 0:
+		if m.has(0, 2, "MC") {
+			m.addBoth("K")
+		} else {
+			m.add("X", "K")
+		}
+	default:
+		m.addBoth("X")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleD(index int) int {
+	switch {
+	case m.has(index, 2, "DG"):
+		if m.has(index+2, 1, "I", "E", "Y") {
+			// Edge.
+			m.addBoth("J")
+			return index + 3
+		}
+		// Edgar.
+		m.addBoth("TK")
+		return index + 2
+	case m.has(index, 2, "DT", "DD"):
+		m.addBoth("T")
+		return index + 2
+	}
+	m.addBoth("T")
+	return index + 1
+}
+
+func (m *metaphone) handleG(index int) int {
+	switch {
+	case m.at(index+1) == 'H':
+		return m.handleGH(index)
+	case m.at(index+1) == 'N':
+		switch {
+		case index == 1 && m.isVowel(0) && !m.slavoGermanic:
+			m.add("KN", "N")
+		case !m.has(index+2, 2, "EY") && m.at(index+1) != 'Y' && !m.slavoGermanic:
+			m.add("N", "KN")
+		default:
+			m.addBoth("KN")
+		}
+		return index + 2
+	case m.has(index+1, 2, "LI") && !m.slavoGermanic:
+		// Tagliaro.
+		m.add("KL", "L")
+		return index + 2
+	case index == 0 && (m.at(index+1) == 'Y' ||
+		m.has(index+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// -ges-, -gep-, -gel-, -gie- at the beginning.
+		m.add("K", "J")
+		return index + 2
+	case (m.has(index+1, 2, "ER") || m.at(index+1) == 'Y') &&
+		!m.has(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!m.has(index-1, 1, "E", "I") && !m.has(index-1, 3, "RGY", "OGY"):
+		// -ger-, -gy-.
+		m.add("K", "J")
+		return index + 2
+	case m.has(index+1, 1, "E", "I", "Y") || m.has(index-1, 4, "AGGI", "OGGI"):
+		// Italian, e.g. Biaggi.
+		switch {
+		case m.isGermanic() || m.has(index+1, 2, "ET"):
+			m.addBoth("K")
+		case m.has(index+1, 3, "IER"):
+			m.addBoth("J")
+		default:
+			m.add("J", "K")
+		}
+		return index + 2
+	case m.at(index+1) == 'G':
+		m.addBoth("K")
+		return index + 2
+	}
+	m.addBoth("K")
+	return index + 1
+}
+
+func (m *metaphone) handleGH(index int) int {
+	switch {
+	case index > 0 && !m.isVowel(index-1):
+		m.addBoth("K")
+	case index == 0:
+		// Ghislane, Ghiradelli.
+		if m.at(index+2) == 'I' {
+			m.addBoth("J")
+		} else {
+			m.addBoth("K")
+		}
+	case m.has(index-2, 1, "B", "H", "D") || m.has(index-3, 1, "B", "H", "D") ||
+		m.has(index-4, 1, "B", "H"):
+		// Parker's rule, e.g. Hugh, bough, broughton.
+	case index > 2 && m.at(index-1) == 'U' && m.has(index-3, 1, "C", "G", "L", "R", "T"):
+		// Laugh, McLaughlin, cough, rough, tough.
+		m.addBoth("F")
+	case index > 0 && m.at(index-1) != 'I':
+		m.addBoth("K")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleH(index int) int {
+	// H is only kept at the beginning or between vowels, and before a vowel.
+	if (index == 0 || m.isVowel(index-1)) && m.isVowel(index+1) {
+		m.addBoth("H")
+		return index + 2
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleJ(index int) int {
+	if m.has(index, 4, "JOSE") || m.has(0, 4, "SAN ") {
+		// Spanish, e.g. Jose, San Jacinto.
+		if (index == 0 && m.at(index+4) == ' ') || len(m.value) == 4 || m.has(0, 4, "SAN ") {
+			m.addBoth("H")
+		} else {
+			m.add("J", "H")
+		}
+		return index + 1
+	}
+	switch {
+	case index == 0:
+		// Jankelowicz may be pronounced Yankelowicz.
+		m.add("J", "A")
+	case m.isVowel(index-1) && !m.slavoGermanic && (m.at(index+1) == 'A' || m.at(index+1) == 'O'):
+		// Spanish pronunciation of, e.g. bajador.
+		m.add("J", "H")
+	case index == m.last():
+		m.add("J", "")
+	case !m.has(index+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") &&
+		!m.has(index-1, 1, "S", "K", "L"):
+		m.addBoth("J")
+	}
+	return m.skip(index, "J")
+}
+
+func (m *metaphone) handleL(index int) int {
+	if m.at(index+1) != 'L' {
+		m.addBoth("L")
+		return index + 1
+	}
+	// Spanish, e.g. Cabrillo, Gallegos.
+	if (index == len(m.value)-3 && m.has(index-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((m.has(len(m.value)-2, 2, "AS", "OS") || m.has(m.last(), 1, "A", "O")) &&
+			m.has(index-1, 4, "ALLE")) {
+		m.add("L", "")
+	} else {
+		m.addBoth("L")
+	}
+	return index + 2
+}
+
+func (m *metaphone) handleR(index int) int {
+	// French, e.g. Rogier, but not Hochmeier.
+	if index == m.last() && !m.slavoGermanic && m.has(index-2, 2, "IE") &&
+		!m.has(index-4, 2, "ME", "MA") {
+		m.add("", "R")
+	} else {
+		m.addBoth("R")
+	}
+	return m.skip(index, "R")
+}
+
+func (m *metaphone) handleS(index int) int {
+	switch {
+	case m.has(index-1, 3, "ISL", "YSL"):
+		// Island, isle, Carlisle, Carlysle.
+		return index + 1
+	case index == 0 && m.has(index, 5, "SUGAR"):
+		m.add("X", "S")
+		return index + 1
+	case m.has(index, 2, "SH"):
+		// Germanic, e.g. Rheinsheim.
+		if m.has(index+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			m.addBoth("S")
+		} else {
+			m.addBoth("X")
+		}
+		return index + 2
+	case m.has(index, 3, "SIO", "SIA") || m.has(index, 4, "SIAN"):
+		// Italian and Armenian.
+		if m.slavoGermanic {
+			m.addBoth("S")
+		} else {
+			m.add("S", "X")
+		}
+		return index + 3
+	case (index == 0 && m.has(index+1, 1, "M", "N", "L", "W")) || m.has(index+1, 1, "Z"):
+		// German and anglicisations, e.g. Smith matches Schmidt, snider
+		// matches Schneider.
+		m.add("S", "X")
+		return m.skip(index, "Z")
+	case m.has(index, 2, "SC"):
+		return m.handleSC(index)
+	case index == m.last() && m.has(index-2, 2, "AI", "OI"):
+		// French, e.g. Resnais, Artois.
+		m.add("", "S")
+	default:
+		m.addBoth("S")
+	}
+	return m.skip(index, "SZ")
+}
+
+func (m *metaphone) handleSC(index int) int {
+	switch {
+	case m.at(index+2) == 'H':
+		// Schlesinger's rule.
+		switch {
+		case m.has(index+3, 2, "ER", "EN"):
+			// Schermerhorn, Schenker.
+			m.add("X", "SK")
+		case m.has(index+3, 2, "OO", "UY", "ED", "EM"):
+			// Dutch origin, e.g. school, schooner.
+			m.addBoth("SK")
+		case index == 0 && !m.isVowel(3) && m.at(3) != 'W':
+			m.add("X", "S")
+		default:
+			m.addBoth("X")
+		}
+	case m.has(index+2, 1, "I", "E", "Y"):
+		m.addBoth("S")
+	default:
+		m.addBoth("SK")
+	}
+	return index + 3
+}
+
+func (m *metaphone) handleT(index int) int {
+	switch {
+	case m.has(index, 4, "TION"), m.has(index, 3, "TIA", "TCH"):
+		m.addBoth("X")
+		return index + 3
+	case m.has(index, 2, "TH") || m.has(index, 3, "TTH"):
+		// Thomas, Thames or germanic.
+		if m.has(index+2, 2, "OM", "AM") || m.isGermanic() {
+			m.addBoth("T")
+		} else {
+			m.add("0", "T")
+		}
+		return index + 2
+	}
+	m.addBoth("T")
+	return m.skip(index, "TD")
+}
+
+func (m *metaphone) handleW(index int) int {
+	switch {
+	case m.has(index, 2, "WR"):
+		m.addBoth("R")
+		return index + 2
+	case index == 0 && m.isVowel(index+1):
+		// Wasserman should match Vasserman.
+		m.add("A", "F")
+	case index == 0 && m.has(index, 2, "WH"):
+		// Uomo should match Womo.
+		m.addBoth("A")
+	case (index == m.last() && m.isVowel(index-1)) ||
+		m.has(index-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || m.has(0, 3, "SCH"):
+		// Arnow should match Arnoff.
+		m.add("", "F")
+	case m.has(index, 4, "WICZ", "WITZ"):
+		// Polish, e.g. Filipowicz.
+		m.add("TS", "FX")
+		return index + 4
+	}
+	return index + 1
+}
+
+func (m *metaphone) handleX(index int) int {
+	if index == 0 {
+		m.addBoth("S")
+		return index + 1
+	}
+	// French, e.g. breaux.
+	if !(index == m.last() && (m.has(index-3, 3, "IAU", "EAU") || m.has(index-2, 2, "AU", "OU"))) {
+		m.addBoth("KS")
+	}
+	return m.skip(index, "CX")
+}
+
+func (m *metaphone) handleZ(index int) int {
+	if m.at(index+1) == 'H' {
+		// Chinese pinyin, e.g. Zhao.
+		m.addBoth("J")
+		return index + 2
+	}
+	if m.has(index+1, 2, "ZO", "ZI", "ZA") ||
+		(m.slavoGermanic && index > 0 && m.at(index-1) != 'T') {
+		m.add("S", "TS")
+	} else {
+		m.addBoth("S")
+	}
+	return m.skip(index, "Z")
+}
@@ -18,9 +18,14 @@
 package tok
 
 import (
+	"bytes"
 	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/blevesearch/bleve/analysis"
 	farm "github.com/dgryski/go-farm"
 	geom "github.com/twpayne/go-geom"
 
@@ -70,6 +75,16 @@ func init() {
 	RegisterTokenizer(BoolTokenizer{})
 	RegisterTokenizer(TrigramTokenizer{})
 	RegisterTokenizer(HashTokenizer{})
+	RegisterTokenizer(EdgeNgramTokenizer{
+		MinLen: defaultEdgeNgramMin,
+		MaxLen: defaultEdgeNgramMax,
+	})
+	RegisterTokenizer(SoundexTokenizer{})
+	RegisterTokenizer(MetaphoneTokenizer{})
+	RegisterTokenizer(FoldedExactTokenizer{})
+	RegisterTokenizer(FoldedHashTokenizer{})
+	RegisterTokenizer(FoldedTrigramTokenizer{})
+	RegisterTokenizer(VectorTokenizer{})
 
 	// Check for duplicate prefix bytes.
 	usedIds := make(map[byte]struct{})
@@ -86,8 +101,17 @@ func init() {
 
 // GetTokenizer returns tokenizer given unique name.
 func GetTokenizer(name string) (Tokenizer, bool) {
-	t, found := tokenizers[name]
-	return t, found
+	if t, found := tokenizers[name]; found {
+		return t, true
+	}
+	// Tokenizers taking arguments aren't registered.
+	if t, ok := parseFullText(name); ok {
+		return t, true
+	}
+	if t, ok := parseVector(name); ok {
+		return t, true
+	}
+	return parseEdgeNgram(name)
 }
 
 // RegisterTokenizer adds your tokenizer to our list.
@@ -230,12 +254,23 @@ func (t ExactTokenizer) IsLossy() bool    { return false }
 // Full text tokenizer, with language support
 type FullTextTokenizer struct {
 	Lang string
+	// Config changes the analysis of the values, if not nil.
+	Config *AnalyzerConfig
 }
 
-func (t FullTextTokenizer) Name() string       { return FtsTokenizerName(t.Lang) }
+func (t FullTextTokenizer) Name() string {
+	if t.Config == nil {
+		return FtsTokenizerName(t.Lang)
+	}
+	return FtsTokenizerName(t.Lang) + t.Config.args()
+}
 func (t FullTextTokenizer) Type() types.TypeID { return types.StringID }
 func (t FullTextTokenizer) Tokens(sv types.Val) ([]string, error) {
-	return getBleveTokens(t.Name(), t.Identifier(), sv)
+	analyzer, err := t.analyzer()
+	if err != nil {
+		return nil, err
+	}
+	return analyzeTokens(analyzer, t.Identifier(), sv), nil
 }
 func (t FullTextTokenizer) Identifier() byte { return 0x8 }
 func (t FullTextTokenizer) IsSortable() bool { return false }
@@ -246,14 +281,78 @@ func getBleveTokens(name string, identifier byte, sv types.Val) ([]string, error
 	if err != nil {
 		return nil, err
 	}
+	return analyzeTokens(analyzer, identifier, sv), nil
+}
+
+func analyzeTokens(analyzer *analysis.Analyzer, identifier byte, sv types.Val) []string {
 	tokenStream := analyzer.Analyze([]byte(sv.Value.(string)))
 
 	terms := make([]string, len(tokenStream))
 	for i, token := range tokenStream {
 		terms[i] = encodeToken(string(token.Term), identifier)
 	}
-	terms = x.RemoveDuplicates(terms)
-	return terms, nil
+	return x.RemoveDuplicates(terms)
+}
+
+const (
+	// DocLengthFacet is the facet holding the number of terms of a value on the
+	// postings of the full text index.
+	DocLengthFacet = "dl"
+	// PositionsFacet is the facet holding the positions at which a term occurs
+	// in a value on the postings of the full text index.
+	PositionsFacet = "pos"
+	// TermFreqFacet is the facet holding the number of times a term occurs in a
+	// value on the postings of the full text index.
+	TermFreqFacet = "tf"
+)
+
+// TermPositions returns the positions at which each token of a full text
+// tokenizer occurs in the value, along with the total number of tokens.
+// Positions start at 1 and count the stop words removed by the analyzer, so
+// that adjacent words in the value always differ by one.
+func TermPositions(t Tokenizer, sv types.Val) (map[string][]int, int, error) {
+	ft, ok := t.(FullTextTokenizer)
+	if !ok {
+		return nil, 0, x.Errorf("Term positions need a full text tokenizer. Got: %s", t.Name())
+	}
+	analyzer, err := ft.analyzer()
+	if err != nil {
+		return nil, 0, err
+	}
+	tokenStream := analyzer.Analyze([]byte(sv.Value.(string)))
+
+	positions := make(map[string][]int)
+	for _, token := range tokenStream {
+		term := encodeToken(string(token.Term), ft.Identifier())
+		positions[term] = append(positions[term], token.Position)
+	}
+	return positions, len(tokenStream), nil
+}
+
+// EncodePositions returns the value of the positions facet.
+func EncodePositions(positions []int) []byte {
+	var buf bytes.Buffer
+	for i, pos := range positions {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(strconv.Itoa(pos))
+	}
+	return buf.Bytes()
+}
+
+// DecodePositions parses the value of the positions facet.
+func DecodePositions(data []byte) ([]int, error) {
+	fields := strings.Fields(string(data))
+	positions := make([]int, len(fields))
+	for i, f := range fields {
+		pos, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, x.Wrapf(err, "Invalid term positions: %q", data)
+		}
+		positions[i] = pos
+	}
+	return positions, nil
 }
 
 func encodeInt(val int64) string {
@@ -271,6 +370,49 @@ func encodeToken(tok string, typ byte) string {
 	return string(typ) + tok
 }
 
+// Decodable returns true if the values can be got back from the tokens of t,
+// as t keeps the value, or its year for year, in the token.
+func Decodable(t Tokenizer) bool {
+	switch t.(type) {
+	case ExactTokenizer, IntTokenizer, YearTokenizer, BoolTokenizer:
+		return true
+	}
+	return false
+}
+
+// DecodeToken returns the value an index token of tokenizer t was built from.
+func DecodeToken(t Tokenizer, token string) (types.Val, error) {
+	if !Decodable(t) {
+		return types.Val{}, x.Errorf("Tokens of %s index can't be decoded", t.Name())
+	}
+	if len(token) == 0 || token[0] != t.Identifier() {
+		return types.Val{}, x.Errorf("Token %q wasn't built by tokenizer %s", token, t.Name())
+	}
+	token = token[1:]
+	switch t.(type) {
+	case IntTokenizer:
+		v, err := decodeInt(token)
+		return types.Val{Tid: types.IntID, Value: v}, err
+	case YearTokenizer:
+		if len(token) != 2 {
+			return types.Val{}, x.Errorf("Invalid year token: %q", token)
+		}
+		v := int64(binary.BigEndian.Uint16([]byte(token)))
+		return types.Val{Tid: types.IntID, Value: v}, nil
+	case BoolTokenizer:
+		v, err := decodeInt(token)
+		return types.Val{Tid: types.BoolID, Value: v != 0}, err
+	}
+	return types.Val{Tid: types.StringID, Value: token}, nil
+}
+
+func decodeInt(token string) (int64, error) {
+	if len(token) != 9 {
+		return 0, x.Errorf("Invalid int token: %q", token)
+	}
+	return int64(binary.BigEndian.Uint64([]byte(token[1:]))), nil
+}
+
 func EncodeGeoTokens(tokens []string) {
 	for i := 0; i < len(tokens); i++ {
 		tokens[i] = encodeToken(tokens[i], GeoTokenizer{}.Identifier())
@@ -339,3 +481,90 @@ func (t HashTokenizer) Tokens(sv types.Val) ([]string, error) {
 func (t HashTokenizer) Identifier() byte { return 0xB }
 func (t HashTokenizer) IsSortable() bool { return false }
 func (t HashTokenizer) IsLossy() bool    { return true }
+
+const (
+	edgeNgramName       = "edgengram"
+	defaultEdgeNgramMin = 1
+	defaultEdgeNgramMax = 10
+)
+
+// EdgeNgramTokenizer indexes the prefixes of the words of a value, from MinLen
+// to MaxLen characters. It is used in the schema as edgengram, with the default
+// lengths, or as edgengram(min, max).
+type EdgeNgramTokenizer struct {
+	MinLen int
+	MaxLen int
+}
+
+func (t EdgeNgramTokenizer) Name() string {
+	if t.MinLen == defaultEdgeNgramMin && t.MaxLen == defaultEdgeNgramMax {
+		return edgeNgramName
+	}
+	return fmt.Sprintf("%s(%d,%d)", edgeNgramName, t.MinLen, t.MaxLen)
+}
+func (t EdgeNgramTokenizer) Type() types.TypeID { return types.StringID }
+func (t EdgeNgramTokenizer) Tokens(sv types.Val) ([]string, error) {
+	value, ok := sv.Value.(string)
+	if !ok {
+		return nil, x.Errorf("Edge n-gram tokenizer only supported for string types")
+	}
+	words, err := TermWords(value)
+	if err != nil {
+		return nil, err
+	}
+	var tokens []string
+	for _, word := range words {
+		runes := []rune(word)
+		for l := t.MinLen; l <= t.MaxLen && l <= len(runes); l++ {
+			tokens = append(tokens, encodeToken(string(runes[:l]), t.Identifier()))
+		}
+	}
+	return x.RemoveDuplicates(tokens), nil
+}
+func (t EdgeNgramTokenizer) Identifier() byte { return 0xC }
+func (t EdgeNgramTokenizer) IsSortable() bool { return false }
+func (t EdgeNgramTokenizer) IsLossy() bool    { return true }
+
+// PrefixTokens returns the tokens to look up the values having words which
+// start with the words of the text. Words longer than MaxLen are cut, so the
+// values found have to be checked.
+func (t EdgeNgramTokenizer) PrefixTokens(text string) ([]string, error) {
+	words, err := TermWords(text)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		runes := []rune(word)
+		if len(runes) < t.MinLen {
+			return nil, x.Errorf("Prefix %q is shorter than the minimum length %d of the index",
+				word, t.MinLen)
+		}
+		if len(runes) > t.MaxLen {
+			runes = runes[:t.MaxLen]
+		}
+		tokens = append(tokens, encodeToken(string(runes), t.Identifier()))
+	}
+	return x.RemoveDuplicates(tokens), nil
+}
+
+// parseEdgeNgram returns the edge n-gram tokenizer for names like
+// edgengram(2,15).
+func parseEdgeNgram(name string) (Tokenizer, bool) {
+	if !strings.HasPrefix(name, edgeNgramName+"(") || !strings.HasSuffix(name, ")") {
+		return nil, false
+	}
+	args := strings.Split(name[len(edgeNgramName)+1:len(name)-1], ",")
+	if len(args) != 2 {
+		return nil, false
+	}
+	min, err := strconv.Atoi(strings.TrimSpace(args[0]))
+	if err != nil {
+		return nil, false
+	}
+	max, err := strconv.Atoi(strings.TrimSpace(args[1]))
+	if err != nil || min < 1 || max < min {
+		return nil, false
+	}
+	return EdgeNgramTokenizer{MinLen: min, MaxLen: max}, true
+}
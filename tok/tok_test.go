@@ -18,7 +18,10 @@
 package tok
 
 import (
+	"io/ioutil"
 	"math"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -220,3 +223,399 @@ func TestGetTextTokensInvalidLang(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, tokens)
 }
+
+func TestDecodeToken(t *testing.T) {
+	for _, tc := range []struct {
+		tokenizer string
+		val       types.Val
+	}{
+		{"exact", types.Val{Tid: types.StringID, Value: "Stemming works!"}},
+		{"int", types.Val{Tid: types.IntID, Value: int64(-1543)}},
+		{"int", types.Val{Tid: types.IntID, Value: int64(10000)}},
+		{"bool", types.Val{Tid: types.BoolID, Value: true}},
+	} {
+		tokenizer, has := GetTokenizer(tc.tokenizer)
+		require.True(t, has)
+		tokens, err := tokenizer.Tokens(tc.val)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(tokens))
+		val, err := DecodeToken(tokenizer, tokens[0])
+		require.NoError(t, err)
+		require.Equal(t, tc.val, val)
+	}
+
+	// Year tokens keep the year of the date.
+	year, _ := GetTokenizer("year")
+	tokens, err := year.Tokens(types.Val{Tid: types.DateTimeID,
+		Value: time.Date(2017, 11, 3, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	val, err := DecodeToken(year, tokens[0])
+	require.NoError(t, err)
+	require.Equal(t, types.Val{Tid: types.IntID, Value: int64(2017)}, val)
+
+	for _, name := range []string{"hash", "term", "fulltext", "trigram"} {
+		tokenizer, _ := GetTokenizer(name)
+		tokens, err := tokenizer.Tokens(types.Val{Tid: types.StringID, Value: "abcd"})
+		require.NoError(t, err)
+		_, err = DecodeToken(tokenizer, tokens[0])
+		require.Error(t, err, name)
+	}
+}
+
+func TestTermPositions(t *testing.T) {
+	tokenizer, has := GetTokenizer("fulltext")
+	require.True(t, has)
+	val := types.Val{Tid: types.StringID, Value: "Running shoes for running and the walking"}
+
+	positions, length, err := TermPositions(tokenizer, val)
+	require.NoError(t, err)
+	// "for", "and" and "the" are stop words, but still count for the positions.
+	require.Equal(t, 4, length)
+	id := tokenizer.Identifier()
+	require.Equal(t, map[string][]int{
+		encodeToken("run", id):  {1, 4},
+		encodeToken("shoe", id): {2},
+		encodeToken("walk", id): {7},
+	}, positions)
+
+	term, _ := GetTokenizer("term")
+	_, _, err = TermPositions(term, val)
+	require.Error(t, err)
+}
+
+func TestEncodePositions(t *testing.T) {
+	data := EncodePositions([]int{1, 4, 12})
+	require.Equal(t, "1 4 12", string(data))
+	positions, err := DecodePositions(data)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 4, 12}, positions)
+
+	_, err = DecodePositions([]byte("1 x"))
+	require.Error(t, err)
+}
+
+func TestEdgeNgramTokenizer(t *testing.T) {
+	tokenizer, has := GetTokenizer("edgengram(2,4)")
+	require.True(t, has)
+	require.Equal(t, "edgengram(2,4)", tokenizer.Name())
+	tokens, err := tokenizer.Tokens(types.Val{Tid: types.StringID, Value: "New York, NY"})
+	require.NoError(t, err)
+	id := tokenizer.Identifier()
+	require.Equal(t, []string{
+		encodeToken("ne", id), encodeToken("new", id), encodeToken("ny", id),
+		encodeToken("yo", id), encodeToken("yor", id), encodeToken("york", id),
+	}, tokens)
+
+	tokens, err = tokenizer.(EdgeNgramTokenizer).PrefixTokens("yorksh NE")
+	require.NoError(t, err)
+	require.Equal(t, []string{encodeToken("ne", id), encodeToken("york", id)}, tokens)
+	_, err = tokenizer.(EdgeNgramTokenizer).PrefixTokens("n")
+	require.Error(t, err)
+
+	def, has := GetTokenizer("edgengram")
+	require.True(t, has)
+	require.Equal(t, EdgeNgramTokenizer{MinLen: 1, MaxLen: 10}, def)
+	_, has = GetTokenizer("edgengram(3,1)")
+	require.False(t, has)
+}
+
+func TestSoundex(t *testing.T) {
+	for word, code := range map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Ashcraft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"Lee":      "L000",
+		"john":     "J500",
+		"Jon":      "J500",
+		"123":      "",
+		"Müller":   "M460",
+		"Çelik":    "C420",
+	} {
+		require.Equal(t, code, Soundex(word), word)
+	}
+}
+
+func TestDoubleMetaphone(t *testing.T) {
+	for word, codes := range map[string][2]string{
+		"John":      {"JN", "AN"},
+		"Jon":       {"JN", "AN"},
+		"Joan":      {"JN", "AN"},
+		"Smith":     {"SM0", "XMT"},
+		"Schmidt":   {"XMT", "SMT"},
+		"Thomas":    {"TMS", "TMS"},
+		"Knight":    {"NT", "NT"},
+		"Caesar":    {"SSR", "SSR"},
+		"Michael":   {"MKL", "MXL"},
+		"Xavier":    {"SF", "SFR"},
+		"Wasserman": {"ASRM", "FSRM"},
+	} {
+		primary, alternate := DoubleMetaphone(word)
+		require.Equal(t, codes, [2]string{primary, alternate}, word)
+	}
+
+	// The letters with diacritics sound like their base letters.
+	for word, base := range map[string]string{
+		"Müller": "Muller",
+		"José":   "Jose",
+		"Łukasz": "Lukasz",
+		"Strauß": "Strauss",
+		"Dvořák": "Dvorak",
+		"Bjørn":  "Bjorn",
+		"Renée":  "Renee",
+		"Zoë":    "Zoe",
+	} {
+		primary, alternate := DoubleMetaphone(word)
+		basePrimary, baseAlternate := DoubleMetaphone(base)
+		require.Equal(t, [2]string{basePrimary, baseAlternate}, [2]string{primary, alternate}, word)
+	}
+}
+
+func TestPhoneticTokenizer(t *testing.T) {
+	tokenizer, has := GetTokenizer("phonetic")
+	require.True(t, has)
+	tokens, err := tokenizer.Tokens(types.Val{Tid: types.StringID, Value: "John Smith"})
+	require.NoError(t, err)
+	id := tokenizer.Identifier()
+	require.Equal(t, []string{encodeToken("AN", id), encodeToken("JN", id),
+		encodeToken("SM0", id), encodeToken("XMT", id)}, tokens)
+
+	tokenizer, has = GetTokenizer("soundex")
+	require.True(t, has)
+	require.Equal(t, []string{encodeToken("J500", tokenizer.Identifier())},
+		tokenizer.(PhoneticTokenizer).WordTokens("Joan"))
+}
+
+func TestFullTextTokenizerConfig(t *testing.T) {
+	tokenizer, has := GetTokenizer("fulltextfr(synonyms:<a,b.txt>, stemming:false)")
+	require.True(t, has)
+	require.Equal(t, "fulltextfr(stemming:false,synonyms:<a,b.txt>)", tokenizer.Name())
+	require.Equal(t, FullTextTokenizer{Lang: "fr",
+		Config: &AnalyzerConfig{Synonyms: "a,b.txt", NoStem: true}}, tokenizer)
+
+	_, has = GetTokenizer("fulltext(stemming:maybe)")
+	require.False(t, has)
+	_, has = GetTokenizer("fulltextxx(stemming:false)")
+	require.False(t, has)
+}
+
+func TestCJKBigrams(t *testing.T) {
+	tokens, err := GetTextTokens([]string{"北京大学图书馆"}, "zh")
+	require.NoError(t, err)
+	id := FullTextTokenizer{}.Identifier()
+	require.Equal(t, []string{encodeToken("书馆", id), encodeToken("京大", id),
+		encodeToken("北京", id), encodeToken("图书", id), encodeToken("大学", id),
+		encodeToken("学图", id)}, tokens)
+
+	tokens, err = GetTextTokens([]string{"서울 대학교"}, "ko")
+	require.NoError(t, err)
+	require.Equal(t, []string{encodeToken("대학", id), encodeToken("서울", id),
+		encodeToken("학교", id)}, tokens)
+
+	tokenizer, has := GetTokenizer(FtsTokenizerName("ja"))
+	require.True(t, has)
+	positions, length, err := TermPositions(tokenizer,
+		types.Val{Tid: types.StringID, Value: "東京タワー and 京"})
+	require.NoError(t, err)
+	require.Equal(t, 6, length)
+	require.Equal(t, map[string][]int{
+		encodeToken("東京", id): {1}, encodeToken("京タ", id): {2},
+		encodeToken("タワ", id): {3}, encodeToken("ワー", id): {4},
+		encodeToken("and", id): {5}, encodeToken("京", id): {6},
+	}, positions)
+}
+
+func TestFoldedTokenizers(t *testing.T) {
+	require.Equal(t, "straße ǆ k", FoldCase("STRAßE ǅ K"))
+
+	tokens := func(name, value string) []string {
+		tokenizer, has := GetTokenizer(name)
+		require.True(t, has)
+		require.True(t, FoldsCase(tokenizer))
+		tokens, err := tokenizer.Tokens(types.Val{Tid: types.StringID, Value: value})
+		require.NoError(t, err)
+		return tokens
+	}
+	for _, name := range []string{"iexact", "ihash", "itrigram"} {
+		require.Equal(t, tokens(name, "alice@example.com"), tokens(name, "Alice@Example.COM"))
+		require.NotEqual(t, tokens(name, "alice@example.com"), tokens(name, "bob@example.com"))
+	}
+	require.Equal(t, []string{encodeToken("alice", 0xF)}, tokens("iexact", "ALICE"))
+	require.Equal(t, []string{encodeToken("bob", 0x11)}, tokens("itrigram", "Bob"))
+	require.False(t, FoldsCase(ExactTokenizer{}))
+}
+
+func TestVectorTokenizer(t *testing.T) {
+	tokenizer, has := GetTokenizer("vector(metric:<cosine>)")
+	require.True(t, has)
+	require.Equal(t, "vector(metric:<cosine>)", tokenizer.Name())
+	vt := tokenizer.(VectorTokenizer)
+
+	tokens := func(v []float32) []string {
+		tokens, err := vt.Tokens(types.Val{Tid: types.Float32VectorID, Value: v})
+		require.NoError(t, err)
+		require.Len(t, tokens, vectorTables)
+		return tokens
+	}
+	// Vectors in the same direction are on the same side of every hyperplane.
+	require.Equal(t, tokens([]float32{1, 2, 3}), tokens([]float32{2, 4, 6}))
+	require.NotEqual(t, tokens([]float32{1, 2, 3}), tokens([]float32{-1, -2, -3}))
+	probes := vt.ProbeTokens([]float32{1, 2, 3})
+	for _, token := range tokens([]float32{1, 2, 3}) {
+		require.Contains(t, probes, token)
+	}
+	require.Len(t, probes, vectorTables*(vectorBits+1))
+	require.InDelta(t, 0, vt.Distance([]float32{1, 2, 3}, []float32{2, 4, 6}), 1e-9)
+
+	_, has = GetTokenizer("vector(metric:<manhattan>)")
+	require.False(t, has)
+	_, has = GetTokenizer("vector(metric:<cosine>,width:<2>)")
+	require.False(t, has)
+}
+
+func TestVectorTokenizerEuclidean(t *testing.T) {
+	tokenizer, has := GetTokenizer("vector(metric:<euclidean>,width:<2>)")
+	require.True(t, has)
+	require.Equal(t, "vector(width:<2>)", tokenizer.Name())
+	vt := tokenizer.(VectorTokenizer)
+
+	tokens := func(v []float32) []string {
+		tokens, err := vt.Tokens(types.Val{Tid: types.Float32VectorID, Value: v})
+		require.NoError(t, err)
+		require.Len(t, tokens, vectorTables)
+		return tokens
+	}
+	// Vectors much closer than the width mostly fall in the same slots, but
+	// unlike for cosine, the ones in the same direction needn't.
+	require.Equal(t, tokens([]float32{1, 2, 3}), tokens([]float32{1, 2, 3.001}))
+	require.NotEqual(t, tokens([]float32{1, 2, 3}), tokens([]float32{2, 4, 6}))
+	probes := vt.ProbeTokens([]float32{1, 2, 3})
+	for _, token := range tokens([]float32{1, 2, 3}) {
+		require.Contains(t, probes, token)
+	}
+	require.Len(t, probes, vectorTables*(vectorProjections+1))
+	require.InDelta(t, 3.7416, vt.Distance([]float32{1, 2, 3}, []float32{2, 4, 6}), 1e-4)
+
+	_, has = GetTokenizer("vector(width:<0>)")
+	require.False(t, has)
+}
+
+func TestCompositeIndex(t *testing.T) {
+	ci, err := ParseComposite("country", "composite(status,city)")
+	require.NoError(t, err)
+	require.Equal(t, []string{"country", "status", "city"}, ci.Preds)
+	require.Equal(t, "composite(status,city)", ci.Name())
+
+	str := func(s string) types.Val { return types.Val{Tid: types.StringID, Value: s} }
+	tokens, err := ci.Tokens([][]types.Val{{str("US")}, {str("active"), str("new")},
+		{str("NYC")}})
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	for _, token := range tokens {
+		require.Equal(t, ci.Prefix(), token[:1])
+	}
+	// The values are delimited, so moving a character between them changes the token.
+	other, err := ci.Tokens([][]types.Val{{str("USa")}, {str("ctive")}, {str("NYC")}})
+	require.NoError(t, err)
+	require.NotEqual(t, tokens[0], other[0])
+
+	tokens, err = ci.Tokens([][]types.Val{{str("US")}, nil, {str("NYC")}})
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	_, err = ParseComposite("country", "composite(country)")
+	require.Error(t, err)
+}
+
+func TestFacetIndex(t *testing.T) {
+	fi, err := ParseFacetIndex("facet(score)")
+	require.NoError(t, err)
+	require.Equal(t, "score", fi.Key)
+	require.Equal(t, "facet(score)", fi.Name())
+
+	// The tokens sort like the values.
+	vals := []types.Val{
+		{Tid: types.IntID, Value: int64(-10)},
+		{Tid: types.IntID, Value: int64(-1)},
+		{Tid: types.IntID, Value: int64(0)},
+		{Tid: types.IntID, Value: int64(4)},
+		{Tid: types.FloatID, Value: float64(-2.5)},
+		{Tid: types.FloatID, Value: float64(-0.5)},
+		{Tid: types.FloatID, Value: float64(1.5)},
+		{Tid: types.FloatID, Value: float64(100)},
+		{Tid: types.DateTimeID, Value: time.Date(1969, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Tid: types.DateTimeID, Value: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Tid: types.DateTimeID, Value: time.Date(2017, 1, 1, 0, 0, 0, 5, time.UTC)},
+	}
+	var tokens []string
+	for _, v := range vals {
+		token, err := fi.Token(v)
+		require.NoError(t, err)
+		require.Equal(t, fi.Prefix(v.Tid), token[:len(fi.Prefix(v.Tid))])
+		tokens = append(tokens, token)
+	}
+	for i := 1; i < len(tokens); i++ {
+		if vals[i].Tid == vals[i-1].Tid {
+			require.True(t, tokens[i-1] < tokens[i], "%v < %v", vals[i-1].Value, vals[i].Value)
+		}
+	}
+
+	other, err := FacetIndex{Key: "scores"}.Token(types.Val{Tid: types.IntID, Value: int64(0)})
+	require.NoError(t, err)
+	require.NotEqual(t, tokens[2][:len(fi.Prefix(types.IntID))], other[:len(fi.Prefix(types.IntID))])
+
+	_, err = ParseFacetIndex("facet(score, since)")
+	require.Error(t, err)
+}
+
+func TestFullTextTokenizerLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analyzer_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	Config.AnalyzerDir = dir
+	defer func() { Config.AnalyzerDir = "" }()
+	file := filepath.Join(dir, "stopwords.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("quick\n"), 0644))
+
+	tokenizer := FullTextTokenizer{Config: &AnalyzerConfig{Stopwords: "stopwords.txt"}}
+	loaded, err := tokenizer.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded.Config.Digest)
+	tokens, err := loaded.Tokens(types.Val{Tid: types.StringID, Value: "quick fox"})
+	require.NoError(t, err)
+	require.Equal(t, []string{encodeToken("fox", loaded.Identifier())}, tokens)
+
+	// The tokenizer of the schema keeps the analyzer it was loaded with, but
+	// can't be loaded again once the file has changed.
+	require.NoError(t, ioutil.WriteFile(file, []byte("fox\n"), 0644))
+	tokens, err = loaded.Tokens(types.Val{Tid: types.StringID, Value: "quick fox"})
+	require.NoError(t, err)
+	require.Equal(t, []string{encodeToken("fox", loaded.Identifier())}, tokens)
+	_, err = loaded.Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "have changed since the schema was set")
+
+	reloaded, err := tokenizer.Load()
+	require.NoError(t, err)
+	require.NotEqual(t, loaded.Name(), reloaded.Name())
+	tokens, err = reloaded.Tokens(types.Val{Tid: types.StringID, Value: "quick fox"})
+	require.NoError(t, err)
+	require.Equal(t, []string{encodeToken("quick", loaded.Identifier())}, tokens)
+}
+
+func TestFullTextTokenizerLoadSymlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "analyzer_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	Config.AnalyzerDir = filepath.Join(dir, "analyzers")
+	defer func() { Config.AnalyzerDir = "" }()
+	require.NoError(t, os.Mkdir(Config.AnalyzerDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.txt"),
+		filepath.Join(Config.AnalyzerDir, "stopwords.txt")))
+
+	_, err = FullTextTokenizer{Config: &AnalyzerConfig{Stopwords: "stopwords.txt"}}.Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is outside of the analyzer directory")
+}
@@ -38,6 +38,21 @@ func GetTextTokens(funcArgs []string, lang string) ([]string, error) {
 	return nil, x.Errorf("Tokenizer not found for %s", "fulltext"+lang)
 }
 
+// TermWords splits the text into lower case words, like the term tokenizer
+// does.
+func TermWords(text string) ([]string, error) {
+	analyzer, err := bleveCache.AnalyzerNamed(termTokenizer.Name())
+	if err != nil {
+		return nil, err
+	}
+	tokenStream := analyzer.Analyze([]byte(text))
+	words := make([]string, len(tokenStream))
+	for i, token := range tokenStream {
+		words[i] = string(token.Term)
+	}
+	return words, nil
+}
+
 func tokenize(funcArgs []string, tokenizer Tokenizer) ([]string, error) {
 	if len(funcArgs) != 1 {
 		return nil, x.Errorf("Function requires 1 arguments, but got %d",
@@ -0,0 +1,284 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tok
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+const (
+	vectorName = "vector"
+	// The vectors are put in a bucket of every table. For the cosine metric,
+	// the bucket is given by the sides of the random hyperplanes of the table
+	// the vector is on, and a search looks up vectorBits+1 of the
+	// 1<<vectorBits buckets of every table. For the euclidean metric, it is
+	// given by the slots of width Width the vector falls in when projected on
+	// vectorProjections random lines of the table, and a search looks up
+	// vectorProjections+1 buckets of every table.
+	vectorTables      = 8
+	vectorBits        = 12
+	vectorProjections = 4
+	maxVectorDim      = math.MaxUint16
+
+	// DefaultVectorWidth is the width of the slots of the euclidean index.
+	DefaultVectorWidth = 4.0
+
+	EuclideanMetric = "euclidean"
+	CosineMetric    = "cosine"
+)
+
+// VectorTokenizer indexes vectors for the approximate nearest neighbor search,
+// with locality sensitive hashing. The vectors which are close are likely to
+// have the same tokens: for the cosine metric, they are mostly on the same
+// side of the random hyperplanes through the origin which give the tokens, and
+// for the euclidean metric, their projections on random lines mostly fall in
+// the same slots. The neighbors of a vector are found among the vectors having
+// one of its tokens, or one of the tokens next to them.
+type VectorTokenizer struct {
+	// Metric is the distance between the vectors, euclidean by default.
+	Metric string
+	// Width is the width of the slots of the euclidean metric,
+	// DefaultVectorWidth if zero. The vectors closer than about the width are
+	// likely to be found.
+	Width float64
+}
+
+func (t VectorTokenizer) Name() string {
+	var args []string
+	if t.Metric == CosineMetric {
+		args = append(args, "metric:<"+t.Metric+">")
+	}
+	if t.Width != 0 {
+		args = append(args, "width:<"+strconv.FormatFloat(t.Width, 'g', -1, 64)+">")
+	}
+	if len(args) == 0 {
+		return vectorName
+	}
+	return vectorName + "(" + strings.Join(args, ",") + ")"
+}
+func (t VectorTokenizer) Type() types.TypeID { return types.Float32VectorID }
+func (t VectorTokenizer) Tokens(sv types.Val) ([]string, error) {
+	v, ok := sv.Value.([]float32)
+	if !ok {
+		return nil, x.Errorf("Vector indices only supported for float32vector types")
+	}
+	if len(v) > maxVectorDim {
+		return nil, x.Errorf("Vectors of more than %d dimensions can't be indexed", maxVectorDim)
+	}
+	hash := vectorHashes(len(v))
+	tokens := make([]string, vectorTables)
+	for table := range tokens {
+		if t.Metric == CosineMetric {
+			tokens[table] = t.bucket(table, len(v), signature(v, hash.planes[table]))
+			continue
+		}
+		slots, _ := t.slots(v, hash, table)
+		tokens[table] = t.bucket(table, len(v), slots...)
+	}
+	return tokens, nil
+}
+func (t VectorTokenizer) Identifier() byte { return 0x12 }
+func (t VectorTokenizer) IsSortable() bool { return false }
+func (t VectorTokenizer) IsLossy() bool    { return true }
+
+// Distance returns the distance between two vectors of the same length.
+func (t VectorTokenizer) Distance(a, b []float32) float64 {
+	if t.Metric == CosineMetric {
+		return types.CosineDistance(a, b)
+	}
+	return types.VectorDistance(a, b)
+}
+
+// ProbeTokens returns the tokens to look up for the neighbors of v: the tokens
+// of v and, for the cosine metric, the ones of the buckets on the other side
+// of a single hyperplane, or for the euclidean metric, the ones of the buckets
+// next to the nearer edge of a single slot.
+func (t VectorTokenizer) ProbeTokens(v []float32) []string {
+	hash := vectorHashes(len(v))
+	if t.Metric == CosineMetric {
+		tokens := make([]string, 0, vectorTables*(vectorBits+1))
+		for table := 0; table < vectorTables; table++ {
+			sig := signature(v, hash.planes[table])
+			tokens = append(tokens, t.bucket(table, len(v), sig))
+			for bit := uint(0); bit < vectorBits; bit++ {
+				tokens = append(tokens, t.bucket(table, len(v), sig^(1<<bit)))
+			}
+		}
+		return tokens
+	}
+	tokens := make([]string, 0, vectorTables*(vectorProjections+1))
+	for table := 0; table < vectorTables; table++ {
+		slots, pos := t.slots(v, hash, table)
+		tokens = append(tokens, t.bucket(table, len(v), slots...))
+		for i := range slots {
+			next := append([]uint32(nil), slots...)
+			if pos[i] < 0.5 {
+				next[i]--
+			} else {
+				next[i]++
+			}
+			tokens = append(tokens, t.bucket(table, len(v), next...))
+		}
+	}
+	return tokens
+}
+
+// bucket returns the token of the bucket of the table given by the key, which
+// is the signature of the vector or its slots.
+func (t VectorTokenizer) bucket(table, dim int, key ...uint32) string {
+	b := make([]byte, 3, 3+4*len(key))
+	b[0], b[1], b[2] = byte(table), byte(dim>>8), byte(dim)
+	for _, k := range key {
+		b = append(b, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], k)
+	}
+	return encodeToken(string(b), t.Identifier())
+}
+
+// signature returns the bits telling on which side of the planes v is.
+func signature(v []float32, planes [][]float32) uint32 {
+	var sig uint32
+	for bit, plane := range planes {
+		if dot(v, plane) >= 0 {
+			sig |= 1 << uint(bit)
+		}
+	}
+	return sig
+}
+
+// slots returns the slots the projections of v on the lines of the table fall
+// in, along with how far across the slots they are, between 0 and 1.
+func (t VectorTokenizer) slots(v []float32, hash *vectorHash, table int) ([]uint32, []float64) {
+	width := t.Width
+	if width == 0 {
+		width = DefaultVectorWidth
+	}
+	slots := make([]uint32, vectorProjections)
+	pos := make([]float64, vectorProjections)
+	for i := range slots {
+		p := dot(v, hash.planes[table][i])/width + hash.offsets[table][i]
+		slot := math.Floor(p)
+		pos[i] = p - slot
+		// The slots wrap around, so that they fit in the token.
+		slots[i] = uint32(int64(math.Max(math.MinInt64/2, math.Min(slot, math.MaxInt64/2))))
+	}
+	return slots, pos
+}
+
+func dot(v []float32, plane []float32) float64 {
+	var d float64
+	for i, f := range v {
+		d += float64(f) * float64(plane[i])
+	}
+	return d
+}
+
+// vectorHash holds the random hyperplanes of every table, whose normals are
+// also the lines of the euclidean metric, and the random offsets of the slots
+// on the lines, as a fraction of their width.
+type vectorHash struct {
+	planes  [][][]float32
+	offsets [][]float64
+}
+
+var vectorHashCache = struct {
+	sync.Mutex
+	byDim map[int]*vectorHash
+}{byDim: make(map[int]*vectorHash)}
+
+// vectorHashes returns the random hyperplanes and offsets of every table for
+// the vectors of dim dimensions. They are generated from a fixed seed, so that
+// they stay the same for the index.
+func vectorHashes(dim int) *vectorHash {
+	vectorHashCache.Lock()
+	defer vectorHashCache.Unlock()
+	if hash, ok := vectorHashCache.byDim[dim]; ok {
+		return hash
+	}
+	r := rand.New(rand.NewSource(int64(dim)))
+	hash := &vectorHash{
+		planes:  make([][][]float32, vectorTables),
+		offsets: make([][]float64, vectorTables),
+	}
+	for table := range hash.planes {
+		hash.planes[table] = make([][]float32, vectorBits)
+		for bit := range hash.planes[table] {
+			plane := make([]float32, dim)
+			for i := range plane {
+				plane[i] = float32(r.NormFloat64())
+			}
+			hash.planes[table][bit] = plane
+		}
+	}
+	for table := range hash.offsets {
+		hash.offsets[table] = make([]float64, vectorProjections)
+		for i := range hash.offsets[table] {
+			hash.offsets[table][i] = r.Float64()
+		}
+	}
+	vectorHashCache.byDim[dim] = hash
+	return hash
+}
+
+// parseVector returns the vector tokenizer for names like
+// vector(metric:<cosine>) or vector(metric:<euclidean>,width:<10>). The width
+// is only an option of the euclidean metric.
+func parseVector(name string) (Tokenizer, bool) {
+	if !strings.HasPrefix(name, vectorName+"(") || !strings.HasSuffix(name, ")") {
+		return nil, false
+	}
+	args, ok := splitArgs(name[len(vectorName)+1 : len(name)-1])
+	if !ok {
+		return nil, false
+	}
+	var t VectorTokenizer
+	for _, arg := range args {
+		kv := strings.SplitN(arg, ":", 2)
+		if len(kv) != 2 {
+			return nil, false
+		}
+		val := strings.Trim(strings.TrimSpace(kv[1]), "<>")
+		switch strings.TrimSpace(kv[0]) {
+		case "metric":
+			if val != EuclideanMetric && val != CosineMetric {
+				return nil, false
+			}
+			t.Metric = val
+		case "width":
+			width, err := strconv.ParseFloat(val, 64)
+			if err != nil || width <= 0 || math.IsInf(width, 0) {
+				return nil, false
+			}
+			t.Width = width
+		default:
+			return nil, false
+		}
+	}
+	if t.Metric == CosineMetric && t.Width != 0 {
+		return nil, false
+	}
+	return t, true
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode"
+)
+
+// Collator orders strings following the alphabet of a language instead of
+// comparing their bytes. Letters are first compared ignoring accents and case.
+// Ties are then broken by the accents, and then by the case.
+type Collator struct {
+	// Letters sorted as separate letters right after another one, e.g. å, ä
+	// and ö after z in Swedish.
+	after map[rune]rune
+	// Letters which are sorted like another letter of the alphabet.
+	alias map[rune]rune
+}
+
+// Tailorings for the languages whose alphabet differs from the default one.
+var collators = map[string]*Collator{
+	"da": danishCollator,
+	"nb": danishCollator,
+	"nn": danishCollator,
+	"no": danishCollator,
+	"sv": swedishCollator,
+	"fi": swedishCollator,
+	"es": {
+		after: map[rune]rune{'ñ': 'n'},
+	},
+}
+
+var swedishCollator = &Collator{
+	after: map[rune]rune{'å': 'z', 'ä': 'å', 'ö': 'ä'},
+	alias: map[rune]rune{'æ': 'ä', 'ø': 'ö', 'ü': 'y'},
+}
+
+var danishCollator = &Collator{
+	after: map[rune]rune{'æ': 'z', 'ø': 'æ', 'å': 'ø'},
+	alias: map[rune]rune{'ä': 'æ', 'ö': 'ø', 'ü': 'y'},
+}
+
+// The collator of the languages without a tailoring, e.g. German or English.
+var defaultCollator = &Collator{}
+
+// CollatorFor returns the collator to sort values in the language with the
+// given tag, or nil if values shouldn't be collated, i.e. when no language or
+// any language (.) is asked for.
+func CollatorFor(lang string) *Collator {
+	if lang == "" || lang == "." {
+		return nil
+	}
+	// Only the primary language subtag matters, e.g. sv for sv-FI.
+	if idx := strings.IndexAny(lang, "-_"); idx >= 0 {
+		lang = lang[:idx]
+	}
+	if c, ok := collators[strings.ToLower(lang)]; ok {
+		return c
+	}
+	return defaultCollator
+}
+
+// Letters with diacritics and the base letter they are sorted with.
+var baseLetters = map[rune]rune{}
+
+func init() {
+	for base, letters := range map[rune]string{
+		'a': "àáâãäåāăą",
+		'c': "çćĉċč",
+		'd': "ďđð",
+		'e': "èéêëēĕėęě",
+		'g': "ĝğġģ",
+		'h': "ĥħ",
+		'i': "ìíîïĩīĭįı",
+		'j': "ĵ",
+		'k': "ķ",
+		'l': "ĺļľŀł",
+		'n': "ñńņňŉ",
+		'o': "òóôõöøōŏő",
+		'r': "ŕŗř",
+		's': "śŝşš",
+		't': "ţťŧ",
+		'u': "ùúûüũūŭůűų",
+		'w': "ŵ",
+		'y': "ýÿŷ",
+		'z': "źżž",
+	} {
+		for _, r := range letters {
+			baseLetters[r] = base
+		}
+	}
+}
+
+// Letters sorted as a sequence of letters.
+var expansions = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'þ': "th",
+}
+
+// BaseLetters returns the letters without diacritics the lower case rune r is
+// sorted as, or r itself if it has none.
+func BaseLetters(r rune) string {
+	if s, ok := expansions[r]; ok {
+		return s
+	}
+	if base, ok := baseLetters[r]; ok {
+		return string(base)
+	}
+	return string(r)
+}
+
+func primaryWeight(r rune) uint32 {
+	return uint32(r) << 8
+}
+
+// weights appends the primary and secondary weights of the lower case rune r.
+func (c *Collator) weights(r rune, primary, secondary []uint32) ([]uint32, []uint32) {
+	if a, ok := c.alias[r]; ok {
+		// Sorted as another letter, with the difference as a secondary one.
+		p, _ := c.weights(a, nil, nil)
+		return append(primary, p...), append(secondary, uint32(r)+1)
+	}
+	if _, ok := c.after[r]; ok {
+		// Walk back to the letter of the alphabet the chain starts after.
+		var n uint32
+		base := r
+		for {
+			prev, ok := c.after[base]
+			if !ok {
+				break
+			}
+			base = prev
+			n++
+		}
+		return append(primary, primaryWeight(base)+n), append(secondary, 1)
+	}
+	if s, ok := expansions[r]; ok {
+		for _, e := range s {
+			primary = append(primary, primaryWeight(e))
+			secondary = append(secondary, uint32(r)+1)
+		}
+		return primary, secondary
+	}
+	if base, ok := baseLetters[r]; ok {
+		return append(primary, primaryWeight(base)), append(secondary, uint32(r)+1)
+	}
+	return append(primary, primaryWeight(r)), append(secondary, 1)
+}
+
+// Key returns a sort key for s, such that comparing the bytes of the keys of
+// two strings gives their order in the alphabet of the collator.
+func (c *Collator) Key(s string) string {
+	var primary, secondary []uint32
+	var tertiary []byte
+	for _, r := range s {
+		lower := unicode.ToLower(r)
+		n := len(primary)
+		primary, secondary = c.weights(lower, primary, secondary)
+		for i := n; i < len(primary); i++ {
+			if lower != r {
+				tertiary = append(tertiary, 2)
+			} else {
+				tertiary = append(tertiary, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	var w [4]byte
+	for _, level := range [][]uint32{primary, secondary} {
+		for _, p := range level {
+			binary.BigEndian.PutUint32(w[:], p)
+			buf.Write(w[:])
+		}
+		// The separator is less than any weight, so that a prefix sorts first.
+		buf.Write([]byte{0, 0, 0, 0})
+	}
+	buf.Write(tertiary)
+	buf.WriteByte(0)
+	// Strings which only differ in ways the collator ignores are still
+	// ordered deterministically.
+	buf.WriteString(s)
+	return buf.String()
+}
+
+// Compare returns -1, 0 or 1 if a sorts before, the same as or after b.
+func (c *Collator) Compare(a, b string) int {
+	return strings.Compare(c.Key(a), c.Key(b))
+}
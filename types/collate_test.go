@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func collate(lang string, in []string) []string {
+	c := CollatorFor(lang)
+	out := append([]string{}, in...)
+	sort.Slice(out, func(i, j int) bool { return c.Compare(out[i], out[j]) < 0 })
+	return out
+}
+
+func TestCollateGerman(t *testing.T) {
+	in := []string{"Zebra", "Äpfel", "apfel", "Bär", "Bahn", "Straße", "Strasse", "Apfel"}
+	require.Equal(t,
+		[]string{"apfel", "Apfel", "Äpfel", "Bahn", "Bär", "Strasse", "Straße", "Zebra"},
+		collate("de", in))
+}
+
+func TestCollateSwedish(t *testing.T) {
+	in := []string{"Öl", "Zebra", "Äpple", "Ål", "Apa"}
+	require.Equal(t, []string{"Apa", "Zebra", "Ål", "Äpple", "Öl"}, collate("sv", in))
+	require.Equal(t, []string{"Apa", "Zebra", "Ål", "Äpple", "Öl"}, collate("sv-FI", in))
+	require.Equal(t, []string{"Ål", "Apa", "Äpple", "Öl", "Zebra"}, collate("de", in))
+}
+
+func TestCollatorFor(t *testing.T) {
+	require.Nil(t, CollatorFor(""))
+	require.Nil(t, CollatorFor("."))
+	require.Equal(t, defaultCollator, CollatorFor("en"))
+	require.Equal(t, defaultCollator, CollatorFor("de-AT"))
+	require.Equal(t, swedishCollator, CollatorFor("SV"))
+}
@@ -84,6 +84,12 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				*res = w
 			case PasswordID:
 				*res = string(data)
+			case Float32VectorID:
+				v, err := decodeVector(data)
+				if err != nil {
+					return to, err
+				}
+				*res = v
 			default:
 				return to, cantConvert(fromID, toID)
 			}
@@ -123,6 +129,14 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				}
 				*res = t
 			case GeoID:
+				if IsWKT(vc) {
+					g, err := ParseWKT(vc)
+					if err != nil {
+						return to, err
+					}
+					*res = g
+					break
+				}
 				var g geom.T
 				text := bytes.Replace([]byte(vc), []byte("'"), []byte("\""), -1)
 				if err := geojson.Unmarshal(text, &g); err != nil {
@@ -135,6 +149,12 @@ func Convert(from Val, toID TypeID) (Val, error) {
 					return to, err
 				}
 				*res = password
+			case Float32VectorID:
+				v, err := ParseVector(vc)
+				if err != nil {
+					return to, err
+				}
+				*res = v
 			default:
 				return to, cantConvert(fromID, toID)
 			}
@@ -317,6 +337,23 @@ func Convert(from Val, toID TypeID) (Val, error) {
 				return to, cantConvert(fromID, toID)
 			}
 		}
+	case Float32VectorID:
+		{
+			vc, err := decodeVector(data)
+			if err != nil {
+				return to, err
+			}
+			switch toID {
+			case Float32VectorID:
+				*res = vc
+			case BinaryID:
+				*res = encodeVector(vc)
+			case StringID, DefaultID:
+				*res = FormatVector(vc)
+			default:
+				return to, cantConvert(fromID, toID)
+			}
+		}
 	default:
 		return to, cantConvert(fromID, toID)
 	}
@@ -447,6 +484,19 @@ func Marshal(from Val, to *Val) error {
 		default:
 			return cantConvert(fromID, toID)
 		}
+	case Float32VectorID:
+		vc, ok := val.([]float32)
+		if !ok {
+			return x.Errorf("Expected a float32vector type")
+		}
+		switch toID {
+		case BinaryID:
+			*res = encodeVector(vc)
+		case StringID, DefaultID:
+			*res = FormatVector(vc)
+		default:
+			return cantConvert(fromID, toID)
+		}
 
 	default:
 		return cantConvert(fromID, toID)
@@ -516,6 +566,13 @@ func ObjectValue(id TypeID, value interface{}) (*protos.Value, error) {
 			return def, x.Errorf("Expected value of type password. Got : %v", value)
 		}
 		return &protos.Value{&protos.Value_PasswordVal{v}}, nil
+	// Vectors are sent in binary format too, as there is no value for them.
+	case Float32VectorID:
+		b, err := toBinary(id, value)
+		if err != nil {
+			return def, err
+		}
+		return &protos.Value{&protos.Value_BytesVal{b}}, nil
 	default:
 		return def, x.Errorf("ObjectValue not available for: %v", id)
 	}
@@ -549,6 +606,8 @@ func (v Val) MarshalJSON() ([]byte, error) {
 		return json.Marshal(v.Value.(string))
 	case PasswordID:
 		return json.Marshal(v.Value.(string))
+	case Float32VectorID:
+		return json.Marshal(v.Value.([]float32))
 	}
 	return nil, x.Errorf("Invalid type for MarshalJSON: %v", v.Tid)
 }
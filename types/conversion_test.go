@@ -507,3 +507,34 @@ func TestConvertToString(t *testing.T) {
 	}
 }
 */
+
+func TestConversionVector(t *testing.T) {
+	v, err := Convert(Val{DefaultID, []byte("[0.5, -1, 3e2]")}, Float32VectorID)
+	if err != nil {
+		t.Fatalf("Unexpected error converting to vector: %v", err)
+	}
+	if !reflect.DeepEqual(v.Value, []float32{0.5, -1, 300}) {
+		t.Errorf("Expected [0.5 -1 300], got %v", v.Value)
+	}
+
+	data := ValueForType(BinaryID)
+	if err := Marshal(v, &data); err != nil {
+		t.Fatalf("Unexpected error marshalling vector: %v", err)
+	}
+	back, err := Convert(Val{Float32VectorID, data.Value}, Float32VectorID)
+	if err != nil || !reflect.DeepEqual(back.Value, v.Value) {
+		t.Errorf("Expected %v after marshalling, got %v (%v)", v.Value, back.Value, err)
+	}
+
+	s, err := Convert(Val{Float32VectorID, data.Value}, StringID)
+	if err != nil || s.Value != "[0.5, -1, 300]" {
+		t.Errorf("Expected [0.5, -1, 300] as string, got %v (%v)", s.Value, err)
+	}
+
+	for _, in := range []string{"0.5, 1", "[]", "[1, a]", "[1, NaN]", "[Inf, 0]", "[-inf]",
+		"[1e39]"} {
+		if _, err := Convert(Val{StringID, []byte(in)}, Float32VectorID); err == nil {
+			t.Errorf("Expected error converting %q to vector", in)
+		}
+	}
+}
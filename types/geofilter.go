@@ -18,9 +18,11 @@ package types
 
 import (
 	"bytes"
+	"math"
 	"strconv"
 	"strings"
 
+	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 	"github.com/twpayne/go-geom"
 
@@ -272,6 +274,35 @@ func (q GeoQueryData) isWithin(g geom.T) bool {
 			}
 			return false
 		}
+	case *geom.LineString:
+		pl, err := polylineFromLineString(geometry)
+		if err != nil {
+			return false
+		}
+		for _, l := range q.loops {
+			if PolylineWithin(l, pl) {
+				return true
+			}
+		}
+		return false
+	case *geom.MultiLineString:
+		// Each line should be within some loop of q.loops.
+		for i := 0; i < geometry.NumLineStrings(); i++ {
+			pl, err := polylineFromLineString(geometry.LineString(i))
+			if err != nil {
+				return false
+			}
+			var within bool
+			for _, l := range q.loops {
+				if within = PolylineWithin(l, pl); within {
+					break
+				}
+			}
+			if !within {
+				return false
+			}
+		}
+		return geometry.NumLineStrings() > 0
 	case *geom.MultiPolygon:
 		// We check each polygon in the multipolygon should be within some loop of q.loops.
 		if len(q.loops) > 0 {
@@ -396,6 +427,30 @@ func (q GeoQueryData) intersects(g geom.T) bool {
 			}
 		}
 		return false
+	case *geom.LineString:
+		pl, err := polylineFromLineString(v)
+		if err != nil {
+			return false
+		}
+		for _, loop := range q.loops {
+			if PolylineIntersects(loop, pl) {
+				return true
+			}
+		}
+		return false
+	case *geom.MultiLineString:
+		for i := 0; i < v.NumLineStrings(); i++ {
+			pl, err := polylineFromLineString(v.LineString(i))
+			if err != nil {
+				return false
+			}
+			for _, loop := range q.loops {
+				if PolylineIntersects(loop, pl) {
+					return true
+				}
+			}
+		}
+		return false
 	default:
 		// A type that we don't know how to handle.
 		return false
@@ -434,3 +489,155 @@ func FilterGeoUids(uids *protos.List, values []*protos.TaskValue, q *GeoQueryDat
 	}
 	return rv
 }
+
+const (
+	// knnRadius is the radius in metres of the first lookup of the nearest
+	// geometries, which is doubled until there are enough of them.
+	knnRadius = 1000
+	// maxKnnRadius is the largest radius looked up, beyond which all the
+	// geometries are compared.
+	maxKnnRadius = math.Pi / 2 * EarthRadiusMeters
+)
+
+// KnnQuery is the query of the k geometries nearest to a point.
+type KnnQuery struct {
+	point *geom.Point
+	pt    s2.Point
+	K     int
+}
+
+// NewKnnQuery returns the query of knn(pred, point, k).
+func NewKnnQuery(srcFunc *protos.SrcFunction) (*KnnQuery, error) {
+	if len(srcFunc.Args) != 2 {
+		return nil, x.Errorf("knn function requires 2 arguments, but got %d",
+			len(srcFunc.Args))
+	}
+	g, err := convertToGeom(srcFunc.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	p, ok := g.(*geom.Point)
+	if !ok {
+		return nil, x.Errorf("knn function requires a point, but got %T", g)
+	}
+	k, err := strconv.Atoi(srcFunc.Args[1])
+	if err != nil {
+		return nil, x.Wrapf(err, "Error while converting number of neighbors to int")
+	}
+	if k <= 0 {
+		return nil, x.Errorf("knn function requires a positive number of neighbors. Got: %d", k)
+	}
+	return &KnnQuery{point: p, pt: pointFromPoint(p), K: k}, nil
+}
+
+// Radii returns the radii in metres of the successive lookups of the index for
+// the nearest geometries, the last one being 0 for all the geometries.
+func (q *KnnQuery) Radii() []float64 {
+	var radii []float64
+	for r := float64(knnRadius); r < maxKnnRadius; r *= 2 {
+		radii = append(radii, r)
+	}
+	return append(radii, 0)
+}
+
+// Tokens returns the tokens to look up for the geometries within radius
+// metres of the point, or for all the geometries if radius is 0.
+func (q *KnnQuery) Tokens(radius float64) ([]string, error) {
+	if radius == 0 {
+		// Every geometry has a parent cell of the lowest level.
+		var cells s2.CellUnion
+		for face := 0; face < 6; face++ {
+			f := s2.CellIDFromFace(face)
+			end := f.ChildEndAtLevel(MinCellLevel)
+			for c := f.ChildBeginAtLevel(MinCellLevel); c != end; c = c.Next() {
+				cells = append(cells, c)
+			}
+		}
+		return createTokens(cells, parentPrefix), nil
+	}
+	toks, _, err := queryTokensGeo(QueryTypeNear, q.point, radius)
+	return toks, err
+}
+
+// Settled returns true if all the geometries within dist metres of the point
+// are found by the lookup of the tokens within radius metres. The loop around
+// the point is a bit smaller than the circle, so a small margin is kept.
+func (q *KnnQuery) Settled(dist, radius float64) bool {
+	return radius == 0 || dist <= radius*0.99
+}
+
+// Distance returns the distance in metres between the point and g, which is 0
+// if g contains the point. It returns false for the geometries it can't
+// measure.
+func (q *KnnQuery) Distance(g geom.T) (float64, bool) {
+	switch v := g.(type) {
+	case *geom.Point:
+		return float64(EarthDistance(q.pt.Distance(pointFromPoint(v)))), true
+	case *geom.Polygon:
+		l, err := loopFromPolygon(v)
+		if err != nil {
+			return 0, false
+		}
+		return float64(EarthDistance(q.loopDistance(l))), true
+	case *geom.MultiPolygon:
+		min := s1.InfAngle()
+		for i := 0; i < v.NumPolygons(); i++ {
+			l, err := loopFromPolygon(v.Polygon(i))
+			if err != nil {
+				return 0, false
+			}
+			if d := q.loopDistance(l); d < min {
+				min = d
+			}
+		}
+		return float64(EarthDistance(min)), v.NumPolygons() > 0
+	case *geom.LineString:
+		pl, err := polylineFromLineString(v)
+		if err != nil {
+			return 0, false
+		}
+		return float64(EarthDistance(q.polylineDistance(pl))), true
+	case *geom.MultiLineString:
+		min := s1.InfAngle()
+		for i := 0; i < v.NumLineStrings(); i++ {
+			pl, err := polylineFromLineString(v.LineString(i))
+			if err != nil {
+				return 0, false
+			}
+			if d := q.polylineDistance(pl); d < min {
+				min = d
+			}
+		}
+		return float64(EarthDistance(min)), v.NumLineStrings() > 0
+	}
+	return 0, false
+}
+
+// polylineDistance returns the angle between the point and its closest edge of
+// the polyline.
+func (q *KnnQuery) polylineDistance(pl *s2.Polyline) s1.Angle {
+	pts := *pl
+	min := s1.InfAngle()
+	for i := 0; i+1 < len(pts); i++ {
+		if d := s2.DistanceFromSegment(q.pt, pts[i], pts[i+1]); d < min {
+			min = d
+		}
+	}
+	return min
+}
+
+// loopDistance returns the angle between the point and its closest edge of l,
+// or 0 if l contains the point.
+func (q *KnnQuery) loopDistance(l *s2.Loop) s1.Angle {
+	if l.ContainsPoint(q.pt) {
+		return 0
+	}
+	vs := l.Vertices()
+	min := s1.InfAngle()
+	for i := range vs {
+		if d := s2.DistanceFromSegment(q.pt, vs[i], vs[(i+1)%len(vs)]); d < min {
+			min = d
+		}
+	}
+	return min
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/x"
 	"github.com/stretchr/testify/require"
 	"github.com/twpayne/go-geom"
@@ -396,3 +397,87 @@ func TestMatchesFilterNearPoint(t *testing.T) {
 	})
 	require.True(t, qd.MatchesFilter(poly))
 }
+
+func TestMatchesFilterLineString(t *testing.T) {
+	p := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122, 37}, {-123, 37}, {-123, 38}, {-122, 38}, {-122, 37}},
+	})
+	data := formDataPolygon(t, p)
+	_, qd, err := queryTokens(QueryTypeIntersects, data, 0.0)
+	require.NoError(t, err)
+
+	// Line inside the polygon
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-122.2, 37.2}, {-122.8, 37.8}})
+	require.True(t, qd.MatchesFilter(l))
+
+	// Line crossing the polygon with no vertex in it
+	l = geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-121.5, 37.5}, {-123.5, 37.5}})
+	require.True(t, qd.MatchesFilter(l))
+
+	// Line outside the polygon
+	l = geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-121.5, 37.5}, {-121.5, 38.5}})
+	require.False(t, qd.MatchesFilter(l))
+
+	ml := geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-121.5, 37.5}, {-121.5, 38.5}},
+		{{-122.2, 37.2}, {-122.8, 37.8}},
+	})
+	require.True(t, qd.MatchesFilter(ml))
+
+	_, qd, err = queryTokens(QueryTypeWithin, data, 0.0)
+	require.NoError(t, err)
+	l = geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-122.2, 37.2}, {-122.8, 37.8}})
+	require.True(t, qd.MatchesFilter(l))
+	l = geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-121.5, 37.5}, {-123.5, 37.5}})
+	require.False(t, qd.MatchesFilter(l))
+	require.False(t, qd.MatchesFilter(ml))
+
+	// A line passing close to the point is near it.
+	pt := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
+	_, qd, err = queryTokens(QueryTypeNear, formDataPoint(t, pt), 1000.0)
+	require.NoError(t, err)
+	l = geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-122.09, 37.428}, {-122.07, 37.428}})
+	require.True(t, qd.MatchesFilter(l))
+	l = geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{-122.09, 37.5}, {-122.07, 37.5}})
+	require.False(t, qd.MatchesFilter(l))
+}
+
+func TestKnnQuery(t *testing.T) {
+	q, err := NewKnnQuery(&protos.SrcFunction{Name: "knn", Args: []string{"[0.0, 0.0]", "3"}})
+	require.NoError(t, err)
+	require.Equal(t, 3, q.K)
+
+	radii := q.Radii()
+	require.Equal(t, float64(knnRadius), radii[0])
+	require.Equal(t, float64(0), radii[len(radii)-1])
+	toks, err := q.Tokens(radii[0])
+	require.NoError(t, err)
+	require.NotEmpty(t, toks)
+
+	d, ok := q.Distance(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0, 1}))
+	require.True(t, ok)
+	require.InDelta(t, 111195, d, 1)
+	require.True(t, q.Settled(d, 120000))
+	require.False(t, q.Settled(d, 100000))
+
+	// The distance to a polygon is the one to its closest edge, or 0 inside.
+	p := geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{1, -1}, {2, -1}, {2, 1}, {1, 1}, {1, -1}}})
+	d, ok = q.Distance(p)
+	require.True(t, ok)
+	require.InDelta(t, 111195, d, 1)
+	p = geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}, {-1, -1}}})
+	d, ok = q.Distance(p)
+	require.True(t, ok)
+	require.Equal(t, float64(0), d)
+
+	// The distance to a line is the one to its closest segment.
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{5, 5}, {1, 1}, {1, -1}})
+	d, ok = q.Distance(l)
+	require.True(t, ok)
+	require.InDelta(t, 111195, d, 1)
+
+	_, err = NewKnnQuery(&protos.SrcFunction{Name: "knn", Args: []string{"[0.0, 0.0]", "-1"}})
+	require.Error(t, err)
+}
@@ -130,12 +130,66 @@ func Intersects(l1 *s2.Loop, l2 *s2.Loop) bool {
 	return intersects(l1, l2)
 }
 
+// polylineCrossesLoop returns true if an edge of the polyline crosses an edge
+// of the loop.
+func polylineCrossesLoop(l *s2.Loop, pl *s2.Polyline) bool {
+	pts := *pl
+	for i := 0; i+1 < len(pts); i++ {
+		crosser := s2.NewChainEdgeCrosser(pts[i], pts[i+1], l.Vertex(0))
+		for j := 1; j <= l.NumEdges(); j++ {
+			if crosser.EdgeOrVertexChainCrossing(l.Vertex(j)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PolylineIntersects returns true if the polyline has a point in the loop.
+func PolylineIntersects(l *s2.Loop, pl *s2.Polyline) bool {
+	if !l.RectBound().Intersects(pl.RectBound()) {
+		return false
+	}
+	if l.ContainsPoint((*pl)[0]) {
+		return true
+	}
+	return polylineCrossesLoop(l, pl)
+}
+
+// PolylineWithin returns true if all the polyline is in the loop.
+func PolylineWithin(l *s2.Loop, pl *s2.Polyline) bool {
+	if !l.RectBound().Contains(pl.RectBound()) {
+		return false
+	}
+	for _, p := range *pl {
+		if !l.ContainsPoint(p) {
+			return false
+		}
+	}
+	return !polylineCrossesLoop(l, pl)
+}
+
 func closed(coords []geom.Coord) bool {
 	l := len(coords)
 	return coords[0][0] == coords[l-1][0] && coords[0][1] == coords[l-1][1]
 }
 
 func convertToGeom(str string) (geom.T, error) {
+	if IsWKT(str) {
+		g, err := ParseWKT(str)
+		if err != nil {
+			return nil, err
+		}
+		switch g.(type) {
+		case *geom.Point, *geom.Polygon, *geom.MultiPolygon:
+			return g, nil
+		}
+		// The values can be line strings and multipoints, but the geo
+		// functions only take the geometries they can cover with loops.
+		return nil, x.Errorf("Geo functions don't take line strings, multiline strings or "+
+			"multipoints. Expected a point, a polygon or a multipolygon. Got: %s", str)
+	}
+
 	s := x.WhiteSpace.Replace(str)
 	if len(s) < 5 { // [1,2]
 		return nil, x.Errorf("Invalid coordinates")
@@ -98,11 +98,40 @@ func indexCells(g geom.T) (parents, cover s2.CellUnion, err error) {
 		// Get parents for all cells in cover.
 		parents := getParentCells(cover, MinCellLevel)
 		return parents, cover, nil
+	case *geom.LineString:
+		pl, err := polylineFromLineString(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		cover := coverPolyline(pl, MinCellLevel, MaxCellLevel, MaxCells)
+		parents := getParentCells(cover, MinCellLevel)
+		return parents, cover, nil
+	case *geom.MultiLineString:
+		var cover s2.CellUnion
+		for i := 0; i < v.NumLineStrings(); i++ {
+			pl, err := polylineFromLineString(v.LineString(i))
+			if err != nil {
+				return nil, nil, err
+			}
+			cover = append(cover, coverPolyline(pl, MinCellLevel, MaxCellLevel, MaxCells)...)
+		}
+		parents := getParentCells(cover, MinCellLevel)
+		return parents, cover, nil
 	default:
 		return nil, nil, x.Errorf("Cannot index geometry of type %T", v)
 	}
 }
 
+// ValidateGeo returns an error for the geometries which can't be indexed, as
+// their type isn't supported or they don't have enough points.
+func ValidateGeo(g geom.T) error {
+	if g == nil {
+		return x.Errorf("Missing geometry")
+	}
+	_, _, err := indexCells(g)
+	return err
+}
+
 const (
 	// MinCellLevel is the smallest cell level (largest cell size) used by indexing
 	MinCellLevel = 5 // Approx 250km x 380km
@@ -148,6 +177,19 @@ func loopFromPolygon(p *geom.Polygon) (*s2.Loop, error) {
 	return l, nil
 }
 
+// polylineFromLineString converts a geom.LineString to a s2.Polyline.
+func polylineFromLineString(l *geom.LineString) (*s2.Polyline, error) {
+	n := l.NumCoords()
+	if n < 2 {
+		return nil, x.Errorf("Can't convert line with less than 2 pts")
+	}
+	pts := make(s2.Polyline, n)
+	for i := 0; i < n; i++ {
+		pts[i] = pointFromCoord(l.Coord(i))
+	}
+	return &pts, nil
+}
+
 // Checks if a ring is clockwise or counter-clockwise. Note: This uses the algorithm for planar
 // polygons and doesn't work for spherical polygons that contain the poles or the antimeridan
 // discontinuity. We use this as a fast approximation instead.
@@ -222,6 +264,16 @@ func coverLoop(l *s2.Loop, minLevel int, maxLevel int, maxCells int) s2.CellUnio
 	return rc.Covering(l)
 }
 
+func coverPolyline(p *s2.Polyline, minLevel int, maxLevel int, maxCells int) s2.CellUnion {
+	rc := &s2.RegionCoverer{
+		MinLevel: minLevel,
+		MaxLevel: maxLevel,
+		LevelMod: 0,
+		MaxCells: maxCells,
+	}
+	return rc.Covering(p)
+}
+
 // appendTokens creates tokens with a certain prefix and append.
 func createTokens(cu s2.CellUnion, prefix string) (toks []string) {
 	for _, c := range cu {
@@ -114,6 +114,38 @@ func TestIndexCellsPolygon(t *testing.T) {
 	require.True(t, len(parents) > len(cover))
 }
 
+func TestIndexCellsLineString(t *testing.T) {
+	l := geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{
+		{-122.4194, 37.7749}, {-122.2711, 37.8044}, {-121.8863, 37.3382}})
+	parents, cover, err := indexCells(l)
+	require.NoError(t, err)
+	require.NotEmpty(t, cover)
+	require.True(t, len(cover) <= MaxCells)
+	for _, c := range cover {
+		require.True(t, c.Level() <= MaxCellLevel && c.Level() >= MinCellLevel)
+		require.Contains(t, parents, c)
+	}
+
+	ml := geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+		{{-122.4194, 37.7749}, {-122.2711, 37.8044}},
+		{{-71.0589, 42.3601}, {-71.0942, 42.3736}},
+	})
+	_, mcover, err := indexCells(ml)
+	require.NoError(t, err)
+	require.True(t, len(mcover) > 1)
+}
+
+func TestValidateGeo(t *testing.T) {
+	require.Error(t, ValidateGeo(nil))
+	require.NoError(t, ValidateGeo(geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{0, 0})))
+	require.NoError(t, ValidateGeo(geom.NewLineString(geom.XY).MustSetCoords(
+		[]geom.Coord{{0, 0}, {1, 1}})))
+	require.Error(t, ValidateGeo(geom.NewLineString(geom.XY).MustSetCoords(
+		[]geom.Coord{{0, 0}})))
+	require.Error(t, ValidateGeo(geom.NewMultiPoint(geom.XY).MustSetCoords(
+		[]geom.Coord{{0, 0}, {1, 1}})))
+}
+
 func TestKeyGeneratorPoint(t *testing.T) {
 	p := geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{-122.082506, 37.4249518})
 	data, err := wkb.Marshal(p, binary.LittleEndian)
@@ -42,6 +42,8 @@ const (
 	UidID      = TypeID(protos.Posting_UID)
 	PasswordID = TypeID(protos.Posting_PASSWORD)
 	DefaultID  = TypeID(protos.Posting_DEFAULT)
+
+	Float32VectorID = TypeID(protos.Posting_FLOAT32VECTOR)
 )
 
 var typeNameMap = map[string]TypeID{
@@ -55,6 +57,8 @@ var typeNameMap = map[string]TypeID{
 	"uid":      UidID,
 	"password": PasswordID,
 	"default":  DefaultID,
+
+	"float32vector": Float32VectorID,
 }
 
 type TypeID protos.Posting_ValType
@@ -81,6 +85,8 @@ func (t TypeID) Name() string {
 		return "default"
 	case BinaryID:
 		return "binary"
+	case Float32VectorID:
+		return "float32vector"
 	}
 	return ""
 }
@@ -145,6 +151,10 @@ func ValueForType(id TypeID) Val {
 		var p string
 		return Val{PasswordID, p}
 
+	case Float32VectorID:
+		var v []float32
+		return Val{Float32VectorID, &v}
+
 	default:
 		return Val{}
 	}
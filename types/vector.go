@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// ParseVector parses a vector written as a list of numbers, like
+// "[0.25, -1, 3e-2]".
+func ParseVector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return nil, x.Errorf("Vector should be a list of numbers in brackets. Got: %q", s)
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if s == "" {
+		return nil, x.Errorf("Vector should have at least one number")
+	}
+	parts := strings.Split(s, ",")
+	v := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, x.Wrapf(err, "While parsing vector")
+		}
+		v[i] = float32(f)
+	}
+	return v, checkVector(v)
+}
+
+// checkVector returns an error if the vector has a number which can't be
+// compared, as its distance to any other vector wouldn't be a number.
+func checkVector(v []float32) error {
+	for _, f := range v {
+		if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+			return x.Errorf("Vector can't have NaN or infinite numbers")
+		}
+	}
+	return nil
+}
+
+// FormatVector writes the vector like ParseVector reads it.
+func FormatVector(v []float32) string {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+func encodeVector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b
+}
+
+func decodeVector(b []byte) ([]float32, error) {
+	if len(b) == 0 || len(b)%4 != 0 {
+		return nil, x.Errorf("Invalid data for float32vector of %d bytes", len(b))
+	}
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v, checkVector(v)
+}
+
+// VectorDistance returns the euclidean distance between two vectors of the
+// same length.
+func VectorDistance(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		d := float64(a[i]) - float64(b[i])
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// CosineDistance returns one minus the cosine of the angle between two
+// vectors of the same length. It is 1 if one of them is zero.
+func CosineDistance(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(na*nb)
+}
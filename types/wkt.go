@@ -0,0 +1,340 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	geom "github.com/twpayne/go-geom"
+
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// IsWKT returns true if s looks like a geometry in the Well-known text format,
+// which starts with the type of the geometry, rather than a GeoJSON object or
+// coordinates.
+func IsWKT(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return false
+	}
+	c := s[0]
+	// A GeoSPARQL WKT literal may start with the IRI of its reference system.
+	return c == '<' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ParseWKT parses a geometry in the Well-known text format, like POINT(1 2) or
+// POLYGON((0 0, 1 0, 1 1, 0 0)). The coordinates are longitude then latitude.
+func ParseWKT(s string) (geom.T, error) {
+	p := &wktParser{s: strings.TrimSpace(s)}
+	if strings.HasPrefix(p.s, "<") {
+		// Skip the reference system, coordinates are always WGS 84.
+		end := strings.IndexByte(p.s, '>')
+		if end < 0 {
+			return nil, x.Errorf("Invalid WKT %s: unterminated reference system", s)
+		}
+		p.pos = end + 1
+	}
+	g, err := p.geometry()
+	if err != nil {
+		return nil, x.Wrapf(err, "Invalid WKT %s", s)
+	}
+	if p.skipSpaces(); p.pos < len(p.s) {
+		return nil, x.Errorf("Invalid WKT %s: unexpected %q", s, p.s[p.pos:])
+	}
+	return g, nil
+}
+
+type wktParser struct {
+	s      string
+	pos    int
+	layout geom.Layout
+}
+
+func (p *wktParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// word returns the next word in upper case, or the empty string if the next
+// token isn't a word.
+func (p *wktParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+// consume returns true and moves past c if it's the next character.
+func (p *wktParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.consume(c) {
+		return x.Errorf("Expected %q at position %d", c, p.pos)
+	}
+	return nil
+}
+
+func (p *wktParser) geometry() (geom.T, error) {
+	typ := p.word()
+	if len(typ) == 0 {
+		return nil, x.Errorf("Expected a geometry type")
+	}
+	p.layout = geom.XY
+	switch dim := p.word(); dim {
+	case "":
+	case "Z":
+		p.layout = geom.XYZ
+	case "M":
+		p.layout = geom.XYM
+	case "ZM":
+		p.layout = geom.XYZM
+	case "EMPTY":
+		return nil, x.Errorf("Empty geometries aren't supported")
+	default:
+		return nil, x.Errorf("Unexpected %s after %s", dim, typ)
+	}
+
+	switch typ {
+	case "POINT":
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		c, err := p.coord()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return geom.NewPoint(p.layout).SetCoords(c)
+	case "LINESTRING":
+		coords, err := p.coords(false)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewLineString(p.layout).SetCoords(coords)
+	case "POLYGON":
+		rings, err := p.rings()
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewPolygon(p.layout).SetCoords(rings)
+	case "MULTIPOINT":
+		coords, err := p.coords(true)
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPoint(p.layout).SetCoords(coords)
+	case "MULTILINESTRING":
+		var lines [][]geom.Coord
+		err := p.list(func() error {
+			coords, err := p.coords(false)
+			lines = append(lines, coords)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiLineString(p.layout).SetCoords(lines)
+	case "MULTIPOLYGON":
+		var polys [][][]geom.Coord
+		err := p.list(func() error {
+			rings, err := p.rings()
+			polys = append(polys, rings)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return geom.NewMultiPolygon(p.layout).SetCoords(polys)
+	}
+	return nil, x.Errorf("Unsupported geometry type %s", typ)
+}
+
+// list parses a parenthesized list of elements separated by commas.
+func (p *wktParser) list(elem func() error) error {
+	if err := p.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := elem(); err != nil {
+			return err
+		}
+		if !p.consume(',') {
+			break
+		}
+	}
+	return p.expect(')')
+}
+
+// coord parses the coordinates of a point, separated by spaces.
+func (p *wktParser) coord() (geom.Coord, error) {
+	c := make(geom.Coord, p.layout.Stride())
+	for i := range c {
+		p.skipSpaces()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, x.Errorf("Expected a coordinate at position %d", start)
+		}
+		c[i] = f
+	}
+	return c, nil
+}
+
+// coords parses a list of points. The points of a multipoint may also be
+// parenthesized, like MULTIPOINT((1 2), (3 4)).
+func (p *wktParser) coords(multi bool) ([]geom.Coord, error) {
+	var coords []geom.Coord
+	err := p.list(func() error {
+		paren := multi && p.consume('(')
+		c, err := p.coord()
+		if err != nil {
+			return err
+		}
+		if paren {
+			if err := p.expect(')'); err != nil {
+				return err
+			}
+		}
+		coords = append(coords, c)
+		return nil
+	})
+	return coords, err
+}
+
+// rings parses the rings of a polygon, which should be closed.
+func (p *wktParser) rings() ([][]geom.Coord, error) {
+	var rings [][]geom.Coord
+	err := p.list(func() error {
+		coords, err := p.coords(false)
+		if err != nil {
+			return err
+		}
+		if !closed(coords) {
+			return x.Errorf("Last coord not same as first")
+		}
+		rings = append(rings, coords)
+		return nil
+	})
+	return rings, err
+}
+
+// MarshalWKT returns the geometry in the Well-known text format.
+func MarshalWKT(g geom.T) (string, error) {
+	var buf bytes.Buffer
+	switch v := g.(type) {
+	case *geom.Point:
+		buf.WriteString("POINT")
+		writeWKTLayout(&buf, v.Layout())
+		buf.WriteByte('(')
+		writeWKTCoord(&buf, v.Coords())
+		buf.WriteByte(')')
+	case *geom.LineString:
+		buf.WriteString("LINESTRING")
+		writeWKTLayout(&buf, v.Layout())
+		writeWKTCoords(&buf, v.Coords())
+	case *geom.Polygon:
+		buf.WriteString("POLYGON")
+		writeWKTLayout(&buf, v.Layout())
+		writeWKTRings(&buf, v.Coords())
+	case *geom.MultiPoint:
+		buf.WriteString("MULTIPOINT")
+		writeWKTLayout(&buf, v.Layout())
+		writeWKTCoords(&buf, v.Coords())
+	case *geom.MultiLineString:
+		buf.WriteString("MULTILINESTRING")
+		writeWKTLayout(&buf, v.Layout())
+		writeWKTRings(&buf, v.Coords())
+	case *geom.MultiPolygon:
+		buf.WriteString("MULTIPOLYGON")
+		writeWKTLayout(&buf, v.Layout())
+		buf.WriteByte('(')
+		for i, rings := range v.Coords() {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			writeWKTRings(&buf, rings)
+		}
+		buf.WriteByte(')')
+	default:
+		return "", x.Errorf("Cannot convert geometry of type %T to WKT", g)
+	}
+	return buf.String(), nil
+}
+
+func writeWKTLayout(buf *bytes.Buffer, l geom.Layout) {
+	switch l {
+	case geom.XYZ:
+		buf.WriteString(" Z")
+	case geom.XYM:
+		buf.WriteString(" M")
+	case geom.XYZM:
+		buf.WriteString(" ZM")
+	}
+}
+
+func writeWKTCoord(buf *bytes.Buffer, c geom.Coord) {
+	for i, f := range c {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+}
+
+func writeWKTCoords(buf *bytes.Buffer, coords []geom.Coord) {
+	buf.WriteByte('(')
+	for i, c := range coords {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		writeWKTCoord(buf, c)
+	}
+	buf.WriteByte(')')
+}
+
+func writeWKTRings(buf *bytes.Buffer, rings [][]geom.Coord) {
+	buf.WriteByte('(')
+	for i, coords := range rings {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		writeWKTCoords(buf, coords)
+	}
+	buf.WriteByte(')')
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	geom "github.com/twpayne/go-geom"
+)
+
+func TestParseWKT(t *testing.T) {
+	tests := []struct {
+		in  string
+		out geom.T
+		wkt string
+	}{
+		{"POINT(1 2)", geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}),
+			"POINT(1 2)"},
+		{" point z ( -1.5 2e1 3 ) ", geom.NewPoint(geom.XYZ).MustSetCoords(geom.Coord{-1.5, 20, 3}),
+			"POINT Z(-1.5 20 3)"},
+		{"<http://www.opengis.net/def/crs/OGC/1.3/CRS84> POINT(1 2)",
+			geom.NewPoint(geom.XY).MustSetCoords(geom.Coord{1, 2}), "POINT(1 2)"},
+		{"LINESTRING(0 0, 1 1, 2 0)",
+			geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 1}, {2, 0}}),
+			"LINESTRING(0 0, 1 1, 2 0)"},
+		{"POLYGON((0 0, 4 0, 4 4, 0 0), (1 1, 2 1, 2 2, 1 1))",
+			geom.NewPolygon(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {4, 0}, {4, 4}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}),
+			"POLYGON((0 0, 4 0, 4 4, 0 0), (1 1, 2 1, 2 2, 1 1))"},
+		{"MULTIPOINT((1 2), (3 4))",
+			geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			"MULTIPOINT(1 2, 3 4)"},
+		{"MULTIPOINT(1 2, 3 4)",
+			geom.NewMultiPoint(geom.XY).MustSetCoords([]geom.Coord{{1, 2}, {3, 4}}),
+			"MULTIPOINT(1 2, 3 4)"},
+		{"MULTILINESTRING((0 0, 1 1), (2 2, 3 3))",
+			geom.NewMultiLineString(geom.XY).MustSetCoords([][]geom.Coord{
+				{{0, 0}, {1, 1}}, {{2, 2}, {3, 3}}}),
+			"MULTILINESTRING((0 0, 1 1), (2 2, 3 3))"},
+		{"MULTIPOLYGON(((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+			geom.NewMultiPolygon(geom.XY).MustSetCoords([][][]geom.Coord{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}, {{{5, 5}, {6, 5}, {6, 6}, {5, 5}}}}),
+			"MULTIPOLYGON(((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))"},
+	}
+	for _, tc := range tests {
+		require.True(t, IsWKT(tc.in))
+		g, err := ParseWKT(tc.in)
+		require.NoError(t, err, tc.in)
+		require.Equal(t, tc.out, g, tc.in)
+		wkt, err := MarshalWKT(g)
+		require.NoError(t, err)
+		require.Equal(t, tc.wkt, wkt)
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	for _, in := range []string{
+		"POINT EMPTY",
+		"POINT(1)",
+		"POINT(1 2",
+		"POINT(1 2) extra",
+		"CIRCLE(1 2)",
+		"POLYGON((0 0, 1 0, 1 1))",
+		"LINESTRING(0 0, a b)",
+		"<http://www.opengis.net/def/crs/OGC/1.3/CRS84 POINT(1 2)",
+	} {
+		_, err := ParseWKT(in)
+		require.Error(t, err, in)
+	}
+	require.False(t, IsWKT(`{"type":"Point","coordinates":[1,2]}`))
+	require.False(t, IsWKT(`[1, 2]`))
+}
+
+func TestConvertWKT(t *testing.T) {
+	src := Val{StringID, []byte("LINESTRING(0 0, 1 1)")}
+	g, err := Convert(src, GeoID)
+	require.NoError(t, err)
+	require.Equal(t, geom.NewLineString(geom.XY).MustSetCoords([]geom.Coord{{0, 0}, {1, 1}}),
+		g.Value)
+
+	p, err := convertToGeom("POLYGON((0 0, 1 0, 1 1, 0 0))")
+	require.NoError(t, err)
+	require.IsType(t, &geom.Polygon{}, p)
+	_, err = convertToGeom("LINESTRING(0 0, 1 1)")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Geo functions don't take line strings")
+	_, err = convertToGeom("MULTILINESTRING((0 0, 1 1), (2 2, 3 3))")
+	require.Error(t, err)
+}
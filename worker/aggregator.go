@@ -30,9 +30,11 @@ func CouldApplyAggregatorOn(agrtr string, typ types.TypeID) bool {
 			typ == types.DateTimeID ||
 			typ == types.StringID ||
 			typ == types.DefaultID)
-	case "sum", "avg":
+	case "sum", "avg", "median", "percentile", "stddev", "variance":
 		return (typ == types.IntID ||
 			typ == types.FloatID)
+	case "count_distinct", "approx_count_distinct":
+		return true
 	default:
 		return false
 	}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	cindex "github.com/google/codesearch/index"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// handleContainsFunction finds the values having the substring through the
+// trigrams of the substring, and keeps the ones which really contain it.
+func handleContainsFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	tokenizer, ok := regexTokenizer(attr, arg.srcFn.ignoreCase)
+	if !ok {
+		return x.Errorf("Attribute %v does not have trigram index for substring search.", attr)
+	}
+
+	// The trigram query is built from the substring quoted as a regular
+	// expression, which also gives all the cases of its trigrams if needed.
+	flags := syntax.Perl
+	if arg.srcFn.ignoreCase {
+		flags |= syntax.FoldCase
+	}
+	re, err := syntax.Parse(regexp.QuoteMeta(arg.srcFn.substr), flags)
+	if err != nil {
+		return err
+	}
+	query := trigramQuery(re, tokenizer)
+	if query.Op == cindex.QAll {
+		return x.Errorf("%s needs a substring of at least 3 characters. Got: %q",
+			arg.srcFn.fname, arg.srcFn.substr)
+	}
+	intersect := &protos.List{}
+	if arg.q.UidList != nil {
+		intersect = arg.q.UidList
+	}
+	uids, err := uidsForRegex(attr, arg.gid, tokenizer.Identifier(), query, intersect)
+	if err != nil {
+		return err
+	}
+
+	match := func(s string) bool { return strings.Contains(s, arg.srcFn.substr) }
+	if arg.srcFn.ignoreCase {
+		ci := regexp.MustCompile("(?i)" + regexp.QuoteMeta(arg.srcFn.substr))
+		match = ci.MatchString
+	}
+
+	lang := langForFunc(arg.q.Langs)
+	filtered := &protos.List{}
+	for _, uid := range uids.Uids {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		pl := posting.Get(x.DataKey(attr, uid))
+		var val types.Val
+		if lang != "" {
+			val, err = pl.ValueForTag(lang)
+		} else {
+			val, err = pl.Value()
+		}
+		if err != nil {
+			continue
+		}
+		strVal, err := types.Convert(val, types.StringID)
+		if err != nil {
+			continue
+		}
+		if match(strVal.Value.(string)) {
+			filtered.Uids = append(filtered.Uids, uid)
+		}
+	}
+	if arg.q.UidList != nil {
+		algo.IntersectWith(filtered, arg.q.UidList, filtered)
+	}
+	arg.out.UidMatrix = append(arg.out.UidMatrix, filtered)
+	return nil
+}
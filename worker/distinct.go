@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"bytes"
+
+	"github.com/dgraph-io/badger"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// distinctTokenizer picks the index of attr to list the distinct values from.
+// Only the tokenizers which keep the whole value in their tokens can be used.
+func distinctTokenizer(attr string) (tok.Tokenizer, error) {
+	for _, t := range schema.State().Tokenizer(attr) {
+		if tok.Decodable(t) && !t.IsLossy() {
+			return t, nil
+		}
+	}
+	return nil, x.Errorf("Attribute %s doesn't have an index keeping whole values, "+
+		"like exact, int or bool, to list distinct values from", attr)
+}
+
+// handleDistinctFunction iterates over the index keys of the attribute and
+// returns the values they were built from, along with the number of nodes
+// having each value.
+func handleDistinctFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	tokenizer, err := distinctTokenizer(attr)
+	if err != nil {
+		return err
+	}
+	prefix := arg.srcFn.distinctPrefix
+	if len(prefix) > 0 && tokenizer.Type() != types.StringID {
+		return x.Errorf("Prefix can only be used to list distinct string values. Got: %s",
+			tokenizer.Type().Name())
+	}
+
+	seekKey := x.IndexKey(attr, string(tokenizer.Identifier())+prefix)
+	iterOpt := badger.DefaultIteratorOptions
+	iterOpt.PrefetchValues = false
+	it := pstore.NewIterator(iterOpt)
+	defer it.Close()
+
+	values := new(protos.ValueList)
+	offset := arg.srcFn.offset
+	for it.Seek(seekKey); it.Valid(); it.Next() {
+		key := it.Item().Key()
+		if !bytes.HasPrefix(key, seekKey) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		k := x.Parse(key)
+		x.AssertTrue(k != nil && k.IsIndex())
+		// Keys of index postings which became empty stay in the store.
+		count := posting.Get(key).Length(0)
+		if count == 0 {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+
+		val, err := tok.DecodeToken(tokenizer, k.Term)
+		if err != nil {
+			return err
+		}
+		data := types.ValueForType(types.BinaryID)
+		if err := types.Marshal(val, &data); err != nil {
+			return err
+		}
+		values.Values = append(values.Values, &protos.TaskValue{
+			Val:     data.Value.([]byte),
+			ValType: int32(val.Tid),
+		})
+		arg.out.Counts = append(arg.out.Counts, uint32(count))
+		if arg.srcFn.count > 0 && len(values.Values) == arg.srcFn.count {
+			break
+		}
+	}
+	arg.out.ValueMatrix = append(arg.out.ValueMatrix, values)
+	arg.out.UidMatrix = append(arg.out.UidMatrix, &emptyUIDList)
+	return nil
+}
@@ -66,6 +66,8 @@ var rdfTypeMap = map[types.TypeID]string{
 	types.GeoID:      "geo:geojson",
 	types.PasswordID: "pwd:password",
 	types.BinaryID:   "xs:base64Binary",
+
+	types.Float32VectorID: "float32vector",
 }
 
 func toRDF(buf *bytes.Buffer, item kv) {
@@ -277,8 +279,8 @@ func export(bdir string) error {
 		key := item.Key()
 		pk := x.Parse(key)
 
-		if pk.IsIndex() || pk.IsReverse() || pk.IsCount() {
-			// Seek to the end of index, reverse and count keys.
+		if pk.IsIndex() || pk.IsReverse() || pk.IsCount() || pk.IsStats() {
+			// Seek to the end of index, reverse, count and stats keys.
 			it.Seek(pk.SkipRangeOfSameType())
 			continue
 		}
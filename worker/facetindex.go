@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"strings"
+
+	"github.com/dgraph-io/badger"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// facetTypes are the types the values of facets can have.
+var facetTypes = []types.TypeID{types.IntID, types.FloatID, types.DateTimeID,
+	types.BoolID, types.StringID}
+
+// handleHasFacetsFunction finds the nodes having an edge of the predicate
+// whose facets match the facets filter, for has(pred) @facets(filter) at root.
+// The candidates are looked up in the facet indexes of the predicate when the
+// filter compares indexed facets, otherwise they are all the nodes having the
+// predicate, which needs the count index. The facets of their edges are then
+// checked.
+func handleHasFacetsFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	ftree, err := preprocessFilter(arg.q.FacetsFilter)
+	if err != nil {
+		return err
+	}
+	fis := make(map[string]tok.FacetIndex)
+	for _, fi := range schema.State().FacetIndexes(attr) {
+		fis[fi.Key] = fi
+	}
+	candidates, err := facetIndexUids(attr, fis, ftree)
+	if err != nil {
+		return err
+	}
+	if candidates == nil {
+		var out protos.Result
+		if err := handleHasFunction(funcArgs{arg.q, arg.gid, arg.srcFn, &out}); err != nil {
+			return err
+		}
+		candidates = algo.MergeSorted(out.UidMatrix)
+	}
+
+	result := &protos.List{}
+	for _, uid := range candidates.Uids {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		var found bool
+		posting.Get(x.DataKey(attr, uid)).Iterate(0, func(p *protos.Posting) bool {
+			found, err = applyFacetsTree(p.Facets, ftree)
+			return !found && err == nil
+		})
+		if err != nil {
+			return err
+		}
+		if found {
+			result.Uids = append(result.Uids, uid)
+		}
+	}
+	arg.out.UidMatrix = append(arg.out.UidMatrix, result)
+	return nil
+}
+
+// facetIndexUids returns the nodes which may have an edge whose facets match
+// the facets filter, according to the facet indexes. It returns nil if the
+// filter can't be answered from them.
+func facetIndexUids(attr string, fis map[string]tok.FacetIndex,
+	ftree *facetsTree) (*protos.List, error) {
+	if ftree.function != nil {
+		fi, ok := fis[ftree.function.key]
+		fnType, fname := parseFuncTypeHelper(ftree.function.name)
+		if !ok || fnType != CompareAttrFn {
+			return nil, nil
+		}
+		var tokens []string
+		for _, typ := range facetTypes {
+			// The value is compared to the facets of the types it converts to.
+			v, err := types.Convert(ftree.function.val, typ)
+			if err != nil {
+				continue
+			}
+			toks, err := facetIndexTokens(attr, fi, fname, v)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, toks...)
+		}
+		return uidsForTokens(attr, tokens), nil
+	}
+
+	var lists []*protos.List
+	for _, c := range ftree.children {
+		l, err := facetIndexUids(attr, fis, c)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	switch strings.ToLower(ftree.op) {
+	case "and":
+		// A single edge has to match both, so the candidates of either are
+		// enough.
+		var known []*protos.List
+		for _, l := range lists {
+			if l != nil {
+				known = append(known, l)
+			}
+		}
+		if len(known) == 0 {
+			return nil, nil
+		}
+		return algo.IntersectSorted(known), nil
+	case "or":
+		for _, l := range lists {
+			if l == nil {
+				return nil, nil
+			}
+		}
+		return algo.MergeSorted(lists), nil
+	}
+	return nil, nil
+}
+
+// facetIndexTokens returns the tokens of the facet index for the facet values
+// of the type of v which compare to v with fname.
+func facetIndexTokens(attr string, fi tok.FacetIndex, fname string,
+	v types.Val) ([]string, error) {
+	token, err := fi.Token(v)
+	if err != nil {
+		return nil, err
+	}
+	if fname == "eq" {
+		return []string{token}, nil
+	}
+
+	itOpt := badger.DefaultIteratorOptions
+	itOpt.PrefetchValues = false
+	itOpt.Reverse = fname == "le" || fname == "lt"
+	it := pstore.NewIterator(itOpt)
+	defer it.Close()
+
+	var out []string
+	indexPrefix := x.IndexKey(attr, fi.Prefix(v.Tid))
+	for it.Seek(x.IndexKey(attr, token)); it.ValidForPrefix(indexPrefix); it.Next() {
+		k := x.Parse(it.Item().Key())
+		x.AssertTrue(k != nil)
+		out = append(out, k.Term)
+	}
+	return out, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"github.com/twpayne/go-geom"
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// handleKnnFunction finds the k geometries nearest to the point. They are
+// looked up in the index within a radius around the point, which is doubled
+// until k geometries are found within it, or among the uids to filter. The uids
+// are returned in a single list, along with their distances in metres.
+func handleKnnFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	q := arg.srcFn.knnQuery
+	distances := make(map[uint64]float64)
+	// measure adds the distances of the uids which weren't measured yet.
+	measure := func(uids []uint64) error {
+		for _, uid := range uids {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			if _, ok := distances[uid]; ok {
+				continue
+			}
+			distances[uid] = -1
+			val, err := posting.Get(x.DataKey(attr, uid)).Value()
+			if err != nil {
+				continue
+			}
+			gc, err := types.Convert(val, types.GeoID)
+			if err != nil {
+				continue
+			}
+			if d, ok := q.Distance(gc.Value.(geom.T)); ok {
+				distances[uid] = d
+			}
+		}
+		return nil
+	}
+
+	if arg.q.UidList != nil {
+		if err := measure(arg.q.UidList.Uids); err != nil {
+			return err
+		}
+	} else {
+		for _, radius := range q.Radii() {
+			tokens, err := q.Tokens(radius)
+			if err != nil {
+				return err
+			}
+			tok.EncodeGeoTokens(tokens)
+			if err := measure(uidsForTokens(attr, tokens).Uids); err != nil {
+				return err
+			}
+			var settled int
+			for _, d := range distances {
+				if d >= 0 && q.Settled(d, radius) {
+					settled++
+				}
+			}
+			if settled >= q.K {
+				break
+			}
+		}
+	}
+
+	neighbors := make([]neighbor, 0, len(distances))
+	for uid, d := range distances {
+		if d >= 0 {
+			neighbors = append(neighbors, neighbor{uid: uid, dist: d})
+		}
+	}
+	return nearestNeighbors(neighbors, q.K, arg.out)
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// levenshtein returns the number of characters to insert, delete or replace
+// to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// minTrigrams returns how many of the trigrams of the text a value must have
+// to be within the distance. An edit of a character changes at most the
+// trigrams overlapping its bytes. When the distance allows a value to have
+// none of them, it is still one, as only the values found in the trigram index
+// can be checked: e.g. match(name, "abc", 1) doesn't find "abd".
+func minTrigrams(text string, numTrigrams, distance int) int {
+	maxRuneLen := 1
+	for _, r := range text {
+		if l := utf8.RuneLen(r); l > maxRuneLen {
+			maxRuneLen = l
+		}
+	}
+	n := numTrigrams - distance*(maxRuneLen+2)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// handleMatchFunction keeps the uids having enough trigrams in common with the
+// text, whose value is within the maximum edit distance of it. The uids are
+// returned in a single list, along with their distances. The values having no
+// trigram in common with the text, like those shorter than 3 bytes, are never
+// found, even when they are within the distance.
+func handleMatchFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	srcFn := arg.srcFn
+	counts := make(map[uint64]int)
+	for _, ul := range arg.out.UidMatrix {
+		for _, uid := range ul.Uids {
+			counts[uid]++
+		}
+	}
+	threshold := minTrigrams(srcFn.matchText, len(srcFn.tokens), srcFn.maxDistance)
+	candidates := make([]uint64, 0, len(counts))
+	for uid, c := range counts {
+		if c >= threshold {
+			candidates = append(candidates, uid)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	lang := langForFunc(arg.q.Langs)
+	matched := &protos.List{}
+	distances := &protos.ValueList{}
+	for _, uid := range candidates {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		pl := posting.Get(x.DataKey(attr, uid))
+		var val types.Val
+		var err error
+		if lang != "" {
+			val, err = pl.ValueForTag(lang)
+		} else {
+			val, err = pl.Value()
+		}
+		if err != nil {
+			continue
+		}
+		strVal, err := types.Convert(val, types.StringID)
+		if err != nil {
+			continue
+		}
+		dist := levenshtein(strVal.Value.(string), srcFn.matchText)
+		if dist > srcFn.maxDistance {
+			continue
+		}
+		data := types.ValueForType(types.BinaryID)
+		if err := types.Marshal(types.Val{Tid: types.IntID, Value: int64(dist)}, &data); err != nil {
+			return err
+		}
+		matched.Uids = append(matched.Uids, uid)
+		distances.Values = append(distances.Values, &protos.TaskValue{
+			Val:     data.Value.([]byte),
+			ValType: int32(types.IntID),
+		})
+	}
+	arg.out.UidMatrix = []*protos.List{matched}
+	arg.out.ValueMatrix = []*protos.ValueList{distances}
+	return nil
+}
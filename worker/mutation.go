@@ -30,6 +30,7 @@ import (
 	"github.com/dgraph-io/dgraph/posting"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
 	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
 )
@@ -103,10 +104,29 @@ func runSchemaMutation(ctx context.Context, update *protos.SchemaUpdate) error {
 	if err := checkSchema(update); err != nil {
 		return err
 	}
+	if err := checkCompositeIndexes(update); err != nil {
+		return err
+	}
+	if err := checkAnalyzers(update); err != nil {
+		return err
+	}
 	old, ok := schema.State().Get(update.Predicate)
 	current := schema.From(update)
 	updateSchema(update.Predicate, current, rv.Index, rv.Group)
 
+	if ok && old.ValueType != current.ValueType {
+		// The values in the composite indexes declared by other predicates are
+		// kept with their schema type.
+		for _, ci := range schema.State().CompositeIndexes(update.Predicate) {
+			if ci.Preds[0] == update.Predicate {
+				continue
+			}
+			if err := n.rebuildOrDelIndex(ctx, ci.Preds[0], true); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Once we remove index or reverse edges from schema, even though the values
 	// are present in db, they won't be used due to validation in work/task.go
 
@@ -244,8 +264,8 @@ func checkSchema(s *protos.SchemaUpdate) error {
 		return x.Errorf("No predicate specified in schema mutation")
 	}
 	typ := types.TypeID(s.ValueType)
-	if typ == types.UidID && s.Directive == protos.SchemaUpdate_INDEX {
-		// index on uid type
+	if typ == types.UidID && s.Directive == protos.SchemaUpdate_INDEX && !hasFacetIndexes(s) {
+		// index on uid type, which can only have facet indexes
 		return x.Errorf("Index not allowed on predicate of type uid on predicate %s",
 			s.Predicate)
 	} else if typ != types.UidID && s.Directive == protos.SchemaUpdate_REVERSE {
@@ -273,6 +293,57 @@ func checkSchema(s *protos.SchemaUpdate) error {
 	return nil
 }
 
+// hasFacetIndexes returns true if the index in the schema update is only made
+// of facet indexes.
+func hasFacetIndexes(s *protos.SchemaUpdate) bool {
+	for _, name := range s.Tokenizer {
+		if !tok.IsFacetIndex(name) {
+			return false
+		}
+	}
+	return len(s.Tokenizer) > 0
+}
+
+// checkCompositeIndexes verifies that the predicates of the composite indexes in
+// the schema update are served by this group, as their values are indexed
+// together.
+func checkCompositeIndexes(s *protos.SchemaUpdate) error {
+	for _, name := range s.Tokenizer {
+		if !tok.IsComposite(name) {
+			continue
+		}
+		ci, err := tok.ParseComposite(s.Predicate, name)
+		if err != nil {
+			return err
+		}
+		for _, pred := range ci.Preds[1:] {
+			if !groups().ServesTablet(pred) {
+				return x.Errorf("Predicate %s in composite index of %s is served by "+
+					"another group", pred, s.Predicate)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAnalyzers verifies that the files of the full text indexes in the schema
+// update are in the analyzer directory of this server, with the contents they
+// had when the schema was parsed.
+func checkAnalyzers(s *protos.SchemaUpdate) error {
+	for _, name := range s.Tokenizer {
+		tokenizer, ok := tok.GetTokenizer(name)
+		if !ok {
+			continue
+		}
+		if ft, ok := tokenizer.(tok.FullTextTokenizer); ok {
+			if _, err := ft.Load(); err != nil {
+				return x.Wrapf(err, "Invalid analyzer for attr %s", s.Predicate)
+			}
+		}
+	}
+	return nil
+}
+
 // If storage type is specified, then check compatibility or convert to schema type
 // if no storage type is specified then convert to schema type.
 func ValidateAndConvert(edge *protos.DirectedEdge, schemaType types.TypeID) error {
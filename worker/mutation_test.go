@@ -146,6 +146,10 @@ func TestCheckSchema(t *testing.T) {
 	s1 = &protos.SchemaUpdate{Predicate: "name", ValueType: uint32(types.StringID), Directive: protos.SchemaUpdate_REVERSE}
 	require.Error(t, checkSchema(s1))
 
+	// facet index on uid type
+	s1 = &protos.SchemaUpdate{Predicate: "friend", ValueType: uint32(types.UidID), Directive: protos.SchemaUpdate_INDEX, Tokenizer: []string{"facet(since)"}}
+	require.NoError(t, checkSchema(s1))
+
 	s1 = &protos.SchemaUpdate{Predicate: "name", ValueType: uint32(types.FloatID), Directive: protos.SchemaUpdate_INDEX}
 	require.NoError(t, checkSchema(s1))
 
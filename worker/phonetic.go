@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/protos"
+)
+
+// handleSoundsLikeFunction keeps the uids which have, for every word of the
+// text, a word with one of its phonetic codes. The rows of the codes of a word
+// are merged, as a word may be pronounced in different ways.
+func handleSoundsLikeFunction(arg funcArgs) {
+	rows := make(map[string]*protos.List, len(arg.srcFn.tokens))
+	for i, token := range arg.srcFn.tokens {
+		rows[token] = arg.out.UidMatrix[i]
+	}
+	words := make([]*protos.List, 0, len(arg.srcFn.wordTokens))
+	for _, tokens := range arg.srcFn.wordTokens {
+		lists := make([]*protos.List, 0, len(tokens))
+		for _, token := range tokens {
+			lists = append(lists, rows[token])
+		}
+		words = append(words, algo.MergeSorted(lists))
+	}
+	matched := algo.IntersectSorted(words)
+	for i := 0; i < len(arg.out.UidMatrix); i++ {
+		algo.IntersectWith(arg.out.UidMatrix[i], matched, arg.out.UidMatrix[i])
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type phraseTerm struct {
+	token  string
+	offset int // Position relative to the first term of the phrase.
+}
+
+// phraseQuery holds the terms of the phrase and near_words functions.
+type phraseQuery struct {
+	terms []phraseTerm
+	// For near_words, the maximum distance between the positions of the terms.
+	// It is zero for phrase, whose terms must be in the same order and
+	// distances as in the query text.
+	distance int
+}
+
+func parsePhrase(text, attr, lang string, distance int) (*phraseQuery, error) {
+	tokenizer, err := fullTextTokenizer(attr, lang)
+	if err != nil {
+		return nil, err
+	}
+	positions, _, err := tok.TermPositions(tokenizer,
+		types.Val{Tid: types.StringID, Value: text})
+	if err != nil {
+		return nil, err
+	}
+	pq := &phraseQuery{distance: distance}
+	for token, pos := range positions {
+		for _, p := range pos {
+			pq.terms = append(pq.terms, phraseTerm{token: token, offset: p})
+		}
+	}
+	sort.Slice(pq.terms, func(i, j int) bool {
+		return pq.terms[i].offset < pq.terms[j].offset
+	})
+	if len(pq.terms) > 0 {
+		base := pq.terms[0].offset
+		for i := range pq.terms {
+			pq.terms[i].offset -= base
+		}
+	}
+	return pq, nil
+}
+
+// tokens returns the distinct index tokens of the terms.
+func (pq *phraseQuery) tokens() []string {
+	tokens := make([]string, 0, len(pq.terms))
+	for _, t := range pq.terms {
+		tokens = append(tokens, t.token)
+	}
+	return x.RemoveDuplicates(tokens)
+}
+
+// matches returns whether a value with the term positions satisfies the
+// query. Positions must be sorted for every term.
+func (pq *phraseQuery) matches(positions map[string][]int) bool {
+	if len(pq.terms) == 0 {
+		return false
+	}
+	if pq.distance > 0 {
+		return pq.matchesNear(positions)
+	}
+	first := pq.terms[0]
+	for _, start := range positions[first.token] {
+		found := true
+		for _, t := range pq.terms[1:] {
+			pos := positions[t.token]
+			want := start + t.offset
+			if i := sort.SearchInts(pos, want); i == len(pos) || pos[i] != want {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNear looks for the smallest window of positions which has all the
+// terms, and checks that it spans at most the distance of the query.
+func (pq *phraseQuery) matchesNear(positions map[string][]int) bool {
+	type occurrence struct {
+		pos  int
+		term int
+	}
+	tokens := pq.tokens()
+	var occs []occurrence
+	for i, token := range tokens {
+		if len(positions[token]) == 0 {
+			return false
+		}
+		for _, p := range positions[token] {
+			occs = append(occs, occurrence{pos: p, term: i})
+		}
+	}
+	sort.Slice(occs, func(i, j int) bool { return occs[i].pos < occs[j].pos })
+
+	counts := make([]int, len(tokens))
+	covered := 0
+	left := 0
+	for _, o := range occs {
+		if counts[o.term] == 0 {
+			covered++
+		}
+		counts[o.term]++
+		for covered == len(tokens) {
+			if o.pos-occs[left].pos <= pq.distance {
+				return true
+			}
+			counts[occs[left].term]--
+			if counts[occs[left].term] == 0 {
+				covered--
+			}
+			left++
+		}
+	}
+	return false
+}
+
+// handlePhraseFunction keeps the uids whose values have the terms at the
+// positions required by the query, using the positions kept on the postings
+// of the full text index. The values indexed before the positions were kept
+// are tokenized again instead.
+func handlePhraseFunction(ctx context.Context, arg funcArgs) error {
+	if len(arg.out.UidMatrix) == 0 {
+		return nil
+	}
+	candidates := algo.IntersectSorted(arg.out.UidMatrix)
+	positions := make(map[uint64]map[string][]int, len(candidates.Uids))
+	for _, uid := range candidates.Uids {
+		positions[uid] = make(map[string][]int)
+	}
+	// The uids having a posting without positions.
+	unindexed := make(map[uint64]bool)
+
+	for _, token := range arg.srcFn.tokens {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		var err error
+		posting.Get(x.IndexKey(arg.q.Attr, token)).Iterate(0, func(p *protos.Posting) bool {
+			pos, ok := positions[p.Uid]
+			if !ok {
+				return true
+			}
+			found := false
+			for _, f := range p.Facets {
+				if f.Key != tok.PositionsFacet {
+					continue
+				}
+				if pos[token], err = tok.DecodePositions(f.Value); err != nil {
+					return false
+				}
+				found = true
+			}
+			if !found {
+				unindexed[p.Uid] = true
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	filter := stringFilter{
+		attr:   arg.q.Attr,
+		lang:   langForFunc(arg.q.Langs),
+		phrase: arg.srcFn.phrase,
+	}
+	matched := &protos.List{}
+	for _, uid := range candidates.Uids {
+		if unindexed[uid] {
+			if phraseMatchValue(uid, filter) {
+				matched.Uids = append(matched.Uids, uid)
+			}
+			continue
+		}
+		if arg.srcFn.phrase.matches(positions[uid]) {
+			matched.Uids = append(matched.Uids, uid)
+		}
+	}
+	for i := 0; i < len(arg.out.UidMatrix); i++ {
+		algo.IntersectWith(arg.out.UidMatrix[i], matched, arg.out.UidMatrix[i])
+	}
+	return nil
+}
+
+// phraseMatchValue matches the phrase against the value of the uid.
+func phraseMatchValue(uid uint64, filter stringFilter) bool {
+	pl := posting.Get(x.DataKey(filter.attr, uid))
+	var val types.Val
+	var err error
+	if filter.lang == "" {
+		val, err = pl.Value()
+	} else {
+		val, err = pl.ValueForTag(filter.lang)
+	}
+	if err != nil {
+		return false
+	}
+	strVal, err := types.Convert(val, types.StringID)
+	if err != nil {
+		return false
+	}
+	return phraseMatch(strVal, filter)
+}
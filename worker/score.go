@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"math"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/types/facets"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+// Parameters of BM25, with the values commonly used by search engines.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// postingStats returns the values of the dl and tf facets of a posting in the
+// full text index. They are zero if the posting was indexed without them.
+func postingStats(p *protos.Posting) (dl, tf int64) {
+	for _, f := range p.Facets {
+		v, ok := facets.ValFor(f).Value.(int64)
+		if !ok {
+			continue
+		}
+		switch f.Key {
+		case tok.DocLengthFacet:
+			dl = v
+		case tok.TermFreqFacet:
+			tf = v
+		}
+	}
+	return dl, tf
+}
+
+// handleScoreFunction scores the values of attr for the uids against the
+// tokens of the query text, using BM25 on the statistics kept with the full
+// text index. Uids whose value doesn't contain any of the tokens get no score.
+func handleScoreFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	uids := arg.q.UidList.Uids
+
+	// Number of values in the index and their average length.
+	total, sumLength := posting.TextStats(attr)
+	avgLength := 1.0
+	if total > 0 && sumLength > 0 {
+		avgLength = float64(sumLength) / float64(total)
+	}
+
+	scores := make(map[uint64]float64)
+	for _, token := range arg.srcFn.tokens {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		pl := posting.Get(x.IndexKey(attr, token))
+		df := float64(pl.Length(0))
+		if df == 0 {
+			continue
+		}
+		n := math.Max(float64(total), df)
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		// Only the postings of the uids to score are visited.
+		pl.Postings(posting.ListOptions{Intersect: arg.q.UidList}, func(p *protos.Posting) bool {
+			dl, tf := postingStats(p)
+			if tf == 0 {
+				// Indexed before the statistics were kept.
+				tf = 1
+			}
+			length := avgLength
+			if dl > 0 {
+				length = float64(dl)
+			}
+			freq := float64(tf)
+			scores[p.Uid] += idf * freq * (bm25K1 + 1) /
+				(freq + bm25K1*(1-bm25B+bm25B*length/avgLength))
+			return true
+		})
+	}
+
+	for _, uid := range uids {
+		tv := &protos.TaskValue{ValType: int32(types.FloatID)}
+		if score, ok := scores[uid]; ok {
+			data := types.ValueForType(types.BinaryID)
+			if err := types.Marshal(types.Val{Tid: types.FloatID, Value: score}, &data); err != nil {
+				return err
+			}
+			tv.Val = data.Value.([]byte)
+		}
+		arg.out.ValueMatrix = append(arg.out.ValueMatrix,
+			&protos.ValueList{Values: []*protos.TaskValue{tv}})
+		arg.out.UidMatrix = append(arg.out.UidMatrix, &emptyUIDList)
+	}
+	return nil
+}
@@ -292,6 +292,8 @@ func multiSort(ctx context.Context, r *sortresult, ts *protos.SortMessage) error
 
 	// Execute rest of the sorts concurrently.
 	och := make(chan orderResult, len(ts.Order)-1)
+	collators := make([]*types.Collator, len(ts.Order))
+	folds := make([]bool, len(ts.Order))
 	for i := 1; i < len(ts.Order); i++ {
 		in := &protos.Query{
 			Attr:    ts.Order[i].Attr,
@@ -301,7 +303,9 @@ func multiSort(ctx context.Context, r *sortresult, ts *protos.SortMessage) error
 		in.Attr = attrData[0]
 		if len(attrData) == 2 {
 			in.Langs = strings.Split(attrData[1], ":")
+			collators[i] = collatorFor(in.Langs)
 		}
+		folds[i] = collators[i] == nil && foldsCaseForSort(in.Attr)
 		go fetchValues(ctx, in, i, och)
 	}
 
@@ -333,6 +337,11 @@ func multiSort(ctx context.Context, r *sortresult, ts *protos.SortMessage) error
 				if err != nil {
 					return err
 				}
+				if folds[or.idx] {
+					sv = foldedKey(sv)
+				} else {
+					sv = collationKey(collators[or.idx], sv)
+				}
 			}
 			sortVals[i][or.idx] = sv
 		}
@@ -395,6 +404,18 @@ func processSort(ctx context.Context, ts *protos.SortMessage) (*protos.SortResul
 		return nil, x.Errorf("Sorting not supported on attr: %s of type: [scalar]", ts.Order[0].Attr)
 	}
 
+	var r *sortresult
+	if collatorFor(ts.Langs) != nil {
+		// The index is ordered bytewise, so values are sorted directly using
+		// the collation of the language.
+		r = sortWithoutIndex(ctx, ts)
+		if r.err != nil || len(ts.Order) == 1 {
+			return r.reply, r.err
+		}
+		err := multiSort(ctx, r, ts)
+		return r.reply, err
+	}
+
 	cctx, cancel := context.WithCancel(ctx)
 	resCh := make(chan *sortresult, 2)
 	go func() {
@@ -414,7 +435,7 @@ func processSort(ctx context.Context, ts *protos.SortMessage) (*protos.SortResul
 		resCh <- sr
 	}()
 
-	r := <-resCh
+	r = <-resCh
 	if r.err == nil {
 		cancel()
 		// wait for other goroutine to get cancelled
@@ -587,6 +608,8 @@ func paginate(ts *protos.SortMessage, dest *protos.List, vals []types.Val) (int,
 // sortByValue fetches values and sort UIDList.
 func sortByValue(ctx context.Context, ts *protos.SortMessage, ul *protos.List,
 	typ types.TypeID) ([]types.Val, error) {
+	collator := collatorFor(ts.Langs)
+	fold := collator == nil && foldsCaseForSort(ts.Order[0].Attr)
 	lenList := len(ul.Uids)
 	uids := make([]uint64, 0, lenList)
 	values := make([][]types.Val, 0, lenList)
@@ -603,6 +626,11 @@ func sortByValue(ctx context.Context, ts *protos.SortMessage, ul *protos.List,
 				continue
 			}
 			uids = append(uids, uid)
+			if fold {
+				val = foldedKey(val)
+			} else {
+				val = collationKey(collator, val)
+			}
 			values = append(values, []types.Val{val})
 		}
 	}
@@ -616,6 +644,43 @@ func sortByValue(ctx context.Context, ts *protos.SortMessage, ul *protos.List,
 	return multiSortVals, err
 }
 
+// collatorFor returns the collator for the first of the languages asked for.
+func collatorFor(langs []string) *types.Collator {
+	if len(langs) == 0 {
+		return nil
+	}
+	return types.CollatorFor(langs[0])
+}
+
+// collationKey replaces a string value by its sort key, if there is a collator.
+func collationKey(c *types.Collator, val types.Val) types.Val {
+	if c == nil || (val.Tid != types.StringID && val.Tid != types.DefaultID) {
+		return val
+	}
+	val.Value = c.Key(val.Value.(string))
+	return val
+}
+
+// foldsCaseForSort returns true if attr is sorted through a case insensitive
+// index, in which case its values are sorted ignoring their case too.
+func foldsCaseForSort(attr string) bool {
+	for _, t := range schema.State().Tokenizer(attr) {
+		if t.IsSortable() {
+			return tok.FoldsCase(t)
+		}
+	}
+	return false
+}
+
+// foldedKey replaces a string value by a sort key which orders it like the case
+// insensitive index. Values which only differ by case are ordered bytewise.
+func foldedKey(val types.Val) types.Val {
+	if s, ok := val.Value.(string); ok {
+		val.Value = tok.FoldCase(s) + "\x00" + s
+	}
+	return val
+}
+
 // fetchValue gets the value for a given UID.
 func fetchValue(uid uint64, attr string, langs []string, scalar types.TypeID) (types.Val, error) {
 	// Don't put the values in memory
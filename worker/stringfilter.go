@@ -29,13 +29,16 @@ import (
 type matchFn func(types.Val, stringFilter) bool
 
 type stringFilter struct {
-	funcName  string
-	funcType  FuncType
-	lang      string
-	tokens    []string
-	match     matchFn
-	ineqValue types.Val
-	eqVals    []types.Val
+	attr       string
+	funcName   string
+	funcType   FuncType
+	lang       string
+	tokens     []string
+	match      matchFn
+	ineqValue  types.Val
+	eqVals     []types.Val
+	phrase     *phraseQuery
+	ignoreCase bool
 }
 
 func matchStrings(uids *protos.List, values []types.Val, filter stringFilter) *protos.List {
@@ -80,7 +83,43 @@ func defaultMatch(value types.Val, filter stringFilter) bool {
 	}
 }
 
+func phraseMatch(value types.Val, filter stringFilter) bool {
+	tokenizer, err := fullTextTokenizer(filter.attr, filter.lang)
+	// tokenizer was used in previous stages of query proccessing, it has to be available
+	x.AssertTrue(err == nil)
+	positions, _, err := tok.TermPositions(tokenizer, value)
+	if err != nil {
+		return false
+	}
+	return filter.phrase.matches(positions)
+}
+
+// prefixMatch checks that every word of the filter starts some word of the
+// value.
+func prefixMatch(value types.Val, filter stringFilter) bool {
+	words, err := tok.TermWords(value.Value.(string))
+	if err != nil {
+		return false
+	}
+	for _, prefix := range filter.tokens {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, prefix) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func ineqMatch(value types.Val, filter stringFilter) bool {
+	if filter.ignoreCase {
+		value = foldValue(value)
+	}
 	if len(filter.eqVals) == 0 {
 		return types.CompareVals(filter.funcName, value, filter.ineqValue)
 	}
@@ -94,18 +133,13 @@ func ineqMatch(value types.Val, filter stringFilter) bool {
 }
 
 func tokenizeValue(value types.Val, filter stringFilter) []string {
-	var tokName string
-	switch filter.funcType {
-	case StandardFn:
-		tokName = "term"
-	case FullTextSearchFn:
-		tokName = tok.FtsTokenizerName(filter.lang)
+	var tokenizer tok.Tokenizer = tok.TermTokenizer{}
+	if filter.funcType == FullTextSearchFn {
+		var err error
+		tokenizer, err = fullTextTokenizer(filter.attr, filter.lang)
+		// tokenizer was used in previous stages of query proccessing, it has to be available
+		x.AssertTrue(err == nil)
 	}
-
-	tokenizer, found := tok.GetTokenizer(tokName)
-
-	// tokenizer was used in previous stages of query proccessing, it has to be available
-	x.AssertTrue(found)
 	tokens, err := tokenizer.Tokens(value)
 	if err == nil {
 		return tokens
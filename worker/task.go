@@ -44,7 +44,6 @@ import (
 	"github.com/dgraph-io/dgraph/types/facets"
 	"github.com/dgraph-io/dgraph/x"
 
-	cindex "github.com/google/codesearch/index"
 	cregexp "github.com/google/codesearch/regexp"
 )
 
@@ -222,6 +221,16 @@ const (
 	FullTextSearchFn
 	HasFn
 	UidInFn
+	DistinctFn
+	ScoreFn
+	PhraseFn
+	PrefixFn
+	MatchFn
+	ContainsFn
+	SoundsLikeFn
+	SimilarToFn
+	CompositeFn
+	KnnFn
 	StandardFn = 100
 )
 
@@ -248,7 +257,8 @@ func parseFuncTypeHelper(name string) (FuncType, string) {
 	switch f {
 	case "le", "ge", "lt", "gt", "eq":
 		return CompareAttrFn, f
-	case "min", "max", "sum", "avg":
+	case "min", "max", "sum", "avg", "median", "percentile", "stddev", "variance",
+		"count_distinct", "approx_count_distinct":
 		return AggregatorFn, f
 	case "checkpwd":
 		return PasswordFn, f
@@ -260,6 +270,26 @@ func parseFuncTypeHelper(name string) (FuncType, string) {
 		return HasFn, f
 	case "uid_in":
 		return UidInFn, f
+	case "distinct":
+		return DistinctFn, f
+	case "bm25":
+		return ScoreFn, f
+	case "phrase", "near_words":
+		return PhraseFn, f
+	case "prefix":
+		return PrefixFn, f
+	case "match":
+		return MatchFn, f
+	case "icontains":
+		return ContainsFn, f
+	case "sounds_like":
+		return SoundsLikeFn, f
+	case "similar_to":
+		return SimilarToFn, f
+	case "composite":
+		return CompositeFn, f
+	case "knn":
+		return KnnFn, f
 	default:
 		if types.IsGeoFunc(f) {
 			return GeoFn, f
@@ -270,7 +300,8 @@ func parseFuncTypeHelper(name string) (FuncType, string) {
 
 func needsIndex(fnType FuncType) bool {
 	switch fnType {
-	case CompareAttrFn, GeoFn, RegexFn, FullTextSearchFn, StandardFn:
+	case CompareAttrFn, GeoFn, RegexFn, FullTextSearchFn, StandardFn, DistinctFn, ScoreFn,
+		PhraseFn, PrefixFn, MatchFn, ContainsFn, SoundsLikeFn, SimilarToFn, CompositeFn, KnnFn:
 		return true
 	default:
 		return false
@@ -299,7 +330,8 @@ func (srcFn *functionContext) needsValuePostings(typ types.TypeID) (bool, error)
 			return false, nil
 		}
 		return true, nil
-	case GeoFn, RegexFn, FullTextSearchFn, StandardFn, HasFn:
+	case GeoFn, RegexFn, FullTextSearchFn, StandardFn, HasFn, PhraseFn, PrefixFn, MatchFn,
+		ContainsFn, SoundsLikeFn, SimilarToFn, CompositeFn, KnnFn:
 		// All of these require index, hence would require fetching uid postings.
 		return false, nil
 	case UidInFn, CompareScalarFn:
@@ -455,7 +487,8 @@ func handleUidPostings(ctx context.Context, args funcArgs, opts posting.ListOpti
 			} else {
 				key = x.DataKey(attr, q.UidList.Uids[i])
 			}
-		case GeoFn, RegexFn, FullTextSearchFn, StandardFn:
+		case GeoFn, RegexFn, FullTextSearchFn, StandardFn, PhraseFn, PrefixFn, MatchFn,
+			SoundsLikeFn, CompositeFn:
 			key = x.IndexKey(attr, srcFn.tokens[i])
 		case CompareAttrFn:
 			key = x.IndexKey(attr, srcFn.tokens[i])
@@ -604,6 +637,19 @@ func helpProcessTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.
 	}
 	srcFn.atype = typ
 
+	if srcFn.fnType == DistinctFn {
+		if err := handleDistinctFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	if srcFn.fnType == ScoreFn {
+		if err := handleScoreFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
 	opts := posting.ListOptions{
 		AfterUID: uint64(q.AfterUid),
 	}
@@ -628,7 +674,13 @@ func helpProcessTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.
 	}
 
 	if srcFn.fnType == HasFn && srcFn.isFuncAtRoot {
-		if err := handleHasFunction(funcArgs{q, gid, srcFn, out}); err != nil {
+		if q.FacetsFilter != nil && !q.Reverse {
+			// Find the nodes with edges having the facets, using the facet
+			// indexes if possible.
+			if err := handleHasFacetsFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+				return nil, err
+			}
+		} else if err := handleHasFunction(funcArgs{q, gid, srcFn, out}); err != nil {
 			return nil, err
 		}
 	}
@@ -647,6 +699,46 @@ func helpProcessTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.
 		}
 	}
 
+	if srcFn.fnType == PhraseFn {
+		// Check the positions of the terms in the values having all of them.
+		if err := handlePhraseFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+	}
+
+	if srcFn.fnType == SoundsLikeFn {
+		// Keep the uids having a word which sounds like every word of the text.
+		handleSoundsLikeFunction(funcArgs{q, gid, srcFn, out})
+	}
+
+	if srcFn.fnType == ContainsFn {
+		// Look up the trigrams of the substring and check the values found.
+		if err := handleContainsFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+	}
+
+	if srcFn.fnType == SimilarToFn {
+		// Compare the vectors found in the index.
+		if err := handleSimilarToFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+	}
+
+	if srcFn.fnType == KnnFn {
+		// Look up the geometries around the point until the nearest ones are found.
+		if err := handleKnnFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+	}
+
+	if srcFn.fnType == MatchFn {
+		// Compute the distance of the values having enough trigrams in common.
+		if err := handleMatchFunction(ctx, funcArgs{q, gid, srcFn, out}); err != nil {
+			return nil, err
+		}
+	}
+
 	// We fetch the actual value for the uids, compare them to the value in the
 	// request and filter the uids only if the tokenizer IsLossy.
 	if srcFn.fnType == CompareAttrFn && len(srcFn.tokens) > 0 {
@@ -672,7 +764,9 @@ func helpProcessTask(ctx context.Context, q *protos.Query, gid uint32) (*protos.
 func needsStringFiltering(srcFn *functionContext, langs []string) bool {
 	return srcFn.isStringFn && langForFunc(langs) != "." &&
 		(srcFn.fnType == StandardFn || srcFn.fnType == HasFn ||
-			srcFn.fnType == FullTextSearchFn || srcFn.fnType == CompareAttrFn)
+			srcFn.fnType == FullTextSearchFn || srcFn.fnType == CompareAttrFn ||
+			srcFn.fnType == PhraseFn || srcFn.fnType == PrefixFn ||
+			srcFn.fnType == SoundsLikeFn)
 }
 
 func handleHasFunction(arg funcArgs) error {
@@ -719,20 +813,14 @@ func handleRegexFunction(ctx context.Context, arg funcArgs) error {
 	if typ != types.StringID {
 		return x.Errorf("Got non-string type. Regex match is allowed only on string type.")
 	}
-	tokenizers := schema.State().TokenizerNames(attr)
-	var found bool
-	for _, t := range tokenizers {
-		if t == "trigram" { // TODO(tzdybal) - maybe just rename to 'regex' tokenizer?
-			found = true
-		}
-	}
+	tokenizer, found := regexTokenizer(attr, arg.srcFn.ignoreCase)
 	if !found {
 		return x.Errorf("Attribute %v does not have trigram index for regex matching.", attr)
 	}
 
-	query := cindex.RegexpQuery(arg.srcFn.regex.Syntax)
+	query := trigramQuery(arg.srcFn.regex.Syntax, tokenizer)
 	empty := protos.List{}
-	uids, err := uidsForRegex(attr, arg.gid, query, &empty)
+	uids, err := uidsForRegex(attr, arg.gid, tokenizer.Identifier(), query, &empty)
 	lang := langForFunc(arg.q.Langs)
 	if uids != nil {
 		arg.out.UidMatrix = append(arg.out.UidMatrix, uids)
@@ -800,6 +888,12 @@ func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 			rowsToFilter = 1
 		}
 		lang := langForFunc(arg.q.Langs)
+		compare := func(sv types.Val, row int) bool {
+			if arg.srcFn.ignoreCase {
+				sv = foldValue(sv)
+			}
+			return types.CompareVals(arg.q.SrcFunc.Name, sv, arg.srcFn.eqTokens[row])
+		}
 		for row := 0; row < rowsToFilter; row++ {
 			select {
 			case <-ctx.Done():
@@ -813,8 +907,7 @@ func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 					sv, err := pl.Value()
 					if err == nil {
 						dst, err := types.Convert(sv, typ)
-						return err == nil &&
-							types.CompareVals(arg.q.SrcFunc.Name, dst, arg.srcFn.eqTokens[row])
+						return err == nil && compare(dst, row)
 					}
 					return false
 				case ".":
@@ -822,8 +915,7 @@ func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 					values, _ := pl.AllValues()
 					for _, sv := range values {
 						dst, err := types.Convert(sv, typ)
-						if err == nil &&
-							types.CompareVals(arg.q.SrcFunc.Name, dst, arg.srcFn.eqTokens[row]) {
+						if err == nil && compare(dst, row) {
 							return true
 						}
 					}
@@ -833,7 +925,7 @@ func handleCompareFunction(ctx context.Context, arg funcArgs) error {
 					if sv.Value == nil || err != nil {
 						return false
 					}
-					return types.CompareVals(arg.q.SrcFunc.Name, sv, arg.srcFn.eqTokens[row])
+					return compare(sv, row)
 				}
 			})
 		}
@@ -895,22 +987,34 @@ func filterStringFunction(arg funcArgs) {
 
 	filtered := &protos.List{Uids: filteredUids}
 	filter := stringFilter{
+		attr:     attr,
 		funcName: arg.srcFn.fname,
 		funcType: arg.srcFn.fnType,
 		lang:     lang,
 	}
 
 	switch arg.srcFn.fnType {
-	case HasFn:
+	case HasFn, SoundsLikeFn:
 		// Dont do anything, as filtering based on lang is already
 		// done above.
 	case FullTextSearchFn, StandardFn:
 		filter.tokens = arg.srcFn.tokens
 		filter.match = defaultMatch
 		filtered = matchStrings(filtered, values, filter)
+	case PhraseFn:
+		filter.phrase = arg.srcFn.phrase
+		filter.match = phraseMatch
+		filtered = matchStrings(filtered, values, filter)
+	case PrefixFn:
+		// The index only has prefixes up to a maximum length, so the whole
+		// words need to be checked.
+		filter.tokens, _ = tok.TermWords(arg.q.SrcFunc.Args[0])
+		filter.match = prefixMatch
+		filtered = matchStrings(filtered, values, filter)
 	case CompareAttrFn:
 		filter.ineqValue = arg.srcFn.ineqValue
 		filter.eqVals = arg.srcFn.eqTokens
+		filter.ignoreCase = arg.srcFn.ignoreCase
 		filter.match = ineqMatch
 		filtered = matchStrings(uids, values, filter)
 	}
@@ -951,6 +1055,19 @@ type functionContext struct {
 	isFuncAtRoot   bool
 	isStringFn     bool
 	atype          types.TypeID
+	distinctPrefix string
+	offset         int
+	count          int
+	phrase         *phraseQuery
+	matchText      string
+	maxDistance    int
+	substr         string
+	ignoreCase     bool
+	wordTokens     [][]string
+	vector         []float32
+	vectorIndex    tok.VectorTokenizer
+	k              int
+	knnQuery       *types.KnnQuery
 }
 
 const (
@@ -994,6 +1111,13 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 	if err == nil && fnType != NotAFunction && t.Name() == types.StringID.Name() {
 		fc.isStringFn = true
 	}
+	if fnType == GeoFn && f == "contains" && fc.isStringFn {
+		// contains is overloaded on the type of the predicate only. On geo
+		// predicates it finds the shapes containing the given geometry, and on
+		// strings it looks for the argument as a substring.
+		fnType = ContainsFn
+		fc.fnType = fnType
+	}
 
 	switch fnType {
 	case NotAFunction:
@@ -1043,6 +1167,16 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 
 		}
 
+		// The values are compared ignoring their case if they are looked up
+		// through a case insensitive index.
+		if tokenizer, err := pickTokenizer(attr, f); err == nil && tok.FoldsCase(tokenizer) {
+			fc.ignoreCase = true
+			fc.ineqValue = foldValue(fc.ineqValue)
+			for i := range fc.eqTokens {
+				fc.eqTokens[i] = foldValue(fc.eqTokens[i])
+			}
+		}
+
 		// Number of index keys is more than no. of uids to filter, so its better to fetch data keys
 		// directly and compare. Lets make tokens empty.
 		// We don't do this for eq because eq could have multiple arguments and we would have to
@@ -1086,7 +1220,7 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 		if !found {
 			return nil, x.Errorf("Attribute %s is not indexed with type %s", attr, required)
 		}
-		if fc.tokens, err = getStringTokens(q.SrcFunc.Args, langForFunc(q.Langs), fnType); err != nil {
+		if fc.tokens, err = getStringTokens(q.SrcFunc.Args, attr, langForFunc(q.Langs), fnType); err != nil {
 			return nil, err
 		}
 		fnName := strings.ToLower(q.SrcFunc.Name)
@@ -1106,6 +1240,7 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 			}
 		}
 		matchType := "(?m)" // this is cregexp library specific
+		fc.ignoreCase = ignoreCase
 		if ignoreCase {
 			matchType = "(?i)" + matchType
 		}
@@ -1129,6 +1264,190 @@ func parseSrcFn(q *protos.Query) (*functionContext, error) {
 		if fc.isFuncAtRoot {
 			return nil, x.Errorf("uid_in function not allowed at root")
 		}
+	case DistinctFn:
+		// The query layer passes the prefix and the pagination as arguments.
+		if err = ensureArgsCount(q.SrcFunc, 3); err != nil {
+			return nil, err
+		}
+		checkRoot(q, fc)
+		if !fc.isFuncAtRoot {
+			return nil, x.Errorf("distinct function only allowed at root")
+		}
+		fc.distinctPrefix = q.SrcFunc.Args[0]
+		if fc.offset, err = strconv.Atoi(q.SrcFunc.Args[1]); err != nil {
+			return nil, err
+		}
+		if fc.count, err = strconv.Atoi(q.SrcFunc.Args[2]); err != nil {
+			return nil, err
+		}
+		if fc.offset < 0 || fc.count < 0 {
+			return nil, x.Errorf("distinct function expects non-negative offset and count")
+		}
+	case ScoreFn:
+		if err = ensureArgsCount(q.SrcFunc, 1); err != nil {
+			return nil, err
+		}
+		required, found := verifyStringIndex(attr, FullTextSearchFn)
+		if !found {
+			return nil, x.Errorf("Attribute %s is not indexed with type %s", attr, required)
+		}
+		if fc.tokens, err = getStringTokens(q.SrcFunc.Args, attr, langForFunc(q.Langs),
+			FullTextSearchFn); err != nil {
+			return nil, err
+		}
+		checkRoot(q, fc)
+		if fc.isFuncAtRoot {
+			return nil, x.Errorf("bm25 function not allowed at root")
+		}
+	case PhraseFn:
+		// phrase takes the text, near_words the text and the distance.
+		var distance int
+		if f == "near_words" {
+			if err = ensureArgsCount(q.SrcFunc, 2); err != nil {
+				return nil, err
+			}
+			if distance, err = strconv.Atoi(q.SrcFunc.Args[1]); err != nil {
+				return nil, x.Wrapf(err, "near_words expects a number of words as distance")
+			}
+			if distance <= 0 {
+				return nil, x.Errorf("near_words expects a positive distance. Got: %d", distance)
+			}
+		} else if err = ensureArgsCount(q.SrcFunc, 1); err != nil {
+			return nil, err
+		}
+		required, found := verifyStringIndex(attr, FullTextSearchFn)
+		if !found {
+			return nil, x.Errorf("Attribute %s is not indexed with type %s", attr, required)
+		}
+		if fc.phrase, err = parsePhrase(q.SrcFunc.Args[0], attr, langForFunc(q.Langs),
+			distance); err != nil {
+			return nil, err
+		}
+		fc.tokens = fc.phrase.tokens()
+		fc.intersectDest = true
+		fc.n = len(fc.tokens)
+	case PrefixFn:
+		if err = ensureArgsCount(q.SrcFunc, 1); err != nil {
+			return nil, err
+		}
+		tokenizer, found := edgeNgramTokenizer(attr)
+		if !found {
+			return nil, x.Errorf("Attribute %s is not indexed with type edgengram", attr)
+		}
+		if fc.tokens, err = tokenizer.PrefixTokens(q.SrcFunc.Args[0]); err != nil {
+			return nil, err
+		}
+		fc.intersectDest = true
+		fc.n = len(fc.tokens)
+	case ContainsFn:
+		if err = ensureArgsCount(q.SrcFunc, 1); err != nil {
+			return nil, err
+		}
+		fc.substr = q.SrcFunc.Args[0]
+		fc.ignoreCase = f == "icontains"
+		fc.n = 0
+	case SoundsLikeFn:
+		if err = ensureArgsCount(q.SrcFunc, 1); err != nil {
+			return nil, err
+		}
+		tokenizer, found := phoneticTokenizer(attr)
+		if !found {
+			return nil, x.Errorf("Attribute %s is not indexed with type phonetic or soundex", attr)
+		}
+		var words []string
+		if words, err = tok.TermWords(q.SrcFunc.Args[0]); err != nil {
+			return nil, err
+		}
+		for _, word := range words {
+			if tokens := tokenizer.WordTokens(word); len(tokens) > 0 {
+				fc.wordTokens = append(fc.wordTokens, tokens)
+				fc.tokens = append(fc.tokens, tokens...)
+			}
+		}
+		if len(fc.wordTokens) == 0 {
+			return nil, x.Errorf("sounds_like needs a text with at least one word. Got: %q",
+				q.SrcFunc.Args[0])
+		}
+		fc.tokens = x.RemoveDuplicates(fc.tokens)
+		fc.n = len(fc.tokens)
+	case SimilarToFn:
+		if err = ensureArgsCount(q.SrcFunc, 2); err != nil {
+			return nil, err
+		}
+		var found bool
+		if fc.vectorIndex, found = vectorTokenizer(attr); !found {
+			return nil, x.Errorf("Attribute %s is not indexed with type vector", attr)
+		}
+		if fc.k, err = strconv.Atoi(q.SrcFunc.Args[0]); err != nil {
+			return nil, x.Wrapf(err, "similar_to expects a number of neighbors")
+		}
+		if fc.k <= 0 {
+			return nil, x.Errorf("similar_to expects a positive number of neighbors. Got: %d",
+				fc.k)
+		}
+		if fc.vector, err = types.ParseVector(q.SrcFunc.Args[1]); err != nil {
+			return nil, err
+		}
+		fc.n = 0
+	case KnnFn:
+		if !hasGeoIndex(attr) {
+			return nil, x.Errorf("Attribute %s is not indexed with type geo", attr)
+		}
+		if fc.knnQuery, err = types.NewKnnQuery(q.SrcFunc); err != nil {
+			return nil, err
+		}
+		fc.n = 0
+	case CompositeFn:
+		// The eq functions on the predicates of a composite index of attr, which
+		// are given along with their values.
+		args := q.SrcFunc.Args
+		if len(args) < 4 || len(args)%2 != 0 {
+			return nil, x.Errorf("Invalid arguments for composite index of %s: %v", attr, args)
+		}
+		var preds []string
+		var vals [][]types.Val
+		for i := 0; i < len(args); i += 2 {
+			v, err := convertValue(args[i], args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			preds = append(preds, args[i])
+			vals = append(vals, []types.Val{v})
+		}
+		ci, found := compositeIndex(attr, preds)
+		if !found {
+			return nil, x.Errorf("Attribute %s doesn't have composite index with %v", attr,
+				preds[1:])
+		}
+		if fc.tokens, err = ci.Tokens(vals); err != nil {
+			return nil, err
+		}
+		fc.n = len(fc.tokens)
+	case MatchFn:
+		if err = ensureArgsCount(q.SrcFunc, 2); err != nil {
+			return nil, err
+		}
+		if !hasTrigramIndex(attr) {
+			return nil, x.Errorf("Attribute %s does not have trigram index for fuzzy matching.",
+				attr)
+		}
+		fc.matchText = q.SrcFunc.Args[0]
+		if fc.maxDistance, err = strconv.Atoi(q.SrcFunc.Args[1]); err != nil {
+			return nil, x.Wrapf(err, "match expects a number as maximum distance")
+		}
+		if fc.maxDistance < 0 {
+			return nil, x.Errorf("match expects a non-negative maximum distance. Got: %d",
+				fc.maxDistance)
+		}
+		if fc.tokens, err = (tok.TrigramTokenizer{}).Tokens(types.Val{
+			Tid: types.StringID, Value: fc.matchText}); err != nil {
+			return nil, err
+		}
+		if len(fc.tokens) == 0 {
+			return nil, x.Errorf("match needs a text of at least 3 characters, as it only "+
+				"finds the values having a trigram in common with it. Got: %q", fc.matchText)
+		}
+		fc.n = len(fc.tokens)
 	default:
 		return nil, x.Errorf("FnType %d not handled in numFnAttrs.", fnType)
 	}
@@ -52,15 +52,133 @@ func verifyStringIndex(attr string, funcType FuncType) (string, bool) {
 	return requiredTokenizer, false
 }
 
-// Return string tokens from function arguments. It maps function type to correct tokenizer.
-// Note: regexp functions require regexp compilation of argument, not tokenization.
-func getStringTokens(funcArgs []string, lang string, funcType FuncType) ([]string, error) {
+// hasGeoIndex returns whether attr is indexed with the geo tokenizer.
+func hasGeoIndex(attr string) bool {
+	for _, t := range schema.State().TokenizerNames(attr) {
+		if t == "geo" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTrigramIndex returns whether attr is indexed with the trigram tokenizer.
+func hasTrigramIndex(attr string) bool {
+	for _, t := range schema.State().TokenizerNames(attr) {
+		if t == "trigram" {
+			return true
+		}
+	}
+	return false
+}
+
+// regexTokenizer returns the trigram index used to match regular expressions
+// and substrings of attr. The case insensitive index is preferred to ignore
+// the case, and the other one to match it.
+func regexTokenizer(attr string, ignoreCase bool) (tok.Tokenizer, bool) {
+	var found tok.Tokenizer
+	for _, t := range schema.State().Tokenizer(attr) {
+		switch t.(type) {
+		case tok.TrigramTokenizer, tok.FoldedTrigramTokenizer:
+			if tok.FoldsCase(t) == ignoreCase {
+				return t, true
+			}
+			found = t
+		}
+	}
+	return found, found != nil
+}
+
+// foldValue folds the case of a string value, to compare it with the values
+// of an attribute looked up through a case insensitive index.
+func foldValue(v types.Val) types.Val {
+	if s, ok := v.Value.(string); ok {
+		v.Value = tok.FoldCase(s)
+	}
+	return v
+}
+
+// edgeNgramTokenizer returns the edge n-gram tokenizer attr is indexed with.
+func edgeNgramTokenizer(attr string) (tok.EdgeNgramTokenizer, bool) {
+	for _, t := range schema.State().Tokenizer(attr) {
+		if et, ok := t.(tok.EdgeNgramTokenizer); ok {
+			return et, true
+		}
+	}
+	return tok.EdgeNgramTokenizer{}, false
+}
+
+// compositeIndex returns the composite index of attr on the predicates.
+func compositeIndex(attr string, preds []string) (tok.CompositeIndex, bool) {
+outer:
+	for _, ci := range schema.State().CompositeIndexes(attr) {
+		if len(ci.Preds) != len(preds) {
+			continue
+		}
+		for i, pred := range ci.Preds {
+			if pred != preds[i] {
+				continue outer
+			}
+		}
+		return ci, true
+	}
+	return tok.CompositeIndex{}, false
+}
+
+// vectorTokenizer returns the vector index of attr.
+func vectorTokenizer(attr string) (tok.VectorTokenizer, bool) {
+	for _, t := range schema.State().Tokenizer(attr) {
+		if vt, ok := t.(tok.VectorTokenizer); ok {
+			return vt, true
+		}
+	}
+	return tok.VectorTokenizer{}, false
+}
+
+// phoneticTokenizer returns the tokenizer used by sounds_like for attr. Double
+// Metaphone is preferred to Soundex if attr is indexed with both.
+func phoneticTokenizer(attr string) (tok.PhoneticTokenizer, bool) {
+	var found tok.PhoneticTokenizer
+	for _, t := range schema.State().Tokenizer(attr) {
+		switch pt := t.(type) {
+		case tok.MetaphoneTokenizer:
+			return pt, true
+		case tok.SoundexTokenizer:
+			found = pt
+		}
+	}
+	return found, found != nil
+}
+
+// fullTextTokenizer returns the full text tokenizer of attr for the language,
+// with the analyzer configured in the schema for attr.
+func fullTextTokenizer(attr, lang string) (tok.FullTextTokenizer, error) {
 	if lang == "." {
 		lang = "en"
 	}
+	var ft tok.FullTextTokenizer
+	for _, t := range schema.State().Tokenizer(attr) {
+		if it, ok := t.(tok.FullTextTokenizer); ok {
+			ft = it
+			break
+		}
+	}
+	return ft.ForLang(lang)
+}
+
+// Return string tokens from function arguments. It maps function type to correct tokenizer.
+// Note: regexp functions require regexp compilation of argument, not tokenization.
+func getStringTokens(funcArgs []string, attr, lang string, funcType FuncType) ([]string, error) {
 	switch funcType {
 	case FullTextSearchFn:
-		return tok.GetTextTokens(funcArgs, lang)
+		if len(funcArgs) != 1 {
+			return nil, x.Errorf("Function requires 1 arguments, but got %d", len(funcArgs))
+		}
+		tokenizer, err := fullTextTokenizer(attr, lang)
+		if err != nil {
+			return nil, err
+		}
+		return tokenizer.Tokens(types.Val{Tid: types.StringID, Value: funcArgs[0]})
 	default:
 		return tok.GetTokens(funcArgs)
 	}
@@ -73,6 +191,9 @@ func pickTokenizer(attr string, f string) (tok.Tokenizer, error) {
 	}
 
 	tokenizers := schema.State().Tokenizer(attr)
+	if len(tokenizers) == 0 {
+		return nil, x.Errorf("Attribute %s only has composite or facet indexes.", attr)
+	}
 
 	var tokenizer tok.Tokenizer
 	for _, t := range tokenizers {
@@ -88,6 +209,19 @@ func pickTokenizer(attr string, f string) (tok.Tokenizer, error) {
 		return tokenizer, nil
 	}
 
+	// Otherwise eq looks up a single token of a string. The indexes which keep
+	// the case of the values are preferred, as the case is ignored with the
+	// other ones.
+	if f == "eq" {
+		for _, name := range []string{"hash", "iexact", "ihash"} {
+			for _, t := range tokenizers {
+				if t.Name() == name {
+					return t, nil
+				}
+			}
+		}
+	}
+
 	// Lets try to find a sortable tokenizer.
 	for _, t := range tokenizers {
 		if t.IsSortable() {
@@ -144,3 +278,10 @@ func getInequalityTokens(attr, f string, ineqValue types.Val) ([]string, string,
 	}
 	return out, ineqToken, nil
 }
+
+// EqFoldsCase returns true if eq ignores the case of the values of attr, as it
+// is looked up in a case insensitive index.
+func EqFoldsCase(attr string) bool {
+	t, err := pickTokenizer(attr, "eq")
+	return err == nil && tok.FoldsCase(t)
+}
@@ -19,6 +19,8 @@ package worker
 
 import (
 	"errors"
+	"regexp/syntax"
+	"sort"
 
 	cindex "github.com/google/codesearch/index"
 
@@ -33,7 +35,7 @@ const maxUidsForTrigram = 1000000
 
 var regexTooWideErr = errors.New("Regular expression is too wide-ranging and can't be executed efficiently.")
 
-func uidsForRegex(attr string, gid uint32,
+func uidsForRegex(attr string, gid uint32, id byte,
 	query *cindex.Query, intersect *protos.List) (*protos.List, error) {
 	var results *protos.List
 	opts := posting.ListOptions{}
@@ -49,7 +51,7 @@ func uidsForRegex(attr string, gid uint32,
 
 	switch query.Op {
 	case cindex.QAnd:
-		tok.EncodeRegexTokens(query.Trigram)
+		tok.EncodeTokens(query.Trigram, id)
 		for _, t := range query.Trigram {
 			trigramUids := uidsForTrigram(t)
 			if results == nil {
@@ -70,7 +72,7 @@ func uidsForRegex(attr string, gid uint32,
 			}
 			// current list of result is passed for intersection
 			var err error
-			results, err = uidsForRegex(attr, gid, sub, results)
+			results, err = uidsForRegex(attr, gid, id, sub, results)
 			if err != nil {
 				return nil, err
 			}
@@ -81,7 +83,7 @@ func uidsForRegex(attr string, gid uint32,
 			}
 		}
 	case cindex.QOr:
-		tok.EncodeRegexTokens(query.Trigram)
+		tok.EncodeTokens(query.Trigram, id)
 		uidMatrix := make([]*protos.List, len(query.Trigram))
 		for i, t := range query.Trigram {
 			uidMatrix[i] = uidsForTrigram(t)
@@ -91,7 +93,7 @@ func uidsForRegex(attr string, gid uint32,
 			if results == nil {
 				results = intersect
 			}
-			subUids, err := uidsForRegex(attr, gid, sub, intersect)
+			subUids, err := uidsForRegex(attr, gid, id, sub, intersect)
 			if err != nil {
 				return nil, err
 			}
@@ -105,3 +107,68 @@ func uidsForRegex(attr string, gid uint32,
 	}
 	return results, nil
 }
+
+// maxFoldedClass is the number of characters of the largest class folded
+// character by character. Larger classes are taken as any character.
+const maxFoldedClass = 256
+
+// trigramQuery returns the trigrams to look up in the index of tokenizer t for
+// the regular expression. The values are folded in a case insensitive index,
+// so the expression is folded too.
+func trigramQuery(re *syntax.Regexp, t tok.Tokenizer) *cindex.Query {
+	if tok.FoldsCase(t) {
+		re = foldRegexp(re)
+	}
+	return cindex.RegexpQuery(re)
+}
+
+// foldRegexp returns a copy of re which matches the folded case of the strings
+// matched by re, and possibly more.
+func foldRegexp(re *syntax.Regexp) *syntax.Regexp {
+	folded := *re
+	folded.Flags &^= syntax.FoldCase
+	switch re.Op {
+	case syntax.OpLiteral:
+		folded.Rune = make([]rune, len(re.Rune))
+		for i, r := range re.Rune {
+			folded.Rune[i] = tok.FoldRune(r)
+		}
+	case syntax.OpCharClass:
+		if folded.Rune = foldClass(re.Rune); folded.Rune == nil {
+			folded.Op = syntax.OpAnyChar
+		}
+	}
+	folded.Sub = make([]*syntax.Regexp, len(re.Sub))
+	for i, sub := range re.Sub {
+		folded.Sub[i] = foldRegexp(sub)
+	}
+	return &folded
+}
+
+// foldClass returns the ranges of the folded characters of a class, or nil if
+// the class is too large.
+func foldClass(ranges []rune) []rune {
+	var n int
+	for i := 0; i < len(ranges); i += 2 {
+		n += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if n > maxFoldedClass {
+		return nil
+	}
+	runes := make([]rune, 0, n)
+	for i := 0; i < len(ranges); i += 2 {
+		for r := ranges[i]; r <= ranges[i+1]; r++ {
+			runes = append(runes, tok.FoldRune(r))
+		}
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	folded := make([]rune, 0, 2*len(runes))
+	for _, r := range runes {
+		if last := len(folded) - 1; last > 0 && r <= folded[last]+1 {
+			folded[last] = r
+			continue
+		}
+		folded = append(folded, r, r)
+	}
+	return folded
+}
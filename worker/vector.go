@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2017 Dgraph Labs, Inc. and Contributors
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package worker
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/dgraph-io/dgraph/algo"
+	"github.com/dgraph-io/dgraph/posting"
+	"github.com/dgraph-io/dgraph/protos"
+	"github.com/dgraph-io/dgraph/types"
+	"github.com/dgraph-io/dgraph/x"
+)
+
+type neighbor struct {
+	uid  uint64
+	dist float64
+}
+
+// handleSimilarToFunction finds the k nearest neighbors of the vector. They
+// are looked for among the uids in the buckets of the vector and the buckets
+// next to them, or among the uids to filter. As the search is approximate,
+// fewer than k uids may be found. The uids are returned in a single list,
+// along with their distances.
+func handleSimilarToFunction(ctx context.Context, arg funcArgs) error {
+	attr := arg.q.Attr
+	srcFn := arg.srcFn
+	tokenizer := srcFn.vectorIndex
+	candidates := arg.q.UidList
+	if candidates == nil {
+		candidates = uidsForTokens(attr, tokenizer.ProbeTokens(srcFn.vector))
+	}
+
+	neighbors := make([]neighbor, 0, len(candidates.Uids))
+	for _, uid := range candidates.Uids {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		val, err := posting.Get(x.DataKey(attr, uid)).Value()
+		if err != nil {
+			continue
+		}
+		vec, err := types.Convert(val, types.Float32VectorID)
+		if err != nil || len(vec.Value.([]float32)) != len(srcFn.vector) {
+			continue
+		}
+		neighbors = append(neighbors, neighbor{
+			uid:  uid,
+			dist: tokenizer.Distance(vec.Value.([]float32), srcFn.vector),
+		})
+	}
+	return nearestNeighbors(neighbors, srcFn.k, arg.out)
+}
+
+// nearestNeighbors returns the k nearest neighbors in uid order, along with
+// their distances.
+func nearestNeighbors(neighbors []neighbor, k int, out *protos.Result) error {
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].dist != neighbors[j].dist {
+			return neighbors[i].dist < neighbors[j].dist
+		}
+		return neighbors[i].uid < neighbors[j].uid
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].uid < neighbors[j].uid })
+
+	found := &protos.List{}
+	distances := &protos.ValueList{}
+	for _, n := range neighbors {
+		data := types.ValueForType(types.BinaryID)
+		if err := types.Marshal(types.Val{Tid: types.FloatID, Value: n.dist}, &data); err != nil {
+			return err
+		}
+		found.Uids = append(found.Uids, n.uid)
+		distances.Values = append(distances.Values, &protos.TaskValue{
+			Val:     data.Value.([]byte),
+			ValType: int32(types.FloatID),
+		})
+	}
+	out.UidMatrix = []*protos.List{found}
+	out.ValueMatrix = []*protos.ValueList{distances}
+	return nil
+}
+
+// uidsForTokens returns the uids having any of the tokens.
+func uidsForTokens(attr string, tokens []string) *protos.List {
+	lists := make([]*protos.List, 0, len(tokens))
+	for _, token := range tokens {
+		lists = append(lists, posting.Get(x.IndexKey(attr, token)).Uids(posting.ListOptions{}))
+	}
+	return algo.MergeSorted(lists)
+}
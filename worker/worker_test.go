@@ -30,6 +30,8 @@ import (
 	"github.com/dgraph-io/dgraph/posting"
 	"github.com/dgraph-io/dgraph/protos"
 	"github.com/dgraph-io/dgraph/schema"
+	"github.com/dgraph-io/dgraph/tok"
+	"github.com/dgraph-io/dgraph/types"
 	"github.com/dgraph-io/dgraph/x"
 )
 
@@ -313,6 +315,53 @@ func TestProcessTaskIndex(t *testing.T) {
 	}, algo.ToUintsListForTest(r.UidMatrix))
 }
 
+// The values indexed before the positions were kept on the postings of the
+// full text index are checked for phrases too.
+func TestProcessTaskPhraseWithoutPositions(t *testing.T) {
+	dir, ps := initTest(t, `friend:string @index(fulltext) .`)
+	defer os.RemoveAll(dir)
+	defer ps.Close()
+	defer posting.Cleanup()
+
+	addEdge(t, &protos.DirectedEdge{
+		Value:  []byte("red shoes"),
+		Label:  "author0",
+		Attr:   "friend",
+		Entity: 14,
+	}, getOrCreate(x.DataKey("friend", 14)))
+
+	// Index the values without the positions.
+	tokenizer, ok := tok.GetTokenizer("fulltext")
+	require.True(t, ok)
+	for uid, value := range map[uint64]string{15: "red shoes", 16: "shoes red"} {
+		edge := &protos.DirectedEdge{
+			Value:  []byte(value),
+			Label:  "author0",
+			Attr:   "friend",
+			Entity: uid,
+			Op:     protos.DirectedEdge_SET,
+		}
+		_, err := getOrCreate(x.DataKey("friend", uid)).AddMutation(context.Background(), edge)
+		require.NoError(t, err)
+		tokens, err := tokenizer.Tokens(types.Val{Tid: types.StringID, Value: value})
+		require.NoError(t, err)
+		for _, token := range tokens {
+			_, err := getOrCreate(x.IndexKey("friend", token)).AddMutation(context.Background(),
+				&protos.DirectedEdge{ValueId: uid, Label: "author0", Attr: "friend",
+					Op: protos.DirectedEdge_SET})
+			require.NoError(t, err)
+		}
+	}
+
+	query := newQuery("friend", nil, []string{"phrase", "", "red shoes"})
+	r, err := helpProcessTask(context.Background(), query, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, [][]uint64{
+		{14, 15},
+		{14, 15},
+	}, algo.ToUintsListForTest(r.UidMatrix))
+}
+
 /*
 func populateGraphForSort(t *testing.T, ps store.Store) {
 	edge := &protos.DirectedEdge{
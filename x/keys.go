@@ -30,6 +30,7 @@ const (
 	ByteReverse  = byte(0x04)
 	ByteCount    = byte(0x08)
 	ByteCountRev = ByteCount | ByteReverse
+	ByteStats    = byte(0x10)
 	// same prefix for data, index and reverse keys so that relative order of data doesn't change
 	// keys of same attributes are located together
 	defaultPrefix = byte(0x00)
@@ -115,6 +116,18 @@ func CountKey(attr string, count uint32, reverse bool) []byte {
 	return buf
 }
 
+// StatsKey returns the key keeping the statistics of the full text index of
+// attr, which aren't a posting list of the index.
+func StatsKey(attr string) []byte {
+	buf := make([]byte, 2+len(attr)+2)
+	buf[0] = defaultPrefix
+	rest := buf[1:]
+
+	rest = writeAttr(rest, attr)
+	rest[0] = ByteStats
+	return buf
+}
+
 type ParsedKey struct {
 	byteType   byte
 	Attr       string
@@ -145,6 +158,10 @@ func (p ParsedKey) IsSchema() bool {
 	return p.byteType == byteSchema
 }
 
+func (p ParsedKey) IsStats() bool {
+	return p.byteType == ByteStats
+}
+
 func (p ParsedKey) IsType(typ byte) bool {
 	switch typ {
 	case ByteCount, ByteCountRev:
@@ -155,6 +172,8 @@ func (p ParsedKey) IsType(typ byte) bool {
 		return p.IsIndex()
 	case ByteData:
 		return p.IsData()
+	case ByteStats:
+		return p.IsStats()
 	default:
 	}
 	return false
@@ -263,7 +282,7 @@ func Parse(key []byte) *ParsedKey {
 		p.Term = string(k)
 	case ByteCount, ByteCountRev:
 		p.Count = binary.BigEndian.Uint32(k)
-	case byteSchema:
+	case byteSchema, ByteStats:
 		break
 	default:
 		// Some other data type.
@@ -91,3 +91,12 @@ func TestSchemaKey(t *testing.T) {
 		require.Equal(t, sattr, pk.Attr)
 	}
 }
+
+func TestStatsKey(t *testing.T) {
+	key := StatsKey("attr")
+	pk := Parse(key)
+
+	require.True(t, pk.IsStats())
+	require.False(t, pk.IsIndex())
+	require.Equal(t, "attr", pk.Attr)
+}